@@ -0,0 +1,84 @@
+package application
+
+import (
+	"backend/domain"
+	"backend/domain/interfaces"
+	"backend/domain/shared"
+	"context"
+	"errors"
+)
+
+// CpuAnalysisService は行動記録からプレイヤーごとの存在確率マップを作り, 保存する.
+type CpuAnalysisService struct {
+	turnLogRepository    interfaces.TurnLogRepository
+	predictionRepository interfaces.PredictionRepository
+}
+
+func NewCpuAnalysisService(turnLogRepository interfaces.TurnLogRepository, predictionRepository interfaces.PredictionRepository) *CpuAnalysisService {
+	return &CpuAnalysisService{
+		turnLogRepository:    turnLogRepository,
+		predictionRepository: predictionRepository,
+	}
+}
+
+// RecordTurn は1件の行動記録を viewerId の存在確率マップへ反映して保存する.
+// まだ保存されていなければ割引率 discountRate の新しい存在確率マップから始める.
+func (service *CpuAnalysisService) RecordTurn(ctx context.Context, gameId shared.GameId, viewerId shared.PlayerId, discountRate float64, log *domain.TurnLog) (*domain.PredictionBoard, error) {
+	board, err := service.ApplyTurnLogs(ctx, gameId, viewerId, discountRate, []*domain.TurnLog{log})
+	if err != nil {
+		return nil, err
+	}
+	if err := service.predictionRepository.Save(ctx, gameId, viewerId, board); err != nil {
+		return nil, err
+	}
+	return board, nil
+}
+
+// ApplyTurnLogs は保存されている viewerId の存在確率マップに logs を反映したものを返す. 保存はしない.
+// まだ保存されていなければ割引率 discountRate の新しい存在確率マップから始める.
+// 保存されている存在確率マップが logs の最初のターンより先に進んでいる場合は, logs を読んだ後に他の操作が反映したものとして
+// ErrConcurrentModification を返す.
+func (service *CpuAnalysisService) ApplyTurnLogs(ctx context.Context, gameId shared.GameId, viewerId shared.PlayerId, discountRate float64, logs []*domain.TurnLog) (*domain.PredictionBoard, error) {
+	board, err := service.predictionRepository.Find(ctx, gameId, viewerId)
+	if errors.Is(err, shared.ErrPredictionBoardNotFound) {
+		board, err = domain.NewPredictionBoardWithDiscountRate(discountRate)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(logs) != 0 && logs[0] != nil && board.GetCurrentTurn() > logs[0].GetTurn() {
+		return nil, shared.ErrConcurrentModification
+	}
+	for _, log := range logs {
+		if err := board.ApplyTurnLog(viewerId, log); err != nil {
+			return nil, err
+		}
+	}
+	return board, nil
+}
+
+// UpdatePrediction は gameId の全ての行動記録から, 割引率 discountRate の viewerId の存在確率マップを作り直して保存する.
+func (service *CpuAnalysisService) UpdatePrediction(ctx context.Context, gameId shared.GameId, viewerId shared.PlayerId, discountRate float64) (*domain.PredictionBoard, error) {
+	logs, err := service.turnLogRepository.FindByGameId(ctx, gameId)
+	if err != nil {
+		return nil, err
+	}
+	board, err := domain.NewPredictionBoardWithDiscountRate(discountRate)
+	if err != nil {
+		return nil, err
+	}
+	for _, log := range logs {
+		if err := board.ApplyTurnLog(viewerId, log); err != nil {
+			return nil, err
+		}
+	}
+	if err := service.predictionRepository.Save(ctx, gameId, viewerId, board); err != nil {
+		return nil, err
+	}
+	return board, nil
+}
+
+// GetPrediction は保存されている viewerId の存在確率マップを返す.
+func (service *CpuAnalysisService) GetPrediction(ctx context.Context, gameId shared.GameId, viewerId shared.PlayerId) (*domain.PredictionBoard, error) {
+	return service.predictionRepository.Find(ctx, gameId, viewerId)
+}
@@ -0,0 +1,142 @@
+package application
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"backend/domain"
+	"backend/domain/shared"
+	"backend/infrastructure"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newAnalysisTestLog(t *testing.T, turn int, playerId shared.PlayerId, actionType shared.ActionType, x int, y int, attackReport shared.AttackReportType) *domain.TurnLog {
+	t.Helper()
+	var target *domain.Position
+	var direction shared.Direction = shared.DirectionUnknown
+	distance := 0
+	if actionType == shared.Attack {
+		position, err := domain.NewPosition(x, y)
+		assert.NoError(t, err)
+		target = position
+	} else {
+		direction = shared.East
+		distance = 1
+	}
+	log, err := domain.NewTurnLog("g1", turn, playerId, "", actionType, target, direction, distance, attackReport, shared.MoveReportUnknown, shared.ErrorNone, time.Time{})
+	assert.NoError(t, err)
+	return log
+}
+
+func possibilityAt(t *testing.T, board *domain.PredictionBoard, x int, y int) float64 {
+	t.Helper()
+	position, err := domain.NewPosition(x, y)
+	assert.NoError(t, err)
+	possibility, err := board.Possibility(position)
+	assert.NoError(t, err)
+	return possibility
+}
+
+func TestCpuAnalysisServiceRecordTurn(t *testing.T) {
+	ctx := context.Background()
+	predictions := infrastructure.NewInMemoryPredictionRepository()
+	service := NewCpuAnalysisService(infrastructure.NewInMemoryTurnLogRepository(), predictions)
+
+	_, err := service.GetPrediction(ctx, "g1", "p1")
+	assert.ErrorIs(t, err, shared.ErrPredictionBoardNotFound)
+
+	_, err = service.RecordTurn(ctx, "g1", "p1", domain.DefaultDiscountRate, newAnalysisTestLog(t, 1, "p1", shared.Attack, 3, 3, shared.Hit))
+	assert.NoError(t, err)
+	board, err := service.RecordTurn(ctx, "g1", "p1", domain.DefaultDiscountRate, newAnalysisTestLog(t, 2, "p2", shared.Move, 0, 0, shared.AttackReportUnknown))
+	assert.NoError(t, err)
+	assert.InDelta(t, 1, possibilityAt(t, board, 4, 3), 1e-9)
+
+	saved, err := service.GetPrediction(ctx, "g1", "p1")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, saved.GetCurrentTurn())
+	assert.InDelta(t, 1, possibilityAt(t, saved, 4, 3), 1e-9)
+
+	_, err = service.GetPrediction(ctx, "g1", "p2")
+	assert.ErrorIs(t, err, shared.ErrPredictionBoardNotFound)
+}
+
+func TestCpuAnalysisServiceApplyTurnLogs(t *testing.T) {
+	ctx := context.Background()
+	predictions := infrastructure.NewInMemoryPredictionRepository()
+	service := NewCpuAnalysisService(infrastructure.NewInMemoryTurnLogRepository(), predictions)
+	_, err := service.RecordTurn(ctx, "g1", "p1", domain.DefaultDiscountRate, newAnalysisTestLog(t, 1, "p1", shared.Attack, 3, 3, shared.Hit))
+	assert.NoError(t, err)
+
+	board, err := service.ApplyTurnLogs(ctx, "g1", "p1", domain.DefaultDiscountRate, []*domain.TurnLog{newAnalysisTestLog(t, 2, "p2", shared.Move, 0, 0, shared.AttackReportUnknown)})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, board.GetCurrentTurn())
+	assert.InDelta(t, 1, possibilityAt(t, board, 4, 3), 1e-9)
+
+	// 反映した存在確率マップは保存しない.
+	saved, err := service.GetPrediction(ctx, "g1", "p1")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, saved.GetCurrentTurn())
+
+	// 保存されていなければ指定した割引率で始め, 保存されていれば保存されている割引率を使う.
+	board, err = service.ApplyTurnLogs(ctx, "g1", "p2", 0.5, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.NewPredictionBoard().GetCurrentTurn(), board.GetCurrentTurn())
+	assert.Equal(t, 0.5, board.GetDiscountRate())
+	board, err = service.ApplyTurnLogs(ctx, "g1", "p1", 0.5, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.DefaultDiscountRate, board.GetDiscountRate())
+
+	_, err = service.ApplyTurnLogs(ctx, "g1", "p2", 0, nil)
+	assert.ErrorIs(t, err, shared.ErrInvalidDiscountRate)
+
+	// 保存されている存在確率マップが先に進んでいれば, 他の操作が反映したものとして扱う.
+	_, err = service.RecordTurn(ctx, "g1", "p2", domain.DefaultDiscountRate, newAnalysisTestLog(t, 3, "p1", shared.Attack, 3, 3, shared.Miss))
+	assert.NoError(t, err)
+	_, err = service.ApplyTurnLogs(ctx, "g1", "p2", domain.DefaultDiscountRate, []*domain.TurnLog{newAnalysisTestLog(t, 2, "p2", shared.Move, 0, 0, shared.AttackReportUnknown)})
+	assert.ErrorIs(t, err, shared.ErrConcurrentModification)
+
+	// 行動記録そのもののターンが戻っている場合はそのまま返す.
+	_, err = service.ApplyTurnLogs(ctx, "g1", "p2", domain.DefaultDiscountRate, []*domain.TurnLog{
+		newAnalysisTestLog(t, 4, "p2", shared.Move, 0, 0, shared.AttackReportUnknown),
+		newAnalysisTestLog(t, 3, "p1", shared.Attack, 3, 3, shared.Miss),
+	})
+	assert.ErrorIs(t, err, shared.ErrInvalidTurn)
+}
+
+func TestCpuAnalysisServiceUpdatePrediction(t *testing.T) {
+	ctx := context.Background()
+	turnLogs := infrastructure.NewInMemoryTurnLogRepository()
+	service := NewCpuAnalysisService(turnLogs, infrastructure.NewInMemoryPredictionRepository())
+	logs := []*domain.TurnLog{
+		newAnalysisTestLog(t, 1, "p1", shared.Attack, 1, 1, shared.Miss),
+		newAnalysisTestLog(t, 2, "p2", shared.Attack, 4, 4, shared.Miss),
+	}
+	for _, log := range logs {
+		assert.NoError(t, turnLogs.Append(ctx, "g1", log))
+	}
+
+	testList := []struct {
+		name     string
+		viewerId shared.PlayerId
+		x        int
+		y        int
+		expected float64
+	}{
+		{"[UpdatePrediction: p1は自分の攻撃結果を反映]", "p1", 2, 2, 0},
+		{"[UpdatePrediction: p1は相手の攻撃から周囲に加算]", "p1", 5, 5, 4.0/25 + 1.0/8},
+		{"[UpdatePrediction: p2は相手の攻撃から周囲に加算]", "p2", 2, 2, 4.0/25 + 1.0/3},
+	}
+	for _, tl := range testList {
+		t.Run(tl.name, func(t *testing.T) {
+			board, err := service.UpdatePrediction(ctx, "g1", tl.viewerId, domain.DefaultDiscountRate)
+			assert.NoError(t, err)
+			assert.InDelta(t, tl.expected, possibilityAt(t, board, tl.x, tl.y), 1e-9)
+
+			saved, err := service.GetPrediction(ctx, "g1", tl.viewerId)
+			assert.NoError(t, err)
+			assert.InDelta(t, tl.expected, possibilityAt(t, saved, tl.x, tl.y), 1e-9)
+		})
+	}
+}
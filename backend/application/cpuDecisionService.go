@@ -0,0 +1,66 @@
+package application
+
+import (
+	"backend/domain"
+	"backend/domain/interfaces"
+	"backend/domain/shared"
+	"context"
+)
+
+// CpuDecisionService は CPU に渡す情報を CPU 側のプレイヤーが知り得るものだけに絞り, 次の行動を決めさせる.
+// CPU は対戦ごとに保存された CpuProfile から作る.
+type CpuDecisionService struct {
+	turnLogRepository interfaces.TurnLogRepository
+	cpuRegistry       interfaces.CPUPlayerRegistry
+}
+
+func NewCpuDecisionService(turnLogRepository interfaces.TurnLogRepository, cpuRegistry interfaces.CPUPlayerRegistry) *CpuDecisionService {
+	return &CpuDecisionService{
+		turnLogRepository: turnLogRepository,
+		cpuRegistry:       cpuRegistry,
+	}
+}
+
+// CheckProfile は profile の CPU を作れるかを確かめる. 登録されていない名前であれば ErrInvalidCpuName を返す.
+// 対戦を作る時点で確かめ, 行動できない CPU の対戦が保存されないようにする.
+func (service *CpuDecisionService) CheckProfile(profile *domain.CpuProfile) error {
+	_, err := service.cpuRegistry.New(profile)
+	return err
+}
+
+// DecideAction は保存済みの行動記録と game がまだ保存していない行動記録, game からプレイヤーBの GameView を作り,
+// game の CpuProfile の CPU に行動を決めさせる.
+// CPU が理由を説明できる場合は理由も返し, できない場合は nil を返す.
+// 保存済みの行動記録が game のまだ保存していない行動記録より先のターンまで進んでいる場合は, game を読み込んだ後に
+// 他の操作がコミットしたものとして ErrConcurrentModification を返す.
+func (service *CpuDecisionService) DecideAction(ctx context.Context, game *domain.Game) (*domain.ActionCommand, *domain.CpuRationale, error) {
+	if game == nil {
+		return nil, nil, shared.ErrGameIsNil
+	}
+	profile := game.GetCpuProfile()
+	if profile == nil {
+		return nil, nil, shared.ErrCpuProfileIsNil
+	}
+	cpu, err := service.cpuRegistry.New(profile)
+	if err != nil {
+		return nil, nil, err
+	}
+	logs, err := service.turnLogRepository.FindByGameId(ctx, game.GetId())
+	if err != nil {
+		return nil, nil, err
+	}
+	pending := game.PendingTurnLogs()
+	if len(logs) != 0 && len(pending) != 0 && logs[len(logs)-1].GetTurn() > pending[0].GetTurn() {
+		return nil, nil, shared.ErrConcurrentModification
+	}
+	logs = append(logs, pending...)
+	view, err := domain.NewGameView(game, game.GetPlayerBId(), logs)
+	if err != nil {
+		return nil, nil, err
+	}
+	if explaining, ok := cpu.(interfaces.ExplainingCPUPlayer); ok {
+		return explaining.DecideWithRationale(ctx, view)
+	}
+	command, err := cpu.Decide(ctx, view)
+	return command, nil, err
+}
@@ -0,0 +1,144 @@
+package application
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"backend/domain"
+	"backend/domain/shared"
+	"backend/infrastructure"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newDecisionTestGame(t *testing.T, profile *domain.CpuProfile) *domain.Game {
+	t.Helper()
+	board := domain.NewBoard()
+	for playerId, positions := range map[shared.PlayerId][][2]int{
+		"p1": {{1, 1}, {2, 1}, {1, 2}, {2, 2}},
+		"p2": {{3, 3}, {4, 4}, {5, 5}, {5, 4}},
+	} {
+		for _, xy := range positions {
+			position, err := domain.NewPosition(xy[0], xy[1])
+			assert.NoError(t, err)
+			_, err = board.PlaceSubmarine(playerId, position)
+			assert.NoError(t, err)
+		}
+	}
+	game, err := domain.NewGame("g1", "p1", "p2", board)
+	assert.NoError(t, err)
+	if profile != nil {
+		assert.NoError(t, game.SetCpuProfile(profile))
+	}
+	assert.NoError(t, game.Start())
+	return game
+}
+
+func TestCpuDecisionServiceDecideAction(t *testing.T) {
+	ctx := context.Background()
+	registry := infrastructure.NewDefaultCpuRegistry(func() rand.Source { return rand.NewSource(1) })
+
+	for _, name := range registry.Names() {
+		t.Run("[DecideAction: "+name.String()+"]", func(t *testing.T) {
+			turnLogs := infrastructure.NewInMemoryTurnLogRepository()
+			service := NewCpuDecisionService(turnLogs, registry)
+			profile, err := domain.NewCpuProfile(name)
+			assert.NoError(t, err)
+			game := newDecisionTestGame(t, profile)
+			target, err := domain.NewPosition(3, 2)
+			assert.NoError(t, err)
+			attack, err := domain.NewActionCommand("p1", shared.Attack, target, shared.DirectionUnknown, 0)
+			assert.NoError(t, err)
+			_, err = game.Apply(attack)
+			assert.NoError(t, err)
+			for _, log := range game.PullTurnLogs() {
+				assert.NoError(t, turnLogs.Append(ctx, "g1", log))
+			}
+
+			command, rationale, err := service.DecideAction(ctx, game)
+			assert.NoError(t, err)
+			if assert.NotNil(t, rationale) {
+				assert.NotEmpty(t, rationale.GetCandidates())
+			}
+			_, err = game.Apply(command)
+			assert.NoError(t, err)
+			assert.False(t, game.PullTurnLogs()[0].IsRejected())
+		})
+	}
+
+	t.Run("[DecideAction: まだ保存していない行動記録も使う]", func(t *testing.T) {
+		service := NewCpuDecisionService(infrastructure.NewInMemoryTurnLogRepository(), registry)
+		profile, err := domain.NewCpuProfile(shared.CpuHeuristic)
+		assert.NoError(t, err)
+		game := newDecisionTestGame(t, profile)
+		target, err := domain.NewPosition(3, 3)
+		assert.NoError(t, err)
+		attack, err := domain.NewActionCommand("p1", shared.Attack, target, shared.DirectionUnknown, 0)
+		assert.NoError(t, err)
+		_, err = game.Apply(attack)
+		assert.NoError(t, err)
+
+		_, rationale, err := service.DecideAction(ctx, game)
+		assert.NoError(t, err)
+		if assert.NotNil(t, rationale) {
+			assert.Equal(t, shared.CpuTierAllyAtRisk, rationale.GetTier())
+		}
+		assert.Len(t, game.PendingTurnLogs(), 1)
+	})
+
+	t.Run("[DecideAction: 読み込んだ後に他の操作が行動記録を保存していればErrConcurrentModification]", func(t *testing.T) {
+		turnLogs := infrastructure.NewInMemoryTurnLogRepository()
+		service := NewCpuDecisionService(turnLogs, registry)
+		profile, err := domain.NewCpuProfile(shared.CpuHeuristic)
+		assert.NoError(t, err)
+		target, err := domain.NewPosition(3, 3)
+		assert.NoError(t, err)
+		attack, err := domain.NewActionCommand("p1", shared.Attack, target, shared.DirectionUnknown, 0)
+		assert.NoError(t, err)
+
+		// 同じ対戦を読み込んだ別の操作が, 1ターン目と2ターン目を先に保存している.
+		racing := newDecisionTestGame(t, profile)
+		_, err = racing.Apply(attack)
+		assert.NoError(t, err)
+		command, _, err := service.DecideAction(ctx, racing)
+		assert.NoError(t, err)
+		_, err = racing.Apply(command)
+		assert.NoError(t, err)
+		for _, log := range racing.PullTurnLogs() {
+			assert.NoError(t, turnLogs.Append(ctx, "g1", log))
+		}
+
+		game := newDecisionTestGame(t, profile)
+		_, err = game.Apply(attack)
+		assert.NoError(t, err)
+		_, _, err = service.DecideAction(ctx, game)
+		assert.ErrorIs(t, err, shared.ErrConcurrentModification)
+	})
+
+	t.Run("[DecideAction: CPUの設定がない]", func(t *testing.T) {
+		service := NewCpuDecisionService(infrastructure.NewInMemoryTurnLogRepository(), registry)
+		_, _, err := service.DecideAction(ctx, newDecisionTestGame(t, nil))
+		assert.ErrorIs(t, err, shared.ErrCpuProfileIsNil)
+	})
+
+	t.Run("[DecideAction: 登録されていないCPU]", func(t *testing.T) {
+		service := NewCpuDecisionService(infrastructure.NewInMemoryTurnLogRepository(), registry)
+		profile, err := domain.NewCpuProfile("unknown")
+		assert.NoError(t, err)
+		_, _, err = service.DecideAction(ctx, newDecisionTestGame(t, profile))
+		assert.ErrorIs(t, err, shared.ErrInvalidCpuName)
+	})
+}
+
+func TestCpuDecisionServiceCheckProfile(t *testing.T) {
+	registry := infrastructure.NewDefaultCpuRegistry(func() rand.Source { return rand.NewSource(1) })
+	service := NewCpuDecisionService(infrastructure.NewInMemoryTurnLogRepository(), registry)
+	heuristic, err := domain.NewCpuProfile(shared.CpuHeuristic)
+	assert.NoError(t, err)
+	assert.NoError(t, service.CheckProfile(heuristic))
+	unknown, err := domain.NewCpuProfile("foo")
+	assert.NoError(t, err)
+	assert.ErrorIs(t, service.CheckProfile(unknown), shared.ErrInvalidCpuName)
+	assert.ErrorIs(t, service.CheckProfile(nil), shared.ErrCpuProfileIsNil)
+}
@@ -0,0 +1,240 @@
+package application
+
+import (
+	"backend/domain"
+	"backend/domain/interfaces"
+	"backend/domain/shared"
+	"context"
+	"errors"
+)
+
+// executeTurnAttempts は ExecuteTurn が保存の競合で読み込み直す回数の上限.
+const executeTurnAttempts = 3
+
+// InitializeGameInput は InitializeGameRequest に対応する対戦の作成内容.
+// AutoPlace が true の場合, プレイヤーAの配置は SubmarinePositions の代わりにサーバが決める.
+// CpuProfile が nil の場合はプレイヤーBも人間が操作し, 配置は PlayerBSubmarinePositions か, PlayerBAutoPlace が true であればサーバが決める.
+type InitializeGameInput struct {
+	PlayerAId                 shared.PlayerId
+	PlayerBId                 shared.PlayerId
+	SubmarinePositions        []*domain.Position
+	AutoPlace                 bool
+	PlayerBSubmarinePositions []*domain.Position
+	PlayerBAutoPlace          bool
+	CpuProfile                *domain.CpuProfile
+	Debug                     bool
+}
+
+// ActionOutcome は ExecuteActionResponse に対応する1回の行動の結果. Game は保存した後の対戦.
+// CPU が続けて行動した場合は CpuCommand と CpuResult を設定し, 対戦のデバッグ設定が有効であれば CpuRationale も設定する.
+type ActionOutcome struct {
+	Game         *domain.Game
+	Result       *domain.TurnResult
+	CpuCommand   *domain.ActionCommand
+	CpuResult    *domain.TurnResult
+	CpuRationale *domain.CpuRationale
+}
+
+// GameService は対戦の作成と進行をまとめる.
+type GameService struct {
+	gameRepository     interfaces.GameRepository
+	unitOfWork         interfaces.UnitOfWork
+	cpuDecisionService *CpuDecisionService
+	cpuAnalysisService *CpuAnalysisService
+	cpuPlacement       interfaces.PlacementStrategy
+	autoPlacement      interfaces.PlacementStrategy
+	newGameId          func() shared.GameId
+}
+
+// NewGameService は CPU の配置に cpuPlacement を, 人間の自動配置に autoPlacement を使う GameService を作る.
+// 1ターンの書き込みは unitOfWork でまとめてコミットする.
+func NewGameService(gameRepository interfaces.GameRepository, unitOfWork interfaces.UnitOfWork, cpuDecisionService *CpuDecisionService, cpuAnalysisService *CpuAnalysisService, cpuPlacement interfaces.PlacementStrategy, autoPlacement interfaces.PlacementStrategy, newGameId func() shared.GameId) *GameService {
+	return &GameService{
+		gameRepository:     gameRepository,
+		unitOfWork:         unitOfWork,
+		cpuDecisionService: cpuDecisionService,
+		cpuAnalysisService: cpuAnalysisService,
+		cpuPlacement:       cpuPlacement,
+		autoPlacement:      autoPlacement,
+		newGameId:          newGameId,
+	}
+}
+
+// InitializeGame は両プレイヤーの潜水艦を配置して対戦を開始し, 両プレイヤーの参加中の対戦に加えて1つのトランザクションで保存する.
+// 自動配置には autoPlacement を使う. プレイヤーBが CPU の場合は配置を指定できず, 常に cpuPlacement で決める.
+// CpuProfile の CPU を作れない場合は何も保存せずにエラーを返す.
+func (service *GameService) InitializeGame(ctx context.Context, input InitializeGameInput) (*domain.Game, error) {
+	if input.CpuProfile != nil {
+		if err := service.cpuDecisionService.CheckProfile(input.CpuProfile); err != nil {
+			return nil, err
+		}
+	}
+	positionsA, err := placeFleet(ctx, input.SubmarinePositions, input.AutoPlace, service.autoPlacement)
+	if err != nil {
+		return nil, err
+	}
+	var positionsB []*domain.Position
+	if input.CpuProfile != nil {
+		if len(input.PlayerBSubmarinePositions) != 0 || input.PlayerBAutoPlace {
+			return nil, shared.ErrInvalidPlacement
+		}
+		positionsB, err = service.cpuPlacement.Place(ctx)
+	} else {
+		positionsB, err = placeFleet(ctx, input.PlayerBSubmarinePositions, input.PlayerBAutoPlace, service.autoPlacement)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	board := domain.NewBoard()
+	game, err := domain.NewGame(service.newGameId(), input.PlayerAId, input.PlayerBId, board)
+	if err != nil {
+		return nil, err
+	}
+	for _, fleet := range []struct {
+		playerId  shared.PlayerId
+		positions []*domain.Position
+	}{{input.PlayerAId, positionsA}, {input.PlayerBId, positionsB}} {
+		if len(fleet.positions) != shared.SubmarineCount {
+			return nil, shared.ErrInvalidPlacement
+		}
+		for _, position := range fleet.positions {
+			if _, err := board.PlaceSubmarine(fleet.playerId, position); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if input.CpuProfile != nil {
+		if err := game.SetCpuProfile(input.CpuProfile); err != nil {
+			return nil, err
+		}
+	}
+	if err := game.SetDebug(input.Debug); err != nil {
+		return nil, err
+	}
+	if err := game.Start(); err != nil {
+		return nil, err
+	}
+	transaction := service.unitOfWork.Begin()
+	if err := transaction.SaveGame(game); err != nil {
+		return nil, err
+	}
+	for _, playerId := range []shared.PlayerId{input.PlayerAId, input.PlayerBId} {
+		if err := transaction.AddPlayerGame(playerId, game.GetId()); err != nil {
+			return nil, err
+		}
+	}
+	if err := transaction.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return game, nil
+}
+
+// placeFleet は autoPlace が true であれば placement で配置を決め, そうでなければ positions をそのまま返す.
+// 自動配置と配置の両方を指定した場合は ErrInvalidPlacement を返す.
+func placeFleet(ctx context.Context, positions []*domain.Position, autoPlace bool, placement interfaces.PlacementStrategy) ([]*domain.Position, error) {
+	if !autoPlace {
+		return positions, nil
+	}
+	if len(positions) != 0 {
+		return nil, shared.ErrInvalidPlacement
+	}
+	return placement.Place(ctx)
+}
+
+// ExecuteTurn は gameId の対戦を読み込んで command を反映し, 次がプレイヤーBの CPU の手番であれば CPU の行動まで続けて反映して保存する.
+// 対戦, 行動記録, CPU の存在確率マップは1つのトランザクションでコミットするため, 途中で失敗しても一部だけが保存されることはない.
+// command が拒否された場合も, 時間切れの判定で対戦が変わることがあるため保存し, errorCode を設定した Result とエラーを返す.
+// expectedTurn はクライアントが command を決めたときに見ていたターンで, 読み込んだ対戦のターンと異なれば何も反映せず ErrStaleTurn を返す.
+// 読み込んでから保存するまでに他の操作が対戦を更新していた場合は, 読み込み直してターンが進んでいなければ command を反映し直す.
+// そのため二重に送られた command や古い画面から送られた command は, 2回目の行動として受け付けられずに ErrStaleTurn になる.
+// executeTurnAttempts 回続けて競合した場合は ErrConcurrentModification を返す.
+func (service *GameService) ExecuteTurn(ctx context.Context, gameId shared.GameId, expectedTurn int, command *domain.ActionCommand) (*ActionOutcome, error) {
+	for attempt := 0; attempt < executeTurnAttempts; attempt++ {
+		game, err := service.gameRepository.FindByID(ctx, gameId)
+		if err != nil {
+			return nil, err
+		}
+		if game.GetTurn() != expectedTurn {
+			return nil, shared.ErrStaleTurn
+		}
+		outcome, applyErr := service.applyTurn(ctx, game, command)
+		if outcome == nil && errors.Is(applyErr, shared.ErrConcurrentModification) {
+			continue
+		}
+		if outcome == nil {
+			return nil, applyErr
+		}
+		err = service.commitTurn(ctx, game)
+		if errors.Is(err, shared.ErrConcurrentModification) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return outcome, applyErr
+	}
+	return nil, shared.ErrConcurrentModification
+}
+
+// applyTurn は command と, 続く CPU の行動を game に反映する.
+// command が拒否された場合は outcome とエラーを, 続けられない失敗の場合は nil とエラーを返す.
+func (service *GameService) applyTurn(ctx context.Context, game *domain.Game, command *domain.ActionCommand) (*ActionOutcome, error) {
+	result, applyErr := game.Apply(command)
+	if result == nil {
+		return nil, applyErr
+	}
+	outcome := &ActionOutcome{Game: game, Result: result}
+	if applyErr != nil {
+		return outcome, applyErr
+	}
+	if game.IsFinished() || game.GetCpuProfile() == nil || game.GetCurrentPlayerId() != game.GetPlayerBId() {
+		return outcome, nil
+	}
+
+	cpuCommand, rationale, err := service.cpuDecisionService.DecideAction(ctx, game)
+	if err != nil {
+		return nil, err
+	}
+	cpuResult, err := game.Apply(cpuCommand)
+	if err != nil {
+		return nil, err
+	}
+	outcome.CpuCommand = cpuCommand
+	outcome.CpuResult = cpuResult
+	if game.IsDebug() {
+		outcome.CpuRationale = rationale
+	}
+	return outcome, nil
+}
+
+// commitTurn は game と, まだ保存していない行動記録, それを反映した CPU の存在確率マップをまとめてコミットする.
+// コミットに失敗した場合は行動記録を game に残す.
+func (service *GameService) commitTurn(ctx context.Context, game *domain.Game) error {
+	transaction := service.unitOfWork.Begin()
+	if err := transaction.SaveGame(game); err != nil {
+		return err
+	}
+	logs := game.PendingTurnLogs()
+	for _, log := range logs {
+		if err := transaction.AppendTurnLog(game.GetId(), log); err != nil {
+			return err
+		}
+	}
+	if game.GetCpuProfile() != nil && len(logs) != 0 {
+		// 対戦を読み込んだ後に他の操作がコミットしていれば, ターンが先に進んだ存在確率マップで ErrConcurrentModification になる.
+		// ターンが進んでいなくても, 古い存在確率マップに反映したものは Commit の version の確認で書き込まれない.
+		prediction, err := service.cpuAnalysisService.ApplyTurnLogs(ctx, game.GetId(), game.GetPlayerBId(), game.GetCpuProfile().GetDiscountRate(), logs)
+		if err != nil {
+			return err
+		}
+		if err := transaction.SavePrediction(game.GetId(), game.GetPlayerBId(), prediction); err != nil {
+			return err
+		}
+	}
+	if err := transaction.Commit(ctx); err != nil {
+		return err
+	}
+	game.PullTurnLogs()
+	return nil
+}
@@ -0,0 +1,648 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"testing"
+
+	"backend/domain"
+	"backend/domain/interfaces"
+	"backend/domain/shared"
+	"backend/infrastructure"
+	"backend/infrastructure/upstashtest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestRepositories(t *testing.T) *infrastructure.Repositories {
+	t.Helper()
+	repositories, err := infrastructure.NewRepositories(infrastructure.StorageConfig{Backend: infrastructure.MemoryStorage})
+	assert.NoError(t, err)
+	return repositories
+}
+
+func newTestGameService(repositories *infrastructure.Repositories) *GameService {
+	registry := infrastructure.NewDefaultCpuRegistry(func() rand.Source { return rand.NewSource(1) })
+	return NewGameService(
+		repositories.Games,
+		repositories.UnitOfWork,
+		NewCpuDecisionService(repositories.TurnLogs, registry),
+		NewCpuAnalysisService(repositories.TurnLogs, repositories.Predictions),
+		infrastructure.NewSpreadOutPlacement(rand.NewSource(1)),
+		infrastructure.NewRandomPlacement(rand.NewSource(1)),
+		func() shared.GameId { return "g1" },
+	)
+}
+
+func newTestPositions(t *testing.T, xys ...[2]int) []*domain.Position {
+	t.Helper()
+	positions := make([]*domain.Position, 0, len(xys))
+	for _, xy := range xys {
+		position, err := domain.NewPosition(xy[0], xy[1])
+		assert.NoError(t, err)
+		positions = append(positions, position)
+	}
+	return positions
+}
+
+func TestGameServiceInitializeGame(t *testing.T) {
+	ctx := context.Background()
+	profile, err := domain.NewCpuProfile(shared.CpuHeuristic)
+	assert.NoError(t, err)
+
+	t.Run("[InitializeGame: CPUの配置はサーバが決める]", func(t *testing.T) {
+		game, err := newTestGameService(newTestRepositories(t)).InitializeGame(ctx, InitializeGameInput{
+			PlayerAId:          "p1",
+			PlayerBId:          "cpu",
+			SubmarinePositions: newTestPositions(t, [2]int{1, 1}, [2]int{2, 2}, [2]int{3, 3}, [2]int{4, 4}),
+			CpuProfile:         profile,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, shared.GameStatus(shared.InProgress), game.GetStatus())
+		assert.Equal(t, shared.PlayerId("p1"), game.GetCurrentPlayerId())
+		assert.Equal(t, profile, game.GetCpuProfile())
+		corners := make([]*domain.Position, 0, shared.SubmarineCount)
+		for _, submarine := range game.GetBoard().GetAllySubmarines("cpu") {
+			corners = append(corners, submarine.GetPosition())
+		}
+		assert.ElementsMatch(t, newTestPositions(t, [2]int{1, 1}, [2]int{5, 1}, [2]int{1, 5}, [2]int{5, 5}), corners)
+	})
+
+	t.Run("[InitializeGame: 人間も自動で配置できる]", func(t *testing.T) {
+		game, err := newTestGameService(newTestRepositories(t)).InitializeGame(ctx, InitializeGameInput{
+			PlayerAId:        "p1",
+			PlayerBId:        "p2",
+			AutoPlace:        true,
+			PlayerBAutoPlace: true,
+		})
+		assert.NoError(t, err)
+		assert.Nil(t, game.GetCpuProfile())
+		assert.Len(t, game.GetBoard().GetAllySubmarines("p1"), shared.SubmarineCount)
+		assert.Len(t, game.GetBoard().GetAllySubmarines("p2"), shared.SubmarineCount)
+	})
+
+	t.Run("[InitializeGame: 人間のプレイヤーBは自分で配置する]", func(t *testing.T) {
+		positionsB := newTestPositions(t, [2]int{5, 5}, [2]int{4, 5}, [2]int{5, 4}, [2]int{4, 4})
+		game, err := newTestGameService(newTestRepositories(t)).InitializeGame(ctx, InitializeGameInput{
+			PlayerAId:                 "p1",
+			PlayerBId:                 "p2",
+			AutoPlace:                 true,
+			PlayerBSubmarinePositions: positionsB,
+		})
+		assert.NoError(t, err)
+		placed := make([]*domain.Position, 0, shared.SubmarineCount)
+		for _, submarine := range game.GetBoard().GetAllySubmarines("p2") {
+			placed = append(placed, submarine.GetPosition())
+		}
+		assert.ElementsMatch(t, positionsB, placed)
+	})
+
+	t.Run("[InitializeGame: 両プレイヤーの参加中の対戦に加える]", func(t *testing.T) {
+		repositories := newTestRepositories(t)
+		game, err := newTestGameService(repositories).InitializeGame(ctx, InitializeGameInput{PlayerAId: "p1", PlayerBId: "p2", AutoPlace: true, PlayerBAutoPlace: true})
+		assert.NoError(t, err)
+		for _, playerId := range []shared.PlayerId{"p1", "p2"} {
+			gameIds, err := repositories.PlayerGames.ListGames(ctx, playerId)
+			assert.NoError(t, err)
+			assert.Equal(t, []shared.GameId{game.GetId()}, gameIds)
+		}
+	})
+
+	testList := []struct {
+		name        string
+		input       InitializeGameInput
+		expectedErr error
+	}{
+		{
+			name:        "[InitializeGame: 配置が足りない]",
+			input:       InitializeGameInput{PlayerAId: "p1", PlayerBId: "cpu", SubmarinePositions: newTestPositions(t, [2]int{1, 1}), CpuProfile: profile},
+			expectedErr: shared.ErrInvalidPlacement,
+		},
+		{
+			name:        "[InitializeGame: 自動配置と配置の両方を指定]",
+			input:       InitializeGameInput{PlayerAId: "p1", PlayerBId: "cpu", SubmarinePositions: newTestPositions(t, [2]int{1, 1}), AutoPlace: true},
+			expectedErr: shared.ErrInvalidPlacement,
+		},
+		{
+			name:        "[InitializeGame: 同じマスに2隻]",
+			input:       InitializeGameInput{PlayerAId: "p1", PlayerBId: "cpu", SubmarinePositions: newTestPositions(t, [2]int{1, 1}, [2]int{1, 1}, [2]int{3, 3}, [2]int{4, 4})},
+			expectedErr: shared.ErrPositionAlreadyOccupied,
+		},
+		{
+			name:        "[InitializeGame: 同じプレイヤー同士]",
+			input:       InitializeGameInput{PlayerAId: "p1", PlayerBId: "p1", AutoPlace: true, PlayerBAutoPlace: true},
+			expectedErr: shared.ErrInvalidPlayerID,
+		},
+		{
+			name:        "[InitializeGame: 人間のプレイヤーBの配置がない]",
+			input:       InitializeGameInput{PlayerAId: "p1", PlayerBId: "p2", AutoPlace: true},
+			expectedErr: shared.ErrInvalidPlacement,
+		},
+		{
+			name:        "[InitializeGame: プレイヤーBの自動配置と配置の両方を指定]",
+			input:       InitializeGameInput{PlayerAId: "p1", PlayerBId: "p2", AutoPlace: true, PlayerBSubmarinePositions: newTestPositions(t, [2]int{5, 5}), PlayerBAutoPlace: true},
+			expectedErr: shared.ErrInvalidPlacement,
+		},
+		{
+			name:        "[InitializeGame: CPUの配置は指定できない]",
+			input:       InitializeGameInput{PlayerAId: "p1", PlayerBId: "cpu", AutoPlace: true, PlayerBSubmarinePositions: newTestPositions(t, [2]int{5, 5}, [2]int{4, 5}, [2]int{5, 4}, [2]int{4, 4}), CpuProfile: profile},
+			expectedErr: shared.ErrInvalidPlacement,
+		},
+	}
+	for _, tl := range testList {
+		t.Run(tl.name, func(t *testing.T) {
+			_, err := newTestGameService(newTestRepositories(t)).InitializeGame(ctx, tl.input)
+			assert.ErrorIs(t, err, tl.expectedErr)
+		})
+	}
+
+	t.Run("[InitializeGame: 登録されていないCPUの対戦は保存しない]", func(t *testing.T) {
+		unknown, err := domain.NewCpuProfile("foo")
+		assert.NoError(t, err)
+		repositories := newTestRepositories(t)
+		_, err = newTestGameService(repositories).InitializeGame(ctx, InitializeGameInput{PlayerAId: "p1", PlayerBId: "cpu", AutoPlace: true, CpuProfile: unknown})
+		assert.ErrorIs(t, err, shared.ErrInvalidCpuName)
+		_, err = repositories.Games.FindByID(ctx, "g1")
+		assert.ErrorIs(t, err, shared.ErrGameNotFound)
+		gameIds, err := repositories.PlayerGames.ListGames(ctx, "p1")
+		assert.NoError(t, err)
+		assert.Empty(t, gameIds)
+	})
+}
+
+func TestGameServiceExecuteTurn(t *testing.T) {
+	ctx := context.Background()
+	profile, err := domain.NewCpuProfile(shared.CpuHeuristic)
+	assert.NoError(t, err)
+	positions := newTestPositions(t, [2]int{2, 2}, [2]int{2, 3}, [2]int{3, 2}, [2]int{3, 3})
+	newAttack := func(t *testing.T, playerId shared.PlayerId, x int, y int) *domain.ActionCommand {
+		t.Helper()
+		target, err := domain.NewPosition(x, y)
+		assert.NoError(t, err)
+		command, err := domain.NewActionCommand(playerId, shared.Attack, target, shared.DirectionUnknown, 0)
+		assert.NoError(t, err)
+		return command
+	}
+
+	for _, debug := range []bool{false, true} {
+		t.Run(fmt.Sprintf("[ExecuteTurn: CPUが続けて行動する debug=%t]", debug), func(t *testing.T) {
+			repositories := newTestRepositories(t)
+			service := newTestGameService(repositories)
+			game, err := service.InitializeGame(ctx, InitializeGameInput{PlayerAId: "p1", PlayerBId: "cpu", SubmarinePositions: positions, CpuProfile: profile, Debug: debug})
+			assert.NoError(t, err)
+
+			outcome, err := service.ExecuteTurn(ctx, game.GetId(), game.GetTurn(), newAttack(t, "p1", 1, 1))
+			assert.NoError(t, err)
+			assert.Equal(t, shared.AttackReportType(shared.Hit), outcome.Result.AttackReport)
+			assert.NotNil(t, outcome.CpuCommand)
+			assert.Equal(t, shared.ErrorCode(shared.ErrorNone), outcome.CpuResult.GetErrorCode())
+			assert.Equal(t, shared.PlayerId("p1"), outcome.Game.GetCurrentPlayerId())
+			assert.Equal(t, 2, outcome.Game.GetVersion())
+			if debug {
+				if assert.NotNil(t, outcome.CpuRationale) {
+					assert.Equal(t, shared.CpuTierAllyAtRisk, outcome.CpuRationale.GetTier())
+				}
+			} else {
+				assert.Nil(t, outcome.CpuRationale)
+			}
+
+			logs, err := repositories.TurnLogs.FindByGameId(ctx, game.GetId())
+			assert.NoError(t, err)
+			assert.Len(t, logs, 2)
+			prediction, err := repositories.Predictions.Find(ctx, game.GetId(), "cpu")
+			assert.NoError(t, err)
+			assert.Equal(t, 2, prediction.GetCurrentTurn())
+			_, err = repositories.Predictions.Find(ctx, game.GetId(), "p1")
+			assert.ErrorIs(t, err, shared.ErrPredictionBoardNotFound)
+		})
+	}
+
+	t.Run("[ExecuteTurn: 拒否された行動ではCPUは行動しない]", func(t *testing.T) {
+		repositories := newTestRepositories(t)
+		service := newTestGameService(repositories)
+		game, err := service.InitializeGame(ctx, InitializeGameInput{PlayerAId: "p1", PlayerBId: "cpu", SubmarinePositions: positions, CpuProfile: profile})
+		assert.NoError(t, err)
+
+		outcome, err := service.ExecuteTurn(ctx, game.GetId(), game.GetTurn(), newAttack(t, "p1", 5, 5))
+		assert.ErrorIs(t, err, shared.ErrTargetOutOfRange)
+		assert.NotEqual(t, shared.ErrorCode(shared.ErrorNone), outcome.Result.GetErrorCode())
+		assert.Nil(t, outcome.CpuCommand)
+		logs, err := repositories.TurnLogs.FindByGameId(ctx, game.GetId())
+		assert.NoError(t, err)
+		assert.Len(t, logs, 1)
+		prediction, err := repositories.Predictions.Find(ctx, game.GetId(), "cpu")
+		assert.NoError(t, err)
+		assert.Equal(t, 1, prediction.GetCurrentTurn())
+	})
+
+	t.Run("[ExecuteTurn: 存在確率マップはCPUの割引率で作る]", func(t *testing.T) {
+		discounted, err := domain.NewCpuProfileWithParams(shared.CpuHeuristic, domain.DefaultAggression, domain.DefaultMoveThreshold, 0.5)
+		assert.NoError(t, err)
+		repositories := newTestRepositories(t)
+		service := newTestGameService(repositories)
+		game, err := service.InitializeGame(ctx, InitializeGameInput{PlayerAId: "p1", PlayerBId: "cpu", SubmarinePositions: positions, CpuProfile: discounted})
+		assert.NoError(t, err)
+
+		_, err = service.ExecuteTurn(ctx, game.GetId(), game.GetTurn(), newAttack(t, "p1", 1, 1))
+		assert.NoError(t, err)
+		prediction, err := repositories.Predictions.Find(ctx, game.GetId(), "cpu")
+		assert.NoError(t, err)
+		assert.Equal(t, 0.5, prediction.GetDiscountRate())
+	})
+
+	t.Run("[ExecuteTurn: 人間同士ではCPUは行動しない]", func(t *testing.T) {
+		repositories := newTestRepositories(t)
+		service := newTestGameService(repositories)
+		game, err := service.InitializeGame(ctx, InitializeGameInput{PlayerAId: "p1", PlayerBId: "p2", SubmarinePositions: positions, PlayerBAutoPlace: true})
+		assert.NoError(t, err)
+
+		outcome, err := service.ExecuteTurn(ctx, game.GetId(), game.GetTurn(), newAttack(t, "p1", 1, 1))
+		assert.NoError(t, err)
+		assert.Nil(t, outcome.CpuCommand)
+		found, err := repositories.Games.FindByID(ctx, game.GetId())
+		assert.NoError(t, err)
+		assert.Equal(t, shared.PlayerId("p2"), found.GetCurrentPlayerId())
+		// 存在確率マップは CPU のものだけを保存する.
+		for _, playerId := range []shared.PlayerId{"p1", "p2"} {
+			_, err = repositories.Predictions.Find(ctx, game.GetId(), playerId)
+			assert.ErrorIs(t, err, shared.ErrPredictionBoardNotFound)
+		}
+	})
+
+	t.Run("[ExecuteTurn: 保存されていない対戦]", func(t *testing.T) {
+		_, err := newTestGameService(newTestRepositories(t)).ExecuteTurn(ctx, "g1", 1, newAttack(t, "p1", 1, 1))
+		assert.ErrorIs(t, err, shared.ErrGameNotFound)
+	})
+}
+
+// racingUnitOfWork はコミットの前に beforeCommit を呼び, 同じ対戦への別のリクエストが割り込んだ状況を作る.
+// beforeCommit がエラーを返した場合は, 書き込みの途中で止まった場合と同じくコミットせずにそのエラーを返す.
+type racingUnitOfWork struct {
+	interfaces.UnitOfWork
+	beforeCommit func() error
+	commits      int
+}
+
+func (unitOfWork *racingUnitOfWork) Begin() interfaces.TurnTransaction {
+	return &racingTurnTransaction{TurnTransaction: unitOfWork.UnitOfWork.Begin(), unitOfWork: unitOfWork}
+}
+
+type racingTurnTransaction struct {
+	interfaces.TurnTransaction
+	unitOfWork *racingUnitOfWork
+}
+
+func (transaction *racingTurnTransaction) Commit(ctx context.Context) error {
+	transaction.unitOfWork.commits++
+	if beforeCommit := transaction.unitOfWork.beforeCommit; beforeCommit != nil {
+		if err := beforeCommit(); err != nil {
+			return err
+		}
+	}
+	return transaction.TurnTransaction.Commit(ctx)
+}
+
+func newRacingGameService(t *testing.T) (*GameService, *infrastructure.Repositories, *racingUnitOfWork, *domain.Game) {
+	t.Helper()
+	repositories := newTestRepositories(t)
+	unitOfWork := &racingUnitOfWork{UnitOfWork: repositories.UnitOfWork}
+	repositories.UnitOfWork = unitOfWork
+	service := newTestGameService(repositories)
+	game, err := service.InitializeGame(context.Background(), InitializeGameInput{
+		PlayerAId:          "p1",
+		PlayerBId:          "p2",
+		SubmarinePositions: newTestPositions(t, [2]int{1, 1}, [2]int{1, 2}, [2]int{2, 1}, [2]int{2, 2}),
+		PlayerBAutoPlace:   true,
+	})
+	assert.NoError(t, err)
+	// 対戦の作成もコミットするため, ExecuteTurn のコミットだけを数えるよう戻す.
+	unitOfWork.commits = 0
+	return service, repositories, unitOfWork, game
+}
+
+func newTestAttack(t *testing.T, playerId shared.PlayerId, x int, y int) *domain.ActionCommand {
+	t.Helper()
+	target, err := domain.NewPosition(x, y)
+	assert.NoError(t, err)
+	command, err := domain.NewActionCommand(playerId, shared.Attack, target, shared.DirectionUnknown, 0)
+	assert.NoError(t, err)
+	return command
+}
+
+func TestGameServiceExecuteTurnConflict(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("[ExecuteTurn: 競合したら読み込み直して反映する]", func(t *testing.T) {
+		service, repositories, unitOfWork, game := newRacingGameService(t)
+		unitOfWork.beforeCommit = func() error {
+			unitOfWork.beforeCommit = nil
+			racing, err := repositories.Games.FindByID(ctx, game.GetId())
+			assert.NoError(t, err)
+			assert.NoError(t, racing.SetDebug(true))
+			return repositories.Games.Save(ctx, racing)
+		}
+
+		outcome, err := service.ExecuteTurn(ctx, game.GetId(), game.GetTurn(), newTestAttack(t, "p1", 3, 3))
+		assert.NoError(t, err)
+		assert.Equal(t, 2, unitOfWork.commits)
+		assert.True(t, outcome.Game.IsDebug())
+		assert.Equal(t, 3, outcome.Game.GetVersion())
+		logs, err := repositories.TurnLogs.FindByGameId(ctx, game.GetId())
+		assert.NoError(t, err)
+		assert.Len(t, logs, 1)
+	})
+
+	t.Run("[ExecuteTurn: 二重に送られた行動は読み込み直した対戦のターンが進んでいれば拒否される]", func(t *testing.T) {
+		service, repositories, unitOfWork, game := newRacingGameService(t)
+		unitOfWork.beforeCommit = func() error {
+			unitOfWork.beforeCommit = nil
+			_, err := service.ExecuteTurn(ctx, game.GetId(), game.GetTurn(), newTestAttack(t, "p1", 3, 3))
+			return err
+		}
+
+		outcome, err := service.ExecuteTurn(ctx, game.GetId(), game.GetTurn(), newTestAttack(t, "p1", 3, 3))
+		assert.Nil(t, outcome)
+		assert.ErrorIs(t, err, shared.ErrStaleTurn)
+		found, err := repositories.Games.FindByID(ctx, game.GetId())
+		assert.NoError(t, err)
+		assert.Equal(t, shared.PlayerId("p2"), found.GetCurrentPlayerId())
+		assert.Equal(t, 2, found.GetTurn())
+		logs, err := repositories.TurnLogs.FindByGameId(ctx, game.GetId())
+		assert.NoError(t, err)
+		assert.Len(t, logs, 1)
+	})
+
+	t.Run("[ExecuteTurn: 見ていたターンから進んだ対戦への行動は何も反映せずErrStaleTurn]", func(t *testing.T) {
+		service, repositories, _, game := newRacingGameService(t)
+		_, err := service.ExecuteTurn(ctx, game.GetId(), game.GetTurn(), newTestAttack(t, "p1", 3, 3))
+		assert.NoError(t, err)
+
+		outcome, err := service.ExecuteTurn(ctx, game.GetId(), game.GetTurn()+2, newTestAttack(t, "p2", 3, 3))
+		assert.Nil(t, outcome)
+		assert.ErrorIs(t, err, shared.ErrStaleTurn)
+		found, err := repositories.Games.FindByID(ctx, game.GetId())
+		assert.NoError(t, err)
+		assert.Equal(t, 2, found.GetVersion())
+		assert.Equal(t, 2, found.GetTurn())
+	})
+
+	t.Run("[ExecuteTurn: 競合が続けばErrConcurrentModification]", func(t *testing.T) {
+		service, repositories, unitOfWork, game := newRacingGameService(t)
+		unitOfWork.beforeCommit = func() error {
+			racing, err := repositories.Games.FindByID(ctx, game.GetId())
+			assert.NoError(t, err)
+			return repositories.Games.Save(ctx, racing)
+		}
+
+		outcome, err := service.ExecuteTurn(ctx, game.GetId(), game.GetTurn(), newTestAttack(t, "p1", 3, 3))
+		assert.Nil(t, outcome)
+		assert.ErrorIs(t, err, shared.ErrConcurrentModification)
+		assert.Equal(t, executeTurnAttempts, unitOfWork.commits)
+		logs, err := repositories.TurnLogs.FindByGameId(ctx, game.GetId())
+		assert.NoError(t, err)
+		assert.Empty(t, logs)
+	})
+
+	t.Run("[ExecuteTurn: コミットに失敗したら何も保存せず, やり直せる]", func(t *testing.T) {
+		service, repositories, unitOfWork, game := newRacingGameService(t)
+		unitOfWork.beforeCommit = func() error {
+			unitOfWork.beforeCommit = nil
+			return shared.ErrUpstashRequestFailed
+		}
+
+		outcome, err := service.ExecuteTurn(ctx, game.GetId(), game.GetTurn(), newTestAttack(t, "p1", 3, 3))
+		assert.Nil(t, outcome)
+		assert.ErrorIs(t, err, shared.ErrUpstashRequestFailed)
+		found, err := repositories.Games.FindByID(ctx, game.GetId())
+		assert.NoError(t, err)
+		assert.Equal(t, 1, found.GetVersion())
+		assert.Equal(t, 1, found.GetTurn())
+		logs, err := repositories.TurnLogs.FindByGameId(ctx, game.GetId())
+		assert.NoError(t, err)
+		assert.Empty(t, logs)
+
+		outcome, err = service.ExecuteTurn(ctx, game.GetId(), game.GetTurn(), newTestAttack(t, "p1", 3, 3))
+		assert.NoError(t, err)
+		assert.Equal(t, 2, outcome.Game.GetVersion())
+		logs, err = repositories.TurnLogs.FindByGameId(ctx, game.GetId())
+		assert.NoError(t, err)
+		assert.Len(t, logs, 1)
+	})
+}
+
+// 多数のgoroutineから同じ対戦に行動を送っても, 受け付けた行動はそれぞれ別のターンとして1回ずつ記録される.
+func TestGameServiceExecuteTurnConcurrently(t *testing.T) {
+	ctx := context.Background()
+	repositories := newTestRepositories(t)
+	service := newTestGameService(repositories)
+	// 両プレイヤーとも誰もいない (3, 3) を攻撃し続けるため, 対戦は終わらない.
+	board := domain.NewBoard()
+	for playerId, positions := range map[shared.PlayerId][]*domain.Position{
+		"p1": newTestPositions(t, [2]int{1, 1}, [2]int{1, 2}, [2]int{2, 1}, [2]int{2, 2}),
+		"p2": newTestPositions(t, [2]int{4, 4}, [2]int{4, 5}, [2]int{5, 4}, [2]int{5, 5}),
+	} {
+		for _, position := range positions {
+			_, err := board.PlaceSubmarine(playerId, position)
+			assert.NoError(t, err)
+		}
+	}
+	game, err := domain.NewGame("g1", "p1", "p2", board)
+	assert.NoError(t, err)
+	assert.NoError(t, game.Start())
+	assert.NoError(t, repositories.Games.Save(ctx, game))
+
+	const workersPerPlayer, callsPerWorker = 8, 10
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		accepted int
+	)
+	for _, playerId := range []shared.PlayerId{"p1", "p2"} {
+		for i := 0; i < workersPerPlayer; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for call := 0; call < callsPerWorker; call++ {
+					current, err := repositories.Games.FindByID(ctx, game.GetId())
+					assert.NoError(t, err)
+					_, err = service.ExecuteTurn(ctx, game.GetId(), current.GetTurn(), newTestAttack(t, playerId, 3, 3))
+					switch {
+					case err == nil:
+						mu.Lock()
+						accepted++
+						mu.Unlock()
+					case errors.Is(err, shared.ErrInvalidTurn), errors.Is(err, shared.ErrStaleTurn), errors.Is(err, shared.ErrConcurrentModification):
+					default:
+						assert.NoError(t, err)
+					}
+				}
+			}()
+		}
+	}
+	wg.Wait()
+
+	found, err := repositories.Games.FindByID(ctx, game.GetId())
+	assert.NoError(t, err)
+	assert.Positive(t, accepted)
+	assert.Equal(t, accepted+1, found.GetTurn())
+	logs, err := repositories.TurnLogs.FindByGameId(ctx, game.GetId())
+	assert.NoError(t, err)
+	turns := make([]int, 0, accepted)
+	for _, log := range logs {
+		if log.GetErrorCode() == shared.ErrorNone {
+			turns = append(turns, log.GetTurn())
+		}
+	}
+	sort.Ints(turns)
+	expected := make([]int, 0, accepted)
+	for turn := 1; turn <= accepted; turn++ {
+		expected = append(expected, turn)
+	}
+	assert.Equal(t, expected, turns)
+}
+
+// racingGameRepository は対戦を読み込んだ直後に afterFind を呼び, 読み込んだ対戦が保存されるより前に別のリクエストがコミットした状況を作る.
+type racingGameRepository struct {
+	interfaces.GameRepository
+	afterFind func()
+}
+
+func (repository *racingGameRepository) FindByID(ctx context.Context, gameId shared.GameId) (*domain.Game, error) {
+	game, err := repository.GameRepository.FindByID(ctx, gameId)
+	if afterFind := repository.afterFind; afterFind != nil {
+		afterFind()
+	}
+	return game, err
+}
+
+func TestGameServiceExecuteTurnStale(t *testing.T) {
+	ctx := context.Background()
+	profile, err := domain.NewCpuProfile(shared.CpuHeuristic)
+	assert.NoError(t, err)
+	// 対戦を読み込んだ直後に, 同じ対戦を読み込んだ別の操作がプレイヤーとCPUの2ターンをコミットする.
+	newStaleGameService := func(t *testing.T) (*GameService, *infrastructure.Repositories, *domain.Game) {
+		t.Helper()
+		repositories := newTestRepositories(t)
+		racer := newTestGameService(repositories)
+		game, err := racer.InitializeGame(ctx, InitializeGameInput{
+			PlayerAId:          "p1",
+			PlayerBId:          "cpu",
+			SubmarinePositions: newTestPositions(t, [2]int{2, 2}, [2]int{2, 3}, [2]int{3, 2}, [2]int{3, 3}),
+			CpuProfile:         profile,
+		})
+		assert.NoError(t, err)
+		games := &racingGameRepository{GameRepository: repositories.Games}
+		games.afterFind = func() {
+			games.afterFind = nil
+			_, err := racer.ExecuteTurn(ctx, game.GetId(), game.GetTurn(), newTestAttack(t, "p1", 1, 1))
+			assert.NoError(t, err)
+		}
+		racing := *repositories
+		racing.Games = games
+		return newTestGameService(&racing), repositories, game
+	}
+
+	for _, stale := range []struct {
+		name    string
+		command func(t *testing.T) *domain.ActionCommand
+	}{
+		// CPUに決めさせる前に, 先に進んだ存在確率マップで競合する.
+		{"[ExecuteTurn: 先に進んだ行動記録があれば読み込み直して古い行動を拒否する]", func(t *testing.T) *domain.ActionCommand { return newTestAttack(t, "p1", 1, 1) }},
+		// 拒否される行動ではCPUは行動せず, 行動記録を反映する先に進んだ存在確率マップで競合する.
+		{"[ExecuteTurn: 先に進んだ存在確率マップがあれば読み込み直して古い行動を拒否する]", func(t *testing.T) *domain.ActionCommand { return newTestAttack(t, "p1", 5, 5) }},
+	} {
+		t.Run(stale.name, func(t *testing.T) {
+			service, repositories, game := newStaleGameService(t)
+			outcome, err := service.ExecuteTurn(ctx, game.GetId(), game.GetTurn(), stale.command(t))
+			assert.Nil(t, outcome)
+			assert.ErrorIs(t, err, shared.ErrStaleTurn)
+			// 先にコミットしたプレイヤーとCPUの2ターンだけが残る.
+			found, err := repositories.Games.FindByID(ctx, game.GetId())
+			assert.NoError(t, err)
+			assert.Equal(t, 2, found.GetVersion())
+			logs, err := repositories.TurnLogs.FindByGameId(ctx, game.GetId())
+			assert.NoError(t, err)
+			assert.Len(t, logs, 2)
+			prediction, err := repositories.Predictions.Find(ctx, game.GetId(), "cpu")
+			assert.NoError(t, err)
+			assert.Equal(t, 2, prediction.GetCurrentTurn())
+		})
+	}
+}
+
+// 対戦を Upstash に保存し, ExecuteTurn が送るリクエストを1つずつ失敗させても, 対戦, 行動記録, CPU の存在確率マップは
+// 全て実行前か全て実行後のどちらかに揃っている.
+func TestGameServiceExecuteTurnRecovery(t *testing.T) {
+	ctx := context.Background()
+	profile, err := domain.NewCpuProfile(shared.CpuHeuristic)
+	assert.NoError(t, err)
+	newUpstashGameService := func(t *testing.T) (*GameService, *infrastructure.Repositories, *upstashtest.Server, *domain.Game) {
+		t.Helper()
+		server := upstashtest.NewServer(t)
+		repositories, err := infrastructure.NewRepositories(infrastructure.StorageConfig{Backend: infrastructure.UpstashStorage, UpstashURL: server.URL, UpstashToken: server.Token})
+		assert.NoError(t, err)
+		service := newTestGameService(repositories)
+		game, err := service.InitializeGame(ctx, InitializeGameInput{
+			PlayerAId:          "p1",
+			PlayerBId:          "cpu",
+			SubmarinePositions: newTestPositions(t, [2]int{2, 2}, [2]int{2, 3}, [2]int{3, 2}, [2]int{3, 3}),
+			CpuProfile:         profile,
+		})
+		assert.NoError(t, err)
+		return service, repositories, server, game
+	}
+
+	// 失敗させずに1ターン実行したときのリクエスト数を数える.
+	service, _, server, game := newUpstashGameService(t)
+	before := server.Requests()
+	_, err = service.ExecuteTurn(ctx, game.GetId(), game.GetTurn(), newTestAttack(t, "p1", 1, 1))
+	assert.NoError(t, err)
+	requests := server.Requests() - before
+
+	for _, mode := range []struct {
+		name string
+		mode upstashtest.FailureMode
+	}{
+		{"実行前", upstashtest.FailBeforeExecute},
+		{"実行後", upstashtest.FailAfterExecute},
+	} {
+		for n := 1; n <= requests; n++ {
+			t.Run(fmt.Sprintf("[ExecuteTurn: %d番目のリクエストが%sに失敗]", n, mode.name), func(t *testing.T) {
+				service, repositories, server, game := newUpstashGameService(t)
+				server.FailRequest(n, mode.mode)
+				_, executeErr := service.ExecuteTurn(ctx, game.GetId(), game.GetTurn(), newTestAttack(t, "p1", 1, 1))
+
+				found, err := repositories.Games.FindByID(ctx, game.GetId())
+				assert.NoError(t, err)
+				logs, err := repositories.TurnLogs.FindByGameId(ctx, game.GetId())
+				assert.NoError(t, err)
+				prediction, predictionErr := repositories.Predictions.Find(ctx, game.GetId(), "cpu")
+				if found.GetVersion() == 2 {
+					// プレイヤーとCPUの2ターンがまとめて保存されている.
+					assert.Equal(t, 3, found.GetTurn())
+					assert.Len(t, logs, 2)
+					if assert.NoError(t, predictionErr) {
+						assert.Equal(t, 2, prediction.GetCurrentTurn())
+					}
+					return
+				}
+				assert.Error(t, executeErr)
+				assert.Equal(t, 1, found.GetVersion())
+				assert.Equal(t, 1, found.GetTurn())
+				assert.Empty(t, logs)
+				assert.ErrorIs(t, predictionErr, shared.ErrPredictionBoardNotFound)
+
+				// 送る前に失敗した場合はロックも残らないため, そのままやり直せる.
+				if mode.mode == upstashtest.FailBeforeExecute {
+					outcome, err := service.ExecuteTurn(ctx, game.GetId(), game.GetTurn(), newTestAttack(t, "p1", 1, 1))
+					assert.NoError(t, err)
+					assert.Equal(t, 2, outcome.Game.GetVersion())
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,301 @@
+package main
+
+import (
+	"backend/domain"
+	"backend/domain/interfaces"
+	"backend/domain/shared"
+	"backend/infrastructure"
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+const wilsonZ = 1.96
+
+// placementMode は対戦ごとの初期配置の決め方.
+type placementMode string
+
+const (
+	randomPlacement        placementMode = "random"
+	fixedPlacement         placementMode = "fixed"
+	spreadOutPlacement     placementMode = "spreadOut"
+	antiHeuristicPlacement placementMode = "antiHeuristic"
+)
+
+// fixedPositions は fixedPlacement で両者が使う配置. 先手・後手で同じ配置にして有利不利をなくす.
+var fixedPositions = [][2]int{{2, 2}, {4, 2}, {2, 4}, {4, 4}}
+
+// arenaConfig は1回の総当たりの設定.
+// 試合ごとに先手を入れ替え, 試合時間を maxActions 手で使い切るように時計を進める.
+type arenaConfig struct {
+	cpuA       *domain.CpuProfile
+	cpuB       *domain.CpuProfile
+	games      int
+	parallel   int
+	placement  placementMode
+	maxActions int
+	seed       int64
+}
+
+// gameResult は1試合の結果. A, B は arenaConfig の cpuA, cpuB を指し, 先手かどうかとは関係しない.
+type gameResult struct {
+	winner  string
+	actions int
+	hpA     int
+	hpB     int
+	err     error
+}
+
+// arenaReport は総当たりの集計結果.
+type arenaReport struct {
+	CpuA             string     `json:"cpuA"`
+	CpuB             string     `json:"cpuB"`
+	Games            int        `json:"games"`
+	WinsA            int        `json:"winsA"`
+	WinsB            int        `json:"winsB"`
+	Draws            int        `json:"draws"`
+	WinRateA         float64    `json:"winRateA"`
+	WinRateB         float64    `json:"winRateB"`
+	WilsonA          [2]float64 `json:"wilsonA"`
+	WilsonB          [2]float64 `json:"wilsonB"`
+	AverageActions   float64    `json:"averageActions"`
+	AverageHpMarginA float64    `json:"averageHpMarginA"`
+}
+
+// runArena は config.games 試合を config.parallel 個のgoroutineで並行に行い, 結果を集計する.
+// 試合 i の乱数は seed+i から作るため, 並行数によらず同じ結果になる.
+func runArena(ctx context.Context, config arenaConfig) (*arenaReport, error) {
+	if config.games <= 0 || config.parallel <= 0 || config.maxActions <= 0 {
+		return nil, fmt.Errorf("games, parallel and maxActions must be positive")
+	}
+	if config.cpuA == nil || config.cpuB == nil {
+		return nil, shared.ErrCpuProfileIsNil
+	}
+	results, err := playArenaGames(ctx, config, playArenaGame)
+	if err != nil {
+		return nil, err
+	}
+	return summarize(config, results), nil
+}
+
+// playArenaGames は play で config.games 試合を config.parallel 個のgoroutineに振り分ける.
+// いずれかの試合が失敗すると残りの試合を始めずに ctx をキャンセルし, 最初に失敗した試合のエラーを返す.
+func playArenaGames(ctx context.Context, config arenaConfig, play func(ctx context.Context, config arenaConfig, index int) gameResult) ([]gameResult, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	results := make([]gameResult, config.games)
+	indexes := make(chan int)
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	for worker := 0; worker < config.parallel; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				if ctx.Err() != nil {
+					continue
+				}
+				results[index] = play(ctx, config, index)
+				if err := results[index].err; err != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = fmt.Errorf("game %d: %w", index, err)
+					}
+					mu.Unlock()
+					cancel()
+				}
+			}
+		}()
+	}
+	for index := 0; index < config.games && ctx.Err() == nil; index++ {
+		select {
+		case indexes <- index:
+		case <-ctx.Done():
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// playArenaGame は index 番目の試合を行う. 偶数番目は cpuA が先手, 奇数番目は cpuB が先手.
+func playArenaGame(ctx context.Context, config arenaConfig, index int) gameResult {
+	seed := config.seed + int64(index)
+	random := rand.New(rand.NewSource(seed))
+	registry := infrastructure.NewDefaultCpuRegistry(func() rand.Source { return rand.NewSource(random.Int63()) })
+
+	ids := map[shared.PlayerId]string{"first": "A", "second": "B"}
+	profiles := map[shared.PlayerId]*domain.CpuProfile{"first": config.cpuA, "second": config.cpuB}
+	if index%2 == 1 {
+		ids = map[shared.PlayerId]string{"first": "B", "second": "A"}
+		profiles = map[shared.PlayerId]*domain.CpuProfile{"first": config.cpuB, "second": config.cpuA}
+	}
+	cpus := make(map[shared.PlayerId]interfaces.CPUPlayer, len(profiles))
+	for _, playerId := range []shared.PlayerId{"first", "second"} {
+		cpu, err := registry.New(profiles[playerId])
+		if err != nil {
+			return gameResult{err: err}
+		}
+		cpus[playerId] = cpu
+	}
+
+	board := domain.NewBoard()
+	for _, playerId := range []shared.PlayerId{"first", "second"} {
+		positions, err := arenaPositions(ctx, config.placement, random)
+		if err != nil {
+			return gameResult{err: err}
+		}
+		for _, position := range positions {
+			if _, err := board.PlaceSubmarine(playerId, position); err != nil {
+				return gameResult{err: err}
+			}
+		}
+	}
+	game, err := domain.NewGame(shared.GameId(fmt.Sprintf("arena-%d", index)), "first", "second", board)
+	if err != nil {
+		return gameResult{err: err}
+	}
+	clock := &arenaClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	step := shared.MatchDuration / time.Duration(config.maxActions)
+	if err := game.SetClock(clock); err != nil {
+		return gameResult{err: err}
+	}
+	if err := game.Start(); err != nil {
+		return gameResult{err: err}
+	}
+
+	logs := make([]*domain.TurnLog, 0)
+	actions := 0
+	for !game.IsFinished() {
+		if err := ctx.Err(); err != nil {
+			return gameResult{err: err}
+		}
+		playerId := game.GetCurrentPlayerId()
+		view, err := domain.NewGameView(game, playerId, logs)
+		if err != nil {
+			return gameResult{err: err}
+		}
+		command, err := cpus[playerId].Decide(ctx, view)
+		if err != nil {
+			return gameResult{err: err}
+		}
+		if _, err := game.Apply(command); err != nil {
+			return gameResult{err: fmt.Errorf("%s: %w", profiles[playerId].GetName(), err)}
+		}
+		logs = append(logs, game.PullTurnLogs()...)
+		actions++
+		clock.now = clock.now.Add(step)
+		if err := game.Tick(); err != nil {
+			return gameResult{err: err}
+		}
+	}
+
+	result := gameResult{actions: actions}
+	if winnerId := game.GetWinnerId(); winnerId != "" {
+		result.winner = ids[winnerId]
+	}
+	for playerId, id := range ids {
+		if id == "A" {
+			result.hpA = game.GetBoard().RemainingHp(playerId)
+		} else {
+			result.hpB = game.GetBoard().RemainingHp(playerId)
+		}
+	}
+	return result
+}
+
+// arenaPositions は1人分の初期配置を返す.
+func arenaPositions(ctx context.Context, mode placementMode, random *rand.Rand) ([]*domain.Position, error) {
+	source := rand.NewSource(random.Int63())
+	var placement interfaces.PlacementStrategy
+	switch mode {
+	case fixedPlacement:
+		positions := make([]*domain.Position, 0, shared.SubmarineCount)
+		for _, xy := range fixedPositions {
+			position, err := domain.NewPosition(xy[0], xy[1])
+			if err != nil {
+				return nil, err
+			}
+			positions = append(positions, position)
+		}
+		return positions, nil
+	case spreadOutPlacement:
+		placement = infrastructure.NewSpreadOutPlacement(source)
+	case antiHeuristicPlacement:
+		placement = infrastructure.NewAntiHeuristicPlacement(source)
+	default:
+		placement = infrastructure.NewRandomPlacement(source)
+	}
+	return placement.Place(ctx)
+}
+
+func summarize(config arenaConfig, results []gameResult) *arenaReport {
+	report := &arenaReport{
+		CpuA:  describeProfile(config.cpuA),
+		CpuB:  describeProfile(config.cpuB),
+		Games: len(results),
+	}
+	actions, margin := 0, 0
+	for _, result := range results {
+		switch result.winner {
+		case "A":
+			report.WinsA++
+		case "B":
+			report.WinsB++
+		default:
+			report.Draws++
+		}
+		actions += result.actions
+		margin += result.hpA - result.hpB
+	}
+	games := float64(report.Games)
+	report.WinRateA = float64(report.WinsA) / games
+	report.WinRateB = float64(report.WinsB) / games
+	report.WilsonA = wilsonInterval(report.WinsA, report.Games, wilsonZ)
+	report.WilsonB = wilsonInterval(report.WinsB, report.Games, wilsonZ)
+	report.AverageActions = float64(actions) / games
+	report.AverageHpMarginA = float64(margin) / games
+	return report
+}
+
+// wilsonInterval は n 試合中 wins 勝の勝率について, Wilson スコア区間を返す.
+func wilsonInterval(wins int, n int, z float64) [2]float64 {
+	if n == 0 {
+		return [2]float64{0, 1}
+	}
+	p := float64(wins) / float64(n)
+	total := float64(n)
+	denominator := 1 + z*z/total
+	center := (p + z*z/(2*total)) / denominator
+	half := z * math.Sqrt(p*(1-p)/total+z*z/(4*total*total)) / denominator
+	return [2]float64{math.Max(0, center-half), math.Min(1, center+half)}
+}
+
+func describeProfile(profile *domain.CpuProfile) string {
+	if profile.GetName() == shared.CpuMcts {
+		return fmt.Sprintf("%s(iterations=%d,timeBudget=%s)", profile.GetName(), profile.GetIterations(), profile.GetTimeBudget())
+	}
+	return fmt.Sprintf("%s(aggression=%g,moveThreshold=%g,discountRate=%g)", profile.GetName(), profile.GetAggression(), profile.GetMoveThreshold(), profile.GetDiscountRate())
+}
+
+// arenaClock は1手ごとに進める時計. 試合時間を手数で使い切らせるために使う.
+type arenaClock struct {
+	now time.Time
+}
+
+func (clock *arenaClock) Now() time.Time {
+	return clock.now
+}
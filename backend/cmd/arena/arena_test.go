@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"backend/domain"
+	"backend/domain/shared"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWilsonInterval(t *testing.T) {
+	testList := []struct {
+		name     string
+		wins     int
+		n        int
+		expected [2]float64
+	}{
+		{name: "[wilsonInterval: 10戦8勝]", wins: 8, n: 10, expected: [2]float64{0.4902, 0.9433}},
+		{name: "[wilsonInterval: 全勝]", wins: 20, n: 20, expected: [2]float64{0.8389, 1}},
+		{name: "[wilsonInterval: 全敗]", wins: 0, n: 20, expected: [2]float64{0, 0.1611}},
+		{name: "[wilsonInterval: 試合なし]", wins: 0, n: 0, expected: [2]float64{0, 1}},
+	}
+	for _, tl := range testList {
+		t.Run(tl.name, func(t *testing.T) {
+			interval := wilsonInterval(tl.wins, tl.n, wilsonZ)
+			assert.InDelta(t, tl.expected[0], interval[0], 1e-4)
+			assert.InDelta(t, tl.expected[1], interval[1], 1e-4)
+		})
+	}
+}
+
+func TestParseProfile(t *testing.T) {
+	profile, err := parseProfile("heuristic, aggression=0.5,moveThreshold=0.6")
+	assert.NoError(t, err)
+	assert.Equal(t, shared.CpuHeuristic, profile.GetName())
+	assert.Equal(t, 0.5, profile.GetAggression())
+	assert.Equal(t, 0.6, profile.GetMoveThreshold())
+	assert.Equal(t, domain.DefaultDiscountRate, profile.GetDiscountRate())
+
+	profile, err = parseProfile("mcts,iterations=200,timeBudget=500ms")
+	assert.NoError(t, err)
+	assert.Equal(t, 200, profile.GetIterations())
+	assert.Equal(t, 500*time.Millisecond, profile.GetTimeBudget())
+	assert.Equal(t, "mcts(iterations=200,timeBudget=500ms)", describeProfile(profile))
+
+	_, err = parseProfile("heuristic,speed=1")
+	assert.Error(t, err)
+	_, err = parseProfile("heuristic,aggression")
+	assert.Error(t, err)
+	_, err = parseProfile("heuristic,aggression=2")
+	assert.ErrorIs(t, err, shared.ErrInvalidCpuProfile)
+	_, err = parseProfile("mcts,aggression=0.5")
+	assert.ErrorIs(t, err, shared.ErrInvalidCpuProfile)
+	_, err = parseProfile("mcts,iterations=1.5")
+	assert.Error(t, err)
+	_, err = parseProfile("heuristic,iterations=200")
+	assert.ErrorIs(t, err, shared.ErrInvalidCpuProfile)
+}
+
+func TestRunArena(t *testing.T) {
+	heuristic, err := domain.NewCpuProfile(shared.CpuHeuristic)
+	assert.NoError(t, err)
+	random, err := domain.NewCpuProfile(shared.CpuRandom)
+	assert.NoError(t, err)
+	config := arenaConfig{cpuA: heuristic, cpuB: random, games: 12, parallel: 1, placement: randomPlacement, maxActions: 120, seed: 3}
+
+	sequential, err := runArena(context.Background(), config)
+	assert.NoError(t, err)
+	assert.Equal(t, 12, sequential.WinsA+sequential.WinsB+sequential.Draws)
+	assert.Greater(t, sequential.WinsA, sequential.WinsB)
+	assert.Greater(t, sequential.AverageHpMarginA, 0.0)
+	assert.LessOrEqual(t, sequential.AverageActions, 120.0)
+	assert.LessOrEqual(t, sequential.WilsonA[0], sequential.WinRateA)
+	assert.GreaterOrEqual(t, sequential.WilsonA[1], sequential.WinRateA)
+
+	config.parallel = 4
+	parallel, err := runArena(context.Background(), config)
+	assert.NoError(t, err)
+	assert.Equal(t, sequential, parallel)
+
+	t.Run("[runArena: 登録されていないCPU]", func(t *testing.T) {
+		unknown, err := domain.NewCpuProfile("unknown")
+		assert.NoError(t, err)
+		config.cpuB = unknown
+		_, err = runArena(context.Background(), config)
+		assert.ErrorIs(t, err, shared.ErrInvalidCpuName)
+	})
+}
+
+func TestPlayArenaGames(t *testing.T) {
+	config := arenaConfig{games: 100, parallel: 4}
+	failure := errors.New("failed")
+
+	t.Run("[playArenaGames: 失敗したら残りの試合を始めない]", func(t *testing.T) {
+		var started atomic.Int32
+		_, err := playArenaGames(context.Background(), config, func(ctx context.Context, config arenaConfig, index int) gameResult {
+			started.Add(1)
+			if index == 0 {
+				return gameResult{err: failure}
+			}
+			<-ctx.Done()
+			return gameResult{err: ctx.Err()}
+		})
+		assert.ErrorIs(t, err, failure)
+		assert.Less(t, int(started.Load()), config.games)
+	})
+
+	t.Run("[playArenaGames: キャンセルされたcontext]", func(t *testing.T) {
+		canceled, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := playArenaGames(canceled, config, func(ctx context.Context, config arenaConfig, index int) gameResult {
+			return gameResult{}
+		})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestRun(t *testing.T) {
+	var out bytes.Buffer
+	err := run([]string{"-a", "random", "-b", "random", "-games", "4", "-placement", "fixed", "-max-actions", "40", "-format", "json"}, &out)
+	assert.NoError(t, err)
+	var report arenaReport
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &report))
+	assert.Equal(t, 4, report.Games)
+
+	out.Reset()
+	assert.NoError(t, run([]string{"-games", "2", "-max-actions", "40"}, &out))
+	assert.Contains(t, out.String(), "A win rate:")
+
+	assert.Error(t, run([]string{"-placement", "corner"}, &out))
+	assert.Error(t, run([]string{"-format", "xml"}, &out))
+}
@@ -0,0 +1,137 @@
+// arena は登録されている2つの CPU を対戦させ, 勝率などを集計する.
+//
+//	go run ./cmd/arena -a heuristic -b probabilistic,aggression=0.5 -games 200 -format json
+//	go run ./cmd/arena -a mcts,iterations=200,timeBudget=500ms -b heuristic
+//
+// CPU は名前のあとに aggression, moveThreshold, discountRate をカンマ区切りで指定できる.
+// random と mcts はこれらのパラメータを使わないため, 既定値以外を指定するとエラーになる.
+// mcts には1手あたりの反復回数 iterations と思考時間 timeBudget を指定できる.
+// 反復回数より先に思考時間を使い切ると探索が打ち切られ, 同じ seed でも結果が変わることがある.
+package main
+
+import (
+	"backend/domain"
+	"backend/domain/shared"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("arena", flag.ContinueOnError)
+	cpuA := flags.String("a", string(shared.CpuHeuristic), "CPU A")
+	cpuB := flags.String("b", string(shared.CpuRandom), "CPU B")
+	games := flags.Int("games", 100, "number of games")
+	parallel := flags.Int("parallel", runtime.NumCPU(), "number of games played at the same time")
+	placement := flags.String("placement", string(randomPlacement), "initial placement: random, fixed, spreadOut or antiHeuristic")
+	maxActions := flags.Int("max-actions", 240, "actions until the match time runs out")
+	seed := flags.Int64("seed", 1, "random seed")
+	format := flags.String("format", "text", "output format: text or json")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	switch placementMode(*placement) {
+	case randomPlacement, fixedPlacement, spreadOutPlacement, antiHeuristicPlacement:
+	default:
+		return fmt.Errorf("unknown placement %q", *placement)
+	}
+	if *format != "text" && *format != "json" {
+		return fmt.Errorf("unknown format %q", *format)
+	}
+	profileA, err := parseProfile(*cpuA)
+	if err != nil {
+		return err
+	}
+	profileB, err := parseProfile(*cpuB)
+	if err != nil {
+		return err
+	}
+
+	report, err := runArena(context.Background(), arenaConfig{
+		cpuA:       profileA,
+		cpuB:       profileB,
+		games:      *games,
+		parallel:   *parallel,
+		placement:  placementMode(*placement),
+		maxActions: *maxActions,
+		seed:       *seed,
+	})
+	if err != nil {
+		return err
+	}
+	if *format == "json" {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+	return writeText(out, report)
+}
+
+// parseProfile は "heuristic,aggression=0.5" や "mcts,iterations=200" のような指定から CpuProfile を作る.
+func parseProfile(spec string) (*domain.CpuProfile, error) {
+	fields := strings.Split(spec, ",")
+	name := shared.CpuName(strings.TrimSpace(fields[0]))
+	aggression, moveThreshold, discountRate := domain.DefaultAggression, domain.DefaultMoveThreshold, domain.DefaultDiscountRate
+	iterations, timeBudget := 0, time.Duration(0)
+	for _, field := range fields[1:] {
+		key, value, found := strings.Cut(field, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid parameter %q in %q", field, spec)
+		}
+		value = strings.TrimSpace(value)
+		var err error
+		switch strings.TrimSpace(key) {
+		case "aggression":
+			aggression, err = strconv.ParseFloat(value, 64)
+		case "moveThreshold":
+			moveThreshold, err = strconv.ParseFloat(value, 64)
+		case "discountRate":
+			discountRate, err = strconv.ParseFloat(value, 64)
+		case "iterations":
+			iterations, err = strconv.Atoi(value)
+		case "timeBudget":
+			timeBudget, err = time.ParseDuration(value)
+		default:
+			return nil, fmt.Errorf("unknown parameter %q in %q", key, spec)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid parameter %q in %q: %w", field, spec, err)
+		}
+	}
+	profile, err := domain.NewCpuProfileWithParams(name, aggression, moveThreshold, discountRate)
+	if err != nil {
+		return nil, err
+	}
+	return profile.WithSearchBudget(iterations, timeBudget)
+}
+
+func writeText(out io.Writer, report *arenaReport) error {
+	_, err := fmt.Fprintf(out, `A: %s
+B: %s
+games: %d (A wins %d, B wins %d, draws %d)
+A win rate: %.3f (95%% CI %.3f-%.3f)
+B win rate: %.3f (95%% CI %.3f-%.3f)
+average actions: %.1f
+average HP margin (A-B): %.2f
+`,
+		report.CpuA, report.CpuB,
+		report.Games, report.WinsA, report.WinsB, report.Draws,
+		report.WinRateA, report.WilsonA[0], report.WilsonA[1],
+		report.WinRateB, report.WilsonB[0], report.WilsonB[1],
+		report.AverageActions, report.AverageHpMarginA)
+	return err
+}
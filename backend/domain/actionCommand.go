@@ -1,13 +1,17 @@
 package domain
 
-import "backend/domain/shared"
+import (
+	"backend/domain/shared"
+	"errors"
+)
 
 type ActionCommand struct {
-	playerId   shared.PlayerId
-	actionType shared.ActionType
-	target     *Position
-	direction  shared.Direction
-	distance   int
+	playerId    shared.PlayerId
+	submarineId shared.SubmarineId
+	actionType  shared.ActionType
+	target      *Position
+	direction   shared.Direction
+	distance    int
 }
 
 func NewActionCommand(
@@ -16,16 +20,30 @@ func NewActionCommand(
 	target *Position,
 	direction shared.Direction,
 	distance int,
+) (*ActionCommand, error) {
+	return NewActionCommandWithSubmarine(playerId, "", actionType, target, direction, distance)
+}
+
+// NewActionCommandWithSubmarine は行動する潜水艦を指定したActionCommandを生成する.
+// submarineId が空の場合は潜水艦を指定しないコマンドになる.
+func NewActionCommandWithSubmarine(
+	playerId shared.PlayerId,
+	submarineId shared.SubmarineId,
+	actionType shared.ActionType,
+	target *Position,
+	direction shared.Direction,
+	distance int,
 ) (*ActionCommand, error) {
 	if actionType == shared.Move && (distance < shared.MinDistance || distance > shared.MaxDistance) {
 		return nil, shared.ErrInvalidMoveDistance
 	}
 	actionCommand := ActionCommand{
-		playerId:   playerId,
-		actionType: actionType,
-		target:     target,
-		direction:  direction,
-		distance:   distance,
+		playerId:    playerId,
+		submarineId: submarineId,
+		actionType:  actionType,
+		target:      target,
+		direction:   direction,
+		distance:    distance,
 	}
 	switch actionCommand.actionType {
 	case shared.Attack:
@@ -49,6 +67,35 @@ func (actionCommand *ActionCommand) GetPlayerId() (shared.PlayerId, error) {
 	return actionCommand.playerId, nil
 }
 
+func (actionCommand *ActionCommand) GetSubmarineId() (shared.SubmarineId, error) {
+	if actionCommand == nil {
+		return shared.SubmarineId(""), shared.ErrActionCommandIsNil
+	}
+	return actionCommand.submarineId, nil
+}
+
+// ResolveSubmarine は指定された潜水艦が board 上で行動可能かを検証して返す.
+// 潜水艦が指定されていない場合は nil を返す.
+func (actionCommand *ActionCommand) ResolveSubmarine(board *Board) (*Submarine, error) {
+	if actionCommand == nil {
+		return nil, shared.ErrActionCommandIsNil
+	}
+	if actionCommand.submarineId == "" {
+		return nil, nil
+	}
+	submarine, err := board.GetSubmarine(actionCommand.submarineId)
+	if errors.Is(err, shared.ErrSubmarineNotFound) {
+		return nil, shared.ErrSubmarineNotAvailable
+	}
+	if err != nil {
+		return nil, err
+	}
+	if submarine.GetOwnerId() != actionCommand.playerId || submarine.IsSunk() {
+		return nil, shared.ErrSubmarineNotAvailable
+	}
+	return submarine, nil
+}
+
 func (actionCommand *ActionCommand) GetActionType() (shared.ActionType, error) {
 	if actionCommand == nil {
 		return shared.ActionUnknown, shared.ErrActionCommandIsNil
@@ -148,3 +148,57 @@ func TestNewActionCommandFail(t *testing.T) {
 		})
 	}
 }
+
+func TestNewActionCommandWithSubmarine(t *testing.T) {
+	t.Run("[NewActionCommandWithSubmarine: 潜水艦を指定した移動]", func(t *testing.T) {
+		cmd, err := NewActionCommandWithSubmarine("p1", "p1-sub-2", shared.Move, nil, shared.East, 1)
+		assert.NoError(t, err)
+		submarineId, err := cmd.GetSubmarineId()
+		assert.NoError(t, err)
+		assert.Equal(t, shared.SubmarineId("p1-sub-2"), submarineId)
+	})
+
+	t.Run("[NewActionCommand: 潜水艦の指定なし]", func(t *testing.T) {
+		cmd, err := NewActionCommand("p1", shared.Move, nil, shared.East, 1)
+		assert.NoError(t, err)
+		submarineId, err := cmd.GetSubmarineId()
+		assert.NoError(t, err)
+		assert.Equal(t, shared.SubmarineId(""), submarineId)
+	})
+}
+
+func TestResolveSubmarine(t *testing.T) {
+	testList := []struct {
+		name        string
+		submarineId shared.SubmarineId
+		expectedId  shared.SubmarineId
+		expectedErr error
+	}{
+		{"[ResolveSubmarine: 指定なし]", "", "", nil},
+		{"[ResolveSubmarine: 味方の潜水艦]", "p1-sub-1", "p1-sub-1", nil},
+		{"[ResolveSubmarine: 撃沈済み]", "p1-sub-2", "", shared.ErrSubmarineNotAvailable},
+		{"[ResolveSubmarine: 相手の潜水艦]", "p2-sub-1", "", shared.ErrSubmarineNotAvailable},
+		{"[ResolveSubmarine: 存在しない]", "p1-sub-9", "", shared.ErrSubmarineNotAvailable},
+	}
+	for _, tl := range testList {
+		t.Run(tl.name, func(t *testing.T) {
+			board := newTestBoard(t, map[shared.PlayerId][]Position{
+				"p1": {{1, 1}, {2, 2}},
+				"p2": {{4, 4}},
+			})
+			sunk, err := board.GetSubmarine("p1-sub-2")
+			assert.NoError(t, err)
+			assert.NoError(t, sunk.TakeDamage(shared.InitialHp))
+
+			cmd, err := NewActionCommandWithSubmarine("p1", tl.submarineId, shared.Move, nil, shared.South, 1)
+			assert.NoError(t, err)
+			submarine, err := cmd.ResolveSubmarine(board)
+			assert.ErrorIs(t, err, tl.expectedErr)
+			if tl.expectedId == "" {
+				assert.Nil(t, submarine)
+				return
+			}
+			assert.Equal(t, tl.expectedId, submarine.GetId())
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package domain
+
+import (
+	shared "backend/domain/shared"
+)
+
+// AttackOutcome は1回の攻撃を解決した結果.
+type AttackOutcome struct {
+	reportType     shared.AttackReportType
+	target         *Position
+	hitPositions   []*Position
+	sunkSubmarines []*Submarine
+}
+
+const attackDamage = 1
+
+// ResolveAttack は attackerId による target への攻撃を盤面に反映する.
+// target は撃沈されていない味方の潜水艦の周囲8マスに含まれ, かつ味方の潜水艦がいないマスでなければならない.
+func ResolveAttack(board *Board, attackerId shared.PlayerId, target *Position) (*AttackOutcome, error) {
+	if board == nil {
+		return nil, shared.ErrBoardIsNil
+	}
+	if target == nil {
+		return nil, shared.ErrPositionIsNil
+	}
+	inRange, err := board.IsInAttackRange(attackerId, target)
+	if err != nil {
+		return nil, err
+	}
+	if !inRange {
+		return nil, shared.ErrTargetOutOfRange
+	}
+	ally, err := board.GetAllySubmarineAt(attackerId, target)
+	if err != nil {
+		return nil, err
+	}
+	if ally != nil {
+		return nil, shared.ErrTargetIsAllySubmarine
+	}
+
+	targets, err := board.FindTargets(attackerId, target)
+	if err != nil {
+		return nil, err
+	}
+	outcome := &AttackOutcome{
+		reportType:     shared.Miss,
+		target:         target,
+		hitPositions:   make([]*Position, 0, len(targets)),
+		sunkSubmarines: make([]*Submarine, 0),
+	}
+	for _, submarine := range targets {
+		if err := submarine.TakeDamage(attackDamage); err != nil {
+			return nil, err
+		}
+		outcome.hitPositions = append(outcome.hitPositions, submarine.GetPosition())
+		if submarine.IsSunk() {
+			outcome.sunkSubmarines = append(outcome.sunkSubmarines, submarine)
+		}
+	}
+	switch {
+	case len(outcome.sunkSubmarines) > 0:
+		outcome.reportType = shared.HitAndSunk
+	case len(outcome.hitPositions) > 0:
+		outcome.reportType = shared.Hit
+	default:
+		waveHigh, err := hasLiveOpponentAround(board, attackerId, target)
+		if err != nil {
+			return nil, err
+		}
+		if waveHigh {
+			outcome.reportType = shared.WaveHigh
+		}
+	}
+	return outcome, nil
+}
+
+func hasLiveOpponentAround(board *Board, playerId shared.PlayerId, center *Position) (bool, error) {
+	neighbors, err := center.Neighbors8()
+	if err != nil {
+		return false, err
+	}
+	for _, neighbor := range neighbors {
+		opponent, err := board.GetOpponentSubmarineAt(playerId, neighbor)
+		if err != nil {
+			return false, err
+		}
+		if opponent != nil && !opponent.IsSunk() {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (outcome *AttackOutcome) GetReportType() shared.AttackReportType {
+	return outcome.reportType
+}
+
+func (outcome *AttackOutcome) GetTarget() *Position {
+	return outcome.target
+}
+
+func (outcome *AttackOutcome) GetHitPositions() []*Position {
+	return outcome.hitPositions
+}
+
+func (outcome *AttackOutcome) GetSunkSubmarines() []*Submarine {
+	return outcome.sunkSubmarines
+}
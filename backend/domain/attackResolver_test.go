@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"testing"
+
+	shared "backend/domain/shared"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveAttackSuccess(t *testing.T) {
+	testList := []struct {
+		name           string
+		target         Position
+		enemyHp        int
+		expectedReport shared.AttackReportType
+		expectedHits   []Position
+		expectedSunk   []shared.SubmarineId
+	}{
+		{"[ResolveAttack: 命中]", Position{3, 3}, 3, shared.Hit, []Position{{3, 3}}, []shared.SubmarineId{}},
+		{"[ResolveAttack: 命中撃沈]", Position{3, 3}, 1, shared.HitAndSunk, []Position{{3, 3}}, []shared.SubmarineId{"p2-sub-1"}},
+		{"[ResolveAttack: 周囲8マスに敵がいれば波高し]", Position{2, 3}, 3, shared.WaveHigh, []Position{}, []shared.SubmarineId{}},
+		{"[ResolveAttack: 周囲8マスにも敵がいなければ異常なし]", Position{1, 1}, 3, shared.Miss, []Position{}, []shared.SubmarineId{}},
+	}
+	for _, tl := range testList {
+		t.Run(tl.name, func(t *testing.T) {
+			board := newTestBoard(t, map[shared.PlayerId][]Position{
+				"p1": {{2, 2}},
+				"p2": {{3, 3}},
+			})
+			enemy, err := board.GetSubmarine("p2-sub-1")
+			assert.NoError(t, err)
+			if tl.enemyHp < shared.InitialHp {
+				assert.NoError(t, enemy.TakeDamage(shared.InitialHp-tl.enemyHp))
+			}
+
+			outcome, err := ResolveAttack(board, "p1", &tl.target)
+			assert.NoError(t, err)
+			assert.Equal(t, tl.expectedReport, outcome.GetReportType())
+			assert.Equal(t, tl.target, *outcome.GetTarget())
+
+			hits := make([]Position, 0, len(outcome.GetHitPositions()))
+			for _, position := range outcome.GetHitPositions() {
+				hits = append(hits, *position)
+			}
+			assert.Equal(t, tl.expectedHits, hits)
+
+			sunk := make([]shared.SubmarineId, 0, len(outcome.GetSunkSubmarines()))
+			for _, submarine := range outcome.GetSunkSubmarines() {
+				sunk = append(sunk, submarine.GetId())
+			}
+			assert.Equal(t, tl.expectedSunk, sunk)
+		})
+	}
+}
+
+func TestResolveAttackFail(t *testing.T) {
+	testList := []struct {
+		name        string
+		target      *Position
+		expectedErr error
+	}{
+		{"[ResolveAttack: 攻撃範囲外]", &Position{5, 5}, shared.ErrTargetOutOfRange},
+		{"[ResolveAttack: 撃沈した味方の周囲は範囲外]", &Position{5, 1}, shared.ErrTargetOutOfRange},
+		{"[ResolveAttack: 自軍の潜水艦]", &Position{2, 3}, shared.ErrTargetIsAllySubmarine},
+		{"[ResolveAttack: targetがnil]", nil, shared.ErrPositionIsNil},
+	}
+	for _, tl := range testList {
+		t.Run(tl.name, func(t *testing.T) {
+			board := newTestBoard(t, map[shared.PlayerId][]Position{
+				"p1": {{2, 2}, {2, 3}, {4, 1}},
+				"p2": {{3, 3}},
+			})
+			sunkAlly, err := board.GetSubmarine("p1-sub-3")
+			assert.NoError(t, err)
+			assert.NoError(t, sunkAlly.TakeDamage(shared.InitialHp))
+
+			outcome, err := ResolveAttack(board, "p1", tl.target)
+			assert.Nil(t, outcome)
+			assert.ErrorIs(t, err, tl.expectedErr)
+
+			enemy, err := board.GetSubmarine("p2-sub-1")
+			assert.NoError(t, err)
+			assert.Equal(t, shared.InitialHp, enemy.GetHp())
+		})
+	}
+}
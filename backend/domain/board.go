@@ -0,0 +1,368 @@
+package domain
+
+import (
+	shared "backend/domain/shared"
+	"fmt"
+	"sort"
+)
+
+var moveDirections = []shared.Direction{shared.North, shared.East, shared.South, shared.West}
+
+// Board は両プレイヤーの潜水艦を保持する.
+// 同じ座標に異なるプレイヤーの潜水艦が重なることは許容する(5x5x2の占有).
+type Board struct {
+	submarines map[shared.SubmarineId]*Submarine
+}
+
+func NewBoard() *Board {
+	return &Board{
+		submarines: make(map[shared.SubmarineId]*Submarine),
+	}
+}
+
+// PlaceSubmarine は playerId の潜水艦を初期HPで position に配置する.
+func (board *Board) PlaceSubmarine(playerId shared.PlayerId, position *Position) (*Submarine, error) {
+	if board == nil {
+		return nil, shared.ErrBoardIsNil
+	}
+	if playerId == "" {
+		return nil, shared.ErrOwnerIdIsEmpty
+	}
+	count := len(board.GetAllySubmarines(playerId))
+	id := shared.SubmarineId(fmt.Sprintf("%s-sub-%d", playerId, count+1))
+	submarine, err := NewSubmarine(id, playerId, position, shared.InitialHp)
+	if err != nil {
+		return nil, err
+	}
+	if err := board.AddSubmarine(submarine); err != nil {
+		return nil, err
+	}
+	return submarine, nil
+}
+
+// AddSubmarine は生成済みの潜水艦をそのまま盤面に加える.
+func (board *Board) AddSubmarine(submarine *Submarine) error {
+	if board == nil {
+		return shared.ErrBoardIsNil
+	}
+	if submarine == nil {
+		return shared.ErrSubmarineIsNil
+	}
+	if _, exists := board.submarines[submarine.GetId()]; exists {
+		return shared.ErrDuplicateSubmarineId
+	}
+	if len(board.GetAllySubmarines(submarine.GetOwnerId())) >= shared.SubmarineCount {
+		return shared.ErrSubmarineLimitExceeded
+	}
+	blocked, err := board.isBlockedFor(submarine.GetOwnerId(), submarine.GetPosition())
+	if err != nil {
+		return err
+	}
+	if blocked {
+		return shared.ErrPositionAlreadyOccupied
+	}
+	board.submarines[submarine.GetId()] = submarine
+	return nil
+}
+
+// MoveSubmarine は味方の潜水艦を direction に distance マス移動させる.
+// 移動先・経路に撃沈済みの潜水艦か味方の潜水艦がいる場合は MoveBlocked を返す.
+func (board *Board) MoveSubmarine(playerId shared.PlayerId, submarineId shared.SubmarineId, direction shared.Direction, distance int) (shared.MoveReportType, error) {
+	if board == nil {
+		return shared.MoveBlocked, shared.ErrBoardIsNil
+	}
+	submarine, err := board.GetSubmarine(submarineId)
+	if err != nil {
+		return shared.MoveBlocked, err
+	}
+	if submarine.GetOwnerId() != playerId {
+		return shared.MoveBlocked, shared.ErrSubmarineNotFound
+	}
+	if _, err := ResolveMoveOf(board, submarine, direction, distance); err != nil {
+		return shared.MoveBlocked, err
+	}
+	return shared.MoveSuccess, nil
+}
+
+// FindTargets は center にいる, attackerId から見た敵の撃沈されていない潜水艦を返す.
+func (board *Board) FindTargets(attackerId shared.PlayerId, center *Position) ([]*Submarine, error) {
+	if board == nil {
+		return nil, shared.ErrBoardIsNil
+	}
+	if center == nil {
+		return nil, shared.ErrPositionIsNil
+	}
+	targets := make([]*Submarine, 0)
+	for _, submarine := range board.GetOpponentSubmarines(attackerId) {
+		if submarine.IsSunk() {
+			continue
+		}
+		isEqual, err := submarine.GetPosition().isEqual(center)
+		if err != nil {
+			return nil, err
+		}
+		if isEqual {
+			targets = append(targets, submarine)
+		}
+	}
+	return targets, nil
+}
+
+// IsInAttackRange は target が playerId の撃沈されていない潜水艦の周囲8マスに含まれるかを返す.
+func (board *Board) IsInAttackRange(playerId shared.PlayerId, target *Position) (bool, error) {
+	if board == nil {
+		return false, shared.ErrBoardIsNil
+	}
+	if target == nil {
+		return false, shared.ErrPositionIsNil
+	}
+	for _, submarine := range board.GetAllySubmarines(playerId) {
+		inRange, err := isWithinReach(submarine, target)
+		if err != nil {
+			return false, err
+		}
+		if inRange {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// IsOccupied は撃沈済みを含め, いずれかの潜水艦が position にいるかを返す.
+func (board *Board) IsOccupied(position *Position) (bool, error) {
+	if board == nil {
+		return false, shared.ErrBoardIsNil
+	}
+	if position == nil {
+		return false, shared.ErrPositionIsNil
+	}
+	for _, submarine := range board.submarines {
+		isEqual, err := submarine.GetPosition().isEqual(position)
+		if err != nil {
+			return false, err
+		}
+		if isEqual {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// GetAllySubmarineAt は position にいる playerId の潜水艦を返す. いない場合は nil を返す.
+func (board *Board) GetAllySubmarineAt(playerId shared.PlayerId, position *Position) (*Submarine, error) {
+	if board == nil {
+		return nil, shared.ErrBoardIsNil
+	}
+	return findSubmarineAt(board.GetAllySubmarines(playerId), position)
+}
+
+// GetOpponentSubmarineAt は position にいる playerId の相手の潜水艦を返す. いない場合は nil を返す.
+func (board *Board) GetOpponentSubmarineAt(playerId shared.PlayerId, position *Position) (*Submarine, error) {
+	if board == nil {
+		return nil, shared.ErrBoardIsNil
+	}
+	return findSubmarineAt(board.GetOpponentSubmarines(playerId), position)
+}
+
+// GetAllySubmarines は playerId の潜水艦をid順で返す.
+func (board *Board) GetAllySubmarines(playerId shared.PlayerId) []*Submarine {
+	if board == nil {
+		return nil
+	}
+	return board.filterSubmarines(func(submarine *Submarine) bool {
+		return submarine.GetOwnerId() == playerId
+	})
+}
+
+// GetOpponentSubmarines は playerId 以外が所有する潜水艦をid順で返す.
+func (board *Board) GetOpponentSubmarines(playerId shared.PlayerId) []*Submarine {
+	if board == nil {
+		return nil
+	}
+	return board.filterSubmarines(func(submarine *Submarine) bool {
+		return submarine.GetOwnerId() != playerId
+	})
+}
+
+// RemainingHp は playerId の撃沈されていない潜水艦のHPの合計を返す.
+func (board *Board) RemainingHp(playerId shared.PlayerId) int {
+	hp := 0
+	for _, submarine := range board.GetAllySubmarines(playerId) {
+		if !submarine.IsSunk() {
+			hp += submarine.GetHp()
+		}
+	}
+	return hp
+}
+
+// LiveSubmarineCount は playerId の撃沈されていない潜水艦の数を返す.
+func (board *Board) LiveSubmarineCount(playerId shared.PlayerId) int {
+	count := 0
+	for _, submarine := range board.GetAllySubmarines(playerId) {
+		if !submarine.IsSunk() {
+			count++
+		}
+	}
+	return count
+}
+
+// IsFleetDestroyed は playerId の潜水艦がすべて撃沈されているかを返す.
+func (board *Board) IsFleetDestroyed(playerId shared.PlayerId) bool {
+	return board.LiveSubmarineCount(playerId) == 0
+}
+
+// LegalAttackTargets は playerId が攻撃できるマスを上の行から順に返す.
+func (board *Board) LegalAttackTargets(playerId shared.PlayerId) []*Position {
+	var reachable, occupied [boardSize][boardSize]bool
+	for _, submarine := range board.GetAllySubmarines(playerId) {
+		position := submarine.GetPosition()
+		occupied[position.y-shared.MinPosition][position.x-shared.MinPosition] = true
+		if submarine.IsSunk() {
+			continue
+		}
+		for y := position.y - 1; y <= position.y+1; y++ {
+			for x := position.x - 1; x <= position.x+1; x++ {
+				if x < shared.MinPosition || x > shared.MaxPosition || y < shared.MinPosition || y > shared.MaxPosition {
+					continue
+				}
+				reachable[y-shared.MinPosition][x-shared.MinPosition] = true
+			}
+		}
+	}
+	targets := make([]*Position, 0, boardSize*boardSize)
+	for y := shared.MinPosition; y <= shared.MaxPosition; y++ {
+		for x := shared.MinPosition; x <= shared.MaxPosition; x++ {
+			if reachable[y-shared.MinPosition][x-shared.MinPosition] && !occupied[y-shared.MinPosition][x-shared.MinPosition] {
+				targets = append(targets, &Position{x, y})
+			}
+		}
+	}
+	return targets
+}
+
+// LegalMoves は playerId の移動できる潜水艦と方向, 距離の組み合わせを潜水艦のid順に返す. 盤面は変更しない.
+func (board *Board) LegalMoves(playerId shared.PlayerId) []*MoveOutcome {
+	moves := make([]*MoveOutcome, 0)
+	for _, submarine := range board.GetAllySubmarines(playerId) {
+		if submarine.IsSunk() {
+			continue
+		}
+		for _, direction := range moveDirections {
+			for distance := shared.MinDistance; distance <= shared.MaxDistance; distance++ {
+				outcome, err := PlanMove(board, submarine, direction, distance)
+				if err != nil || outcome.reportType != shared.MoveSuccess {
+					continue
+				}
+				moves = append(moves, outcome)
+			}
+		}
+	}
+	return moves
+}
+
+// Clone は潜水艦ごと盤面を複製する. 複製した盤面を変更しても元の盤面には影響しない.
+func (board *Board) Clone() *Board {
+	if board == nil {
+		return nil
+	}
+	clone := NewBoard()
+	for id, submarine := range board.submarines {
+		copied := *submarine
+		clone.submarines[id] = &copied
+	}
+	return clone
+}
+
+func (board *Board) GetSubmarine(submarineId shared.SubmarineId) (*Submarine, error) {
+	if board == nil {
+		return nil, shared.ErrBoardIsNil
+	}
+	submarine, exists := board.submarines[submarineId]
+	if !exists {
+		return nil, shared.ErrSubmarineNotFound
+	}
+	return submarine, nil
+}
+
+func (board *Board) filterSubmarines(match func(submarine *Submarine) bool) []*Submarine {
+	submarines := make([]*Submarine, 0, len(board.submarines))
+	for _, submarine := range board.submarines {
+		if match(submarine) {
+			submarines = append(submarines, submarine)
+		}
+	}
+	sort.Slice(submarines, func(i, j int) bool {
+		return submarines[i].GetId() < submarines[j].GetId()
+	})
+	return submarines
+}
+
+// isBlockedFor は playerId の潜水艦が position に入れないかを返す.
+// 撃沈済みの潜水艦と味方の潜水艦が障害物になる.
+func (board *Board) isBlockedFor(playerId shared.PlayerId, position *Position) (bool, error) {
+	sunkExists, err := board.hasSunkSubmarineAt(position)
+	if err != nil || sunkExists {
+		return sunkExists, err
+	}
+	ally, err := board.GetAllySubmarineAt(playerId, position)
+	if err != nil {
+		return false, err
+	}
+	return ally != nil, nil
+}
+
+func (board *Board) hasSunkSubmarineAt(position *Position) (bool, error) {
+	if position == nil {
+		return false, shared.ErrPositionIsNil
+	}
+	for _, submarine := range board.submarines {
+		if !submarine.IsSunk() {
+			continue
+		}
+		isEqual, err := submarine.GetPosition().isEqual(position)
+		if err != nil {
+			return false, err
+		}
+		if isEqual {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// isWithinReach は target が撃沈されていない submarine の周囲8マスに含まれるかを返す.
+func isWithinReach(submarine *Submarine, target *Position) (bool, error) {
+	if submarine.IsSunk() {
+		return false, nil
+	}
+	neighbors, err := submarine.GetPosition().Neighbors8()
+	if err != nil {
+		return false, err
+	}
+	for _, neighbor := range neighbors {
+		isEqual, err := neighbor.isEqual(target)
+		if err != nil {
+			return false, err
+		}
+		if isEqual {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func findSubmarineAt(submarines []*Submarine, position *Position) (*Submarine, error) {
+	if position == nil {
+		return nil, shared.ErrPositionIsNil
+	}
+	for _, submarine := range submarines {
+		isEqual, err := submarine.GetPosition().isEqual(position)
+		if err != nil {
+			return nil, err
+		}
+		if isEqual {
+			return submarine, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,194 @@
+package domain
+
+import (
+	"testing"
+
+	shared "backend/domain/shared"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestBoard(t *testing.T, placements map[shared.PlayerId][]Position) *Board {
+	t.Helper()
+	board := NewBoard()
+	for playerId, positions := range placements {
+		for _, position := range positions {
+			position := position
+			_, err := board.PlaceSubmarine(playerId, &position)
+			assert.NoError(t, err)
+		}
+	}
+	return board
+}
+
+func TestPlaceSubmarineSuccess(t *testing.T) {
+	t.Run("[PlaceSubmarine: 4隻まで配置できる]", func(t *testing.T) {
+		board := newTestBoard(t, map[shared.PlayerId][]Position{
+			"p1": {{1, 1}, {2, 2}, {3, 3}, {4, 4}},
+		})
+		submarines := board.GetAllySubmarines("p1")
+		assert.Len(t, submarines, shared.SubmarineCount)
+		assert.Equal(t, shared.SubmarineId("p1-sub-1"), submarines[0].GetId())
+		assert.Equal(t, shared.InitialHp, submarines[0].GetHp())
+	})
+
+	t.Run("[PlaceSubmarine: 相手の潜水艦と同じ座標には配置できる]", func(t *testing.T) {
+		board := newTestBoard(t, map[shared.PlayerId][]Position{
+			"p1": {{3, 3}},
+		})
+		_, err := board.PlaceSubmarine("p2", &Position{3, 3})
+		assert.NoError(t, err)
+	})
+}
+
+func TestPlaceSubmarineFail(t *testing.T) {
+	testList := []struct {
+		name        string
+		playerId    shared.PlayerId
+		position    *Position
+		expectedErr error
+	}{
+		{"[PlaceSubmarine: 味方と重なる]", "p1", &Position{1, 1}, shared.ErrPositionAlreadyOccupied},
+		{"[PlaceSubmarine: 5隻目]", "p2", &Position{5, 5}, shared.ErrSubmarineLimitExceeded},
+		{"[PlaceSubmarine: 盤外]", "p1", &Position{0, 1}, shared.ErrOutOfBoard},
+		{"[PlaceSubmarine: positionがnil]", "p1", nil, shared.ErrPositionIsNil},
+		{"[PlaceSubmarine: playerIdが空]", "", &Position{5, 5}, shared.ErrOwnerIdIsEmpty},
+	}
+	for _, tl := range testList {
+		t.Run(tl.name, func(t *testing.T) {
+			board := newTestBoard(t, map[shared.PlayerId][]Position{
+				"p1": {{1, 1}},
+				"p2": {{1, 1}, {2, 2}, {3, 3}, {4, 4}},
+			})
+			submarine, err := board.PlaceSubmarine(tl.playerId, tl.position)
+			assert.Nil(t, submarine)
+			assert.ErrorIs(t, err, tl.expectedErr)
+		})
+	}
+
+	t.Run("[PlaceSubmarine: boardがnil]", func(t *testing.T) {
+		var board *Board
+		_, err := board.PlaceSubmarine("p1", &Position{1, 1})
+		assert.ErrorIs(t, err, shared.ErrBoardIsNil)
+	})
+}
+
+func TestMoveSubmarine(t *testing.T) {
+	testList := []struct {
+		name           string
+		direction      shared.Direction
+		distance       int
+		expectedReport shared.MoveReportType
+		expectedErr    error
+		expectedPos    Position
+	}{
+		{"[MoveSubmarine: 東に2マス]", shared.East, 2, shared.MoveSuccess, nil, Position{5, 3}},
+		{"[MoveSubmarine: 北に1マス]", shared.North, 1, shared.MoveSuccess, nil, Position{3, 2}},
+		{"[MoveSubmarine: 敵の潜水艦がいるマスへは移動できる]", shared.West, 1, shared.MoveSuccess, nil, Position{2, 3}},
+		{"[MoveSubmarine: 撃沈済みの潜水艦を越えられない]", shared.South, 2, shared.MoveBlocked, shared.ErrMoveBlocked, Position{3, 3}},
+		{"[MoveSubmarine: 撃沈済みの潜水艦のマスに止まれない]", shared.South, 1, shared.MoveBlocked, shared.ErrMoveBlocked, Position{3, 3}},
+		{"[MoveSubmarine: 味方のいるマスに止まれない]", shared.North, 2, shared.MoveBlocked, shared.ErrMoveBlocked, Position{3, 3}},
+		{"[MoveSubmarine: 盤外]", shared.West, 3, shared.MoveBlocked, shared.ErrInvalidMoveDistance, Position{3, 3}},
+		{"[MoveSubmarine: directionがUnknown]", shared.DirectionUnknown, 1, shared.MoveBlocked, shared.ErrInvalidAction, Position{3, 3}},
+	}
+	for _, tl := range testList {
+		t.Run(tl.name, func(t *testing.T) {
+			board := newTestBoard(t, map[shared.PlayerId][]Position{
+				"p1": {{3, 3}, {3, 1}},
+				"p2": {{3, 4}, {2, 3}},
+			})
+			sunk, err := board.GetOpponentSubmarineAt("p1", &Position{3, 4})
+			assert.NoError(t, err)
+			assert.NoError(t, sunk.TakeDamage(shared.InitialHp))
+
+			report, err := board.MoveSubmarine("p1", "p1-sub-1", tl.direction, tl.distance)
+			assert.ErrorIs(t, err, tl.expectedErr)
+			assert.Equal(t, tl.expectedReport, report)
+			submarine, err := board.GetSubmarine("p1-sub-1")
+			assert.NoError(t, err)
+			assert.Equal(t, tl.expectedPos, *submarine.GetPosition())
+		})
+	}
+
+	t.Run("[MoveSubmarine: 盤外へは移動できない]", func(t *testing.T) {
+		board := newTestBoard(t, map[shared.PlayerId][]Position{"p1": {{1, 1}}})
+		report, err := board.MoveSubmarine("p1", "p1-sub-1", shared.North, 1)
+		assert.Equal(t, shared.MoveReportType(shared.MoveBlocked), report)
+		assert.ErrorIs(t, err, shared.ErrOutOfBoard)
+	})
+
+	t.Run("[MoveSubmarine: 相手の潜水艦は動かせない]", func(t *testing.T) {
+		board := newTestBoard(t, map[shared.PlayerId][]Position{"p2": {{1, 1}}})
+		_, err := board.MoveSubmarine("p1", "p2-sub-1", shared.South, 1)
+		assert.ErrorIs(t, err, shared.ErrSubmarineNotFound)
+	})
+}
+
+func TestFindTargets(t *testing.T) {
+	board := newTestBoard(t, map[shared.PlayerId][]Position{
+		"p1": {{2, 2}},
+		"p2": {{2, 2}, {4, 4}},
+	})
+	testList := []struct {
+		name        string
+		attackerId  shared.PlayerId
+		center      Position
+		expectedIds []shared.SubmarineId
+	}{
+		{"[FindTargets: 敵がいる]", "p1", Position{4, 4}, []shared.SubmarineId{"p2-sub-2"}},
+		{"[FindTargets: 味方は対象外]", "p2", Position{4, 4}, []shared.SubmarineId{}},
+		{"[FindTargets: 重なった座標では敵のみ]", "p1", Position{2, 2}, []shared.SubmarineId{"p2-sub-1"}},
+		{"[FindTargets: 誰もいない]", "p1", Position{5, 5}, []shared.SubmarineId{}},
+	}
+	for _, tl := range testList {
+		t.Run(tl.name, func(t *testing.T) {
+			targets, err := board.FindTargets(tl.attackerId, &tl.center)
+			assert.NoError(t, err)
+			ids := make([]shared.SubmarineId, 0, len(targets))
+			for _, target := range targets {
+				ids = append(ids, target.GetId())
+			}
+			assert.Equal(t, tl.expectedIds, ids)
+		})
+	}
+}
+
+func TestIsOccupied(t *testing.T) {
+	board := newTestBoard(t, map[shared.PlayerId][]Position{
+		"p1": {{2, 2}},
+		"p2": {{4, 4}},
+	})
+	testList := []struct {
+		name     string
+		position Position
+		expected bool
+	}{
+		{"[IsOccupied: 味方がいる]", Position{2, 2}, true},
+		{"[IsOccupied: 敵がいる]", Position{4, 4}, true},
+		{"[IsOccupied: 空き]", Position{3, 3}, false},
+	}
+	for _, tl := range testList {
+		t.Run(tl.name, func(t *testing.T) {
+			occupied, err := board.IsOccupied(&tl.position)
+			assert.NoError(t, err)
+			assert.Equal(t, tl.expected, occupied)
+		})
+	}
+}
+
+func TestGetSubmarinesByOwner(t *testing.T) {
+	board := newTestBoard(t, map[shared.PlayerId][]Position{
+		"p1": {{1, 1}, {2, 2}},
+		"p2": {{4, 4}},
+	})
+	assert.Len(t, board.GetAllySubmarines("p1"), 2)
+	assert.Len(t, board.GetOpponentSubmarines("p1"), 1)
+
+	ally, err := board.GetAllySubmarineAt("p1", &Position{2, 2})
+	assert.NoError(t, err)
+	assert.Equal(t, shared.SubmarineId("p1-sub-2"), ally.GetId())
+
+	opponent, err := board.GetOpponentSubmarineAt("p1", &Position{2, 2})
+	assert.NoError(t, err)
+	assert.Nil(t, opponent)
+}
@@ -0,0 +1,23 @@
+package domain
+
+import "time"
+
+// Clock はゲームが参照する現在時刻を提供する. テストでは任意の時刻を返す実装を注入する.
+type Clock interface {
+	Now() time.Time
+}
+
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// fixedClock は常に同じ時刻を返す. 時間切れを起こさずに対戦を試行する場合に使う.
+type fixedClock struct {
+	at time.Time
+}
+
+func (clock fixedClock) Now() time.Time {
+	return clock.at
+}
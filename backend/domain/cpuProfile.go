@@ -0,0 +1,102 @@
+package domain
+
+import (
+	shared "backend/domain/shared"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultAggression    = 0.0
+	DefaultMoveThreshold = 0.8
+	// MaxSearchIterations と MaxSearchTimeBudget は探索する CPU に指定できる1手あたりの探索量の上限.
+	MaxSearchIterations = 100000
+	MaxSearchTimeBudget = 10 * time.Second
+)
+
+// CpuProfile はプレイヤーBを担当する CPU の種類と性格.
+// aggression は危険な潜水艦を逃がすより攻撃を優先する度合いで, 0 なら常に逃がし, 1 なら常に攻撃する.
+// moveThreshold は相手から見た存在確率がこれ以上の潜水艦を危険とみなす閾値.
+// random と mcts はこれらのパラメータを使わないため, 既定値だけを受け付ける.
+// iterations と timeBudget は mcts の1手あたりの反復回数と思考時間の上限で, 0 なら CPU の既定値を使う.
+type CpuProfile struct {
+	name          shared.CpuName
+	aggression    float64
+	moveThreshold float64
+	discountRate  float64
+	iterations    int
+	timeBudget    time.Duration
+}
+
+// NewCpuProfile は name の CPU を既定のパラメータで作る.
+func NewCpuProfile(name shared.CpuName) (*CpuProfile, error) {
+	return NewCpuProfileWithParams(name, DefaultAggression, DefaultMoveThreshold, DefaultDiscountRate)
+}
+
+func NewCpuProfileWithParams(name shared.CpuName, aggression float64, moveThreshold float64, discountRate float64) (*CpuProfile, error) {
+	if strings.TrimSpace(name.String()) == "" {
+		return nil, shared.ErrInvalidCpuName
+	}
+	if aggression < 0 || aggression > 1 || moveThreshold < 0 || moveThreshold > 1 {
+		return nil, shared.ErrInvalidCpuProfile
+	}
+	if discountRate <= 0 || discountRate > 1 {
+		return nil, shared.ErrInvalidDiscountRate
+	}
+	if ignoresParams(name) && (aggression != DefaultAggression || moveThreshold != DefaultMoveThreshold || discountRate != DefaultDiscountRate) {
+		return nil, shared.ErrInvalidCpuProfile
+	}
+	return &CpuProfile{
+		name:          name,
+		aggression:    aggression,
+		moveThreshold: moveThreshold,
+		discountRate:  discountRate,
+	}, nil
+}
+
+// WithSearchBudget は探索量を iterations と timeBudget にした複製を返す.
+// 探索しない CPU には 0 以外を指定できない.
+func (profile *CpuProfile) WithSearchBudget(iterations int, timeBudget time.Duration) (*CpuProfile, error) {
+	if profile == nil {
+		return nil, shared.ErrCpuProfileIsNil
+	}
+	if iterations < 0 || iterations > MaxSearchIterations || timeBudget < 0 || timeBudget > MaxSearchTimeBudget {
+		return nil, shared.ErrInvalidCpuProfile
+	}
+	if profile.name != shared.CpuMcts && (iterations != 0 || timeBudget != 0) {
+		return nil, shared.ErrInvalidCpuProfile
+	}
+	copied := *profile
+	copied.iterations = iterations
+	copied.timeBudget = timeBudget
+	return &copied, nil
+}
+
+// ignoresParams は name の CPU がパラメータを使わずに行動を決めるかを返す.
+func ignoresParams(name shared.CpuName) bool {
+	return name == shared.CpuRandom || name == shared.CpuMcts
+}
+
+func (profile *CpuProfile) GetName() shared.CpuName {
+	return profile.name
+}
+
+func (profile *CpuProfile) GetAggression() float64 {
+	return profile.aggression
+}
+
+func (profile *CpuProfile) GetMoveThreshold() float64 {
+	return profile.moveThreshold
+}
+
+func (profile *CpuProfile) GetDiscountRate() float64 {
+	return profile.discountRate
+}
+
+func (profile *CpuProfile) GetIterations() int {
+	return profile.iterations
+}
+
+func (profile *CpuProfile) GetTimeBudget() time.Duration {
+	return profile.timeBudget
+}
@@ -0,0 +1,144 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	shared "backend/domain/shared"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCpuProfile(t *testing.T) {
+	profile, err := NewCpuProfile(shared.CpuHeuristic)
+	assert.NoError(t, err)
+	assert.Equal(t, shared.CpuHeuristic, profile.GetName())
+	assert.Equal(t, DefaultAggression, profile.GetAggression())
+	assert.Equal(t, DefaultMoveThreshold, profile.GetMoveThreshold())
+	assert.Equal(t, DefaultDiscountRate, profile.GetDiscountRate())
+
+	profile, err = NewCpuProfileWithParams(shared.CpuHeuristic, 1, 0, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1.0, profile.GetAggression())
+
+	profile, err = NewCpuProfile(shared.CpuMcts)
+	assert.NoError(t, err)
+	assert.Equal(t, shared.CpuMcts, profile.GetName())
+}
+
+func TestNewCpuProfileFail(t *testing.T) {
+	testList := []struct {
+		name          string
+		cpuName       shared.CpuName
+		aggression    float64
+		moveThreshold float64
+		discountRate  float64
+		expectedErr   error
+	}{
+		{
+			name:          "[NewCpuProfile: 名前が空白のみ]",
+			cpuName:       " ",
+			moveThreshold: DefaultMoveThreshold,
+			discountRate:  DefaultDiscountRate,
+			expectedErr:   shared.ErrInvalidCpuName,
+		},
+		{
+			name:          "[NewCpuProfile: aggressionが1より大きい]",
+			cpuName:       shared.CpuHeuristic,
+			aggression:    1.5,
+			moveThreshold: DefaultMoveThreshold,
+			discountRate:  DefaultDiscountRate,
+			expectedErr:   shared.ErrInvalidCpuProfile,
+		},
+		{
+			name:          "[NewCpuProfile: moveThresholdが負]",
+			cpuName:       shared.CpuHeuristic,
+			moveThreshold: -0.1,
+			discountRate:  DefaultDiscountRate,
+			expectedErr:   shared.ErrInvalidCpuProfile,
+		},
+		{
+			name:          "[NewCpuProfile: mctsはaggressionを使わない]",
+			cpuName:       shared.CpuMcts,
+			aggression:    0.5,
+			moveThreshold: DefaultMoveThreshold,
+			discountRate:  DefaultDiscountRate,
+			expectedErr:   shared.ErrInvalidCpuProfile,
+		},
+		{
+			name:          "[NewCpuProfile: randomは割引率を使わない]",
+			cpuName:       shared.CpuRandom,
+			moveThreshold: DefaultMoveThreshold,
+			discountRate:  0.5,
+			expectedErr:   shared.ErrInvalidCpuProfile,
+		},
+		{
+			name:          "[NewCpuProfile: 割引率が0]",
+			cpuName:       shared.CpuHeuristic,
+			moveThreshold: DefaultMoveThreshold,
+			expectedErr:   shared.ErrInvalidDiscountRate,
+		},
+	}
+	for _, tl := range testList {
+		t.Run(tl.name, func(t *testing.T) {
+			_, err := NewCpuProfileWithParams(tl.cpuName, tl.aggression, tl.moveThreshold, tl.discountRate)
+			assert.ErrorIs(t, err, tl.expectedErr)
+		})
+	}
+}
+
+func TestCpuProfileWithSearchBudget(t *testing.T) {
+	mcts, err := NewCpuProfile(shared.CpuMcts)
+	assert.NoError(t, err)
+	heuristic, err := NewCpuProfile(shared.CpuHeuristic)
+	assert.NoError(t, err)
+
+	t.Run("[WithSearchBudget: mctsの探索量]", func(t *testing.T) {
+		profile, err := mcts.WithSearchBudget(200, 500*time.Millisecond)
+		assert.NoError(t, err)
+		assert.Equal(t, 200, profile.GetIterations())
+		assert.Equal(t, 500*time.Millisecond, profile.GetTimeBudget())
+		assert.Equal(t, 0, mcts.GetIterations())
+	})
+
+	t.Run("[WithSearchBudget: 探索しないCPUも既定値なら受け付ける]", func(t *testing.T) {
+		profile, err := heuristic.WithSearchBudget(0, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, heuristic, profile)
+	})
+
+	testList := []struct {
+		name       string
+		profile    *CpuProfile
+		iterations int
+		timeBudget time.Duration
+	}{
+		{"[WithSearchBudget: 反復回数が負]", mcts, -1, 0},
+		{"[WithSearchBudget: 反復回数が上限より多い]", mcts, MaxSearchIterations + 1, 0},
+		{"[WithSearchBudget: 思考時間が上限より長い]", mcts, 0, MaxSearchTimeBudget + time.Millisecond},
+		{"[WithSearchBudget: heuristicは探索しない]", heuristic, 100, 0},
+	}
+	for _, tl := range testList {
+		t.Run(tl.name, func(t *testing.T) {
+			_, err := tl.profile.WithSearchBudget(tl.iterations, tl.timeBudget)
+			assert.ErrorIs(t, err, shared.ErrInvalidCpuProfile)
+		})
+	}
+}
+
+func TestGameCpuProfile(t *testing.T) {
+	board := newTestBoard(t, map[shared.PlayerId][]Position{"p1": defaultP1Positions, "p2": defaultP2Positions})
+	game, err := NewGame("g1", "p1", "p2", board)
+	assert.NoError(t, err)
+	assert.Nil(t, game.GetCpuProfile())
+	assert.ErrorIs(t, game.SetCpuProfile(nil), shared.ErrCpuProfileIsNil)
+
+	profile, err := NewCpuProfileWithParams(shared.CpuProbabilistic, 0.3, 0.6, 0.9)
+	assert.NoError(t, err)
+	assert.NoError(t, game.SetCpuProfile(profile))
+	assert.Equal(t, profile, game.GetCpuProfile())
+	assert.NotSame(t, profile, game.GetCpuProfile())
+
+	assert.NoError(t, game.Start())
+	assert.ErrorIs(t, game.SetCpuProfile(profile), shared.ErrGameAlreadyStarted)
+}
@@ -0,0 +1,79 @@
+package domain
+
+import (
+	shared "backend/domain/shared"
+)
+
+// CpuCandidate は CPU が比べた行動の候補. position は攻撃先か移動先で, 動かす潜水艦を決めていない移動では nil.
+// score は CPU ごとの評価値.
+type CpuCandidate struct {
+	command  *ActionCommand
+	position *Position
+	score    float64
+}
+
+func NewCpuCandidate(command *ActionCommand, position *Position, score float64) (*CpuCandidate, error) {
+	if command == nil {
+		return nil, shared.ErrActionCommandIsNil
+	}
+	return &CpuCandidate{
+		command:  command,
+		position: position,
+		score:    score,
+	}, nil
+}
+
+func (candidate *CpuCandidate) GetCommand() *ActionCommand {
+	return candidate.command
+}
+
+func (candidate *CpuCandidate) GetPosition() *Position {
+	return candidate.position
+}
+
+func (candidate *CpuCandidate) GetScore() float64 {
+	return candidate.score
+}
+
+// CpuRationale は CPU が行動を選んだ理由. tier と detail は選んだ規則, candidates は比べた候補,
+// prediction はその時に使った敵艦の存在確率マップ (5x5) の複製.
+type CpuRationale struct {
+	tier       shared.CpuRuleTier
+	detail     string
+	candidates []*CpuCandidate
+	prediction [][]float64
+}
+
+func NewCpuRationale(tier shared.CpuRuleTier, detail string, candidates []*CpuCandidate, prediction [][]float64) *CpuRationale {
+	copied := make([][]float64, len(prediction))
+	for y := range prediction {
+		copied[y] = append([]float64(nil), prediction[y]...)
+	}
+	return &CpuRationale{
+		tier:       tier,
+		detail:     detail,
+		candidates: append([]*CpuCandidate(nil), candidates...),
+		prediction: copied,
+	}
+}
+
+func (rationale *CpuRationale) GetTier() shared.CpuRuleTier {
+	return rationale.tier
+}
+
+func (rationale *CpuRationale) GetDetail() string {
+	return rationale.detail
+}
+
+func (rationale *CpuRationale) GetCandidates() []*CpuCandidate {
+	return append([]*CpuCandidate(nil), rationale.candidates...)
+}
+
+// GetPrediction は存在確率マップの複製を返す. 存在確率を使わない CPU では空になる.
+func (rationale *CpuRationale) GetPrediction() [][]float64 {
+	copied := make([][]float64, len(rationale.prediction))
+	for y := range rationale.prediction {
+		copied[y] = append([]float64(nil), rationale.prediction[y]...)
+	}
+	return copied
+}
@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"testing"
+
+	shared "backend/domain/shared"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCpuRationale(t *testing.T) {
+	target, err := NewPosition(2, 3)
+	assert.NoError(t, err)
+	command, err := NewActionCommand("p1", shared.Attack, target, shared.DirectionUnknown, 0)
+	assert.NoError(t, err)
+	candidate, err := NewCpuCandidate(command, target, 0.5)
+	assert.NoError(t, err)
+	assert.Same(t, command, candidate.GetCommand())
+	assert.Same(t, target, candidate.GetPosition())
+	assert.Equal(t, 0.5, candidate.GetScore())
+
+	candidates := []*CpuCandidate{candidate}
+	prediction := [][]float64{{0.1, 0.2}, {0.3, 0.4}}
+	rationale := NewCpuRationale(shared.CpuTierBestAttack, "detail", candidates, prediction)
+	candidates[0] = nil
+	prediction[0][0] = 1
+	assert.Equal(t, shared.CpuTierBestAttack, rationale.GetTier())
+	assert.Equal(t, "detail", rationale.GetDetail())
+	assert.Equal(t, []*CpuCandidate{candidate}, rationale.GetCandidates())
+	assert.Equal(t, [][]float64{{0.1, 0.2}, {0.3, 0.4}}, rationale.GetPrediction())
+
+	rationale.GetPrediction()[1][1] = 1
+	assert.Equal(t, 0.4, rationale.GetPrediction()[1][1])
+
+	_, err = NewCpuCandidate(nil, target, 0)
+	assert.ErrorIs(t, err, shared.ErrActionCommandIsNil)
+}
+
+func TestGameDebug(t *testing.T) {
+	board := newTestBoard(t, map[shared.PlayerId][]Position{"p1": defaultP1Positions, "p2": defaultP2Positions})
+	game, err := NewGame("g1", "p1", "p2", board)
+	assert.NoError(t, err)
+	assert.False(t, game.IsDebug())
+	assert.NoError(t, game.SetDebug(true))
+	assert.True(t, game.IsDebug())
+
+	assert.NoError(t, game.Start())
+	assert.NoError(t, game.SetDebug(false))
+	assert.False(t, game.IsDebug())
+}
@@ -0,0 +1,401 @@
+package domain
+
+import (
+	shared "backend/domain/shared"
+	"fmt"
+	"math/rand"
+	"sort"
+)
+
+// 1隻の潜水艦を「マスの番号 << 2 | hp」の7bitで表し, 4隻分を1つの uint32 に詰めて敵艦隊の配置とする.
+// 撃沈された潜水艦の枠は emptyShip で埋める.
+const (
+	shipBits  = 7
+	shipMask  = 1<<shipBits - 1
+	hpBits    = 2
+	hpMask    = 1<<hpBits - 1
+	emptyShip = shipMask
+)
+
+type fleetKey uint32
+
+// fleet は1つの配置に含まれる撃沈されていない敵艦. 各要素は cell<<hpBits | hp.
+type fleet []uint8
+
+// ExactPredictionBoard は行動記録と矛盾しない敵艦隊の配置を全て列挙し, 各マスに敵艦がいる確率を正確に求める.
+// 初期配置は一様に選ばれ, 敵の移動では動かすことのできる潜水艦のいずれかが等確率で動いたものとする.
+// 5x5 の盤面に4隻の配置は C(25,4) 通りしかないため, 列挙しても十分に速い.
+type ExactPredictionBoard struct {
+	weights     map[fleetKey]float64
+	sunk        [boardSize][boardSize]bool
+	currentTurn int
+	// grid は weights から求めた各マスの存在確率. 配置が変わるたびに求め直す.
+	grid [boardSize][boardSize]float64
+	// sampleKeys と cumulative は SampleFleet のために配置を順に並べ, 重みを累積したもの. 配置が変わると破棄する.
+	sampleKeys []fleetKey
+	cumulative []float64
+}
+
+func NewExactPredictionBoard() *ExactPredictionBoard {
+	board := &ExactPredictionBoard{
+		weights: make(map[fleetKey]float64),
+	}
+	cells := make([]int, shared.SubmarineCount)
+	var enumerate func(depth int, from int)
+	enumerate = func(depth int, from int) {
+		if depth == len(cells) {
+			ships := make(fleet, len(cells))
+			for i, cell := range cells {
+				ships[i] = packShip(cell, shared.InitialHp)
+			}
+			board.weights[ships.key()] = 1
+			return
+		}
+		for cell := from; cell < boardSize*boardSize; cell++ {
+			cells[depth] = cell
+			enumerate(depth+1, cell+1)
+		}
+	}
+	enumerate(0, 0)
+	board.normalize()
+	board.recomputeGrid()
+	return board
+}
+
+// ApplyTurnLog は viewerId から見た1件の行動記録と矛盾する配置を取り除き, 敵の移動は配置を動かして反映する.
+// 全ての配置が矛盾する場合は何も変更せず ErrNoConsistentFleet を返す.
+func (board *ExactPredictionBoard) ApplyTurnLog(viewerId shared.PlayerId, log *TurnLog) error {
+	if board == nil {
+		return shared.ErrPredictionBoardIsNil
+	}
+	if log == nil {
+		return shared.ErrTurnLogIsNil
+	}
+	if log.turn < board.currentTurn {
+		return shared.ErrInvalidTurn
+	}
+	previousTurn := board.currentTurn
+	board.currentTurn = log.turn
+	if log.IsRejected() {
+		return nil
+	}
+
+	var transition func(ships fleet) []fleet
+	if log.playerId == viewerId {
+		if log.actionType != shared.Attack {
+			return nil
+		}
+		target, err := cellOf(log.target)
+		if err != nil {
+			return err
+		}
+		transition = func(ships fleet) []fleet {
+			return ships.afterOwnAttack(target, log.attackReport)
+		}
+	} else {
+		switch log.actionType {
+		case shared.Attack:
+			target, err := cellOf(log.target)
+			if err != nil {
+				return err
+			}
+			transition = func(ships fleet) []fleet {
+				return ships.afterEnemyAttack(target)
+			}
+		case shared.Move:
+			if log.distance < shared.MinDistance || log.distance > shared.MaxDistance {
+				return shared.ErrInvalidMoveDistance
+			}
+			if log.direction == shared.DirectionUnknown {
+				return shared.ErrInvalidAction
+			}
+			transition = func(ships fleet) []fleet {
+				return ships.afterEnemyMove(log.direction, log.distance, &board.sunk)
+			}
+		default:
+			return nil
+		}
+	}
+
+	next := make(map[fleetKey]float64, len(board.weights))
+	for key, weight := range board.weights {
+		successors := transition(key.fleet())
+		for _, successor := range successors {
+			next[successor.key()] += weight / float64(len(successors))
+		}
+	}
+	if len(next) == 0 {
+		board.currentTurn = previousTurn
+		return shared.ErrNoConsistentFleet
+	}
+	board.weights = next
+	board.sampleKeys = nil
+	board.cumulative = nil
+	board.normalize()
+	board.recomputeGrid()
+	if log.actionType == shared.Attack && log.attackReport == shared.HitAndSunk {
+		board.sunk[log.target.y-shared.MinPosition][log.target.x-shared.MinPosition] = true
+	}
+	return nil
+}
+
+// Grid は各マスにいる撃沈されていない敵艦の数の期待値を [y][x] の順で複製して返す.
+// 敵艦は同じマスに重ならないため, そのマスに敵艦がいる確率と等しい.
+func (board *ExactPredictionBoard) Grid() [][]float64 {
+	grid := make([][]float64, boardSize)
+	for y := range board.grid {
+		grid[y] = make([]float64, boardSize)
+		copy(grid[y], board.grid[y][:])
+	}
+	return grid
+}
+
+func (board *ExactPredictionBoard) Possibility(position *Position) (float64, error) {
+	if board == nil {
+		return 0, shared.ErrPredictionBoardIsNil
+	}
+	cell, err := cellOf(position)
+	if err != nil {
+		return 0, err
+	}
+	return board.grid[cell/boardSize][cell%boardSize], nil
+}
+
+// SampleFleet は重みに従って配置を1つ選び, ownerId の撃沈されていない潜水艦として返す.
+// 同じ状態の盤面に同じ seed の random を渡せば同じ配置が選ばれる.
+func (board *ExactPredictionBoard) SampleFleet(random *rand.Rand, ownerId shared.PlayerId) ([]*Submarine, error) {
+	if board == nil {
+		return nil, shared.ErrPredictionBoardIsNil
+	}
+	if board.sampleKeys == nil {
+		board.sampleKeys = make([]fleetKey, 0, len(board.weights))
+		for key := range board.weights {
+			board.sampleKeys = append(board.sampleKeys, key)
+		}
+		sort.Slice(board.sampleKeys, func(i, j int) bool { return board.sampleKeys[i] < board.sampleKeys[j] })
+		board.cumulative = make([]float64, len(board.sampleKeys))
+		total := 0.0
+		for i, key := range board.sampleKeys {
+			total += board.weights[key]
+			board.cumulative[i] = total
+		}
+	}
+	if len(board.sampleKeys) == 0 {
+		return nil, shared.ErrNoConsistentFleet
+	}
+	threshold := random.Float64() * board.cumulative[len(board.cumulative)-1]
+	index := sort.SearchFloat64s(board.cumulative, threshold)
+	if index >= len(board.sampleKeys) {
+		index = len(board.sampleKeys) - 1
+	}
+	ships := board.sampleKeys[index].fleet()
+	submarines := make([]*Submarine, 0, len(ships))
+	for i, ship := range ships {
+		cell := shipCell(ship)
+		position := &Position{cell%boardSize + shared.MinPosition, cell/boardSize + shared.MinPosition}
+		id := shared.SubmarineId(fmt.Sprintf("%s-sample-%d", ownerId, i+1))
+		submarine, err := NewSubmarine(id, ownerId, position, shipHp(ship))
+		if err != nil {
+			return nil, err
+		}
+		submarines = append(submarines, submarine)
+	}
+	return submarines, nil
+}
+
+// ConfigurationCount は行動記録と矛盾しない敵艦隊の配置の数を返す.
+func (board *ExactPredictionBoard) ConfigurationCount() int {
+	return len(board.weights)
+}
+
+func (board *ExactPredictionBoard) GetCurrentTurn() int {
+	return board.currentTurn
+}
+
+// recomputeGrid は配置の重みを各マスに足し合わせて grid を求め直す.
+func (board *ExactPredictionBoard) recomputeGrid() {
+	board.grid = [boardSize][boardSize]float64{}
+	for key, weight := range board.weights {
+		for _, ship := range key.fleet() {
+			cell := shipCell(ship)
+			board.grid[cell/boardSize][cell%boardSize] += weight
+		}
+	}
+}
+
+func (board *ExactPredictionBoard) normalize() {
+	total := 0.0
+	for _, weight := range board.weights {
+		total += weight
+	}
+	for key := range board.weights {
+		board.weights[key] /= total
+	}
+}
+
+// afterOwnAttack は自分の攻撃の結果と矛盾しなければ, ダメージを反映した配置を返す.
+func (ships fleet) afterOwnAttack(target int, report shared.AttackReportType) []fleet {
+	switch report {
+	case shared.Miss:
+		for _, ship := range ships {
+			if chebyshev(shipCell(ship), target) <= 1 {
+				return nil
+			}
+		}
+		return []fleet{ships}
+	case shared.WaveHigh:
+		around := false
+		for _, ship := range ships {
+			switch chebyshev(shipCell(ship), target) {
+			case 0:
+				return nil
+			case 1:
+				around = true
+			}
+		}
+		if !around {
+			return nil
+		}
+		return []fleet{ships}
+	case shared.Hit, shared.HitAndSunk:
+		for i, ship := range ships {
+			if shipCell(ship) != target {
+				continue
+			}
+			hp := shipHp(ship) - attackDamage
+			if (hp > 0) != (report == shared.Hit) {
+				return nil
+			}
+			damaged := make(fleet, 0, len(ships))
+			damaged = append(damaged, ships[:i]...)
+			if hp > 0 {
+				damaged = append(damaged, packShip(target, hp))
+			}
+			return []fleet{append(damaged, ships[i+1:]...)}
+		}
+		return nil
+	}
+	return []fleet{ships}
+}
+
+// afterEnemyAttack は敵の攻撃が可能な配置かを判定する.
+// 攻撃座標には敵艦がおらず, 周囲8マスのいずれかに敵艦がいなければならない.
+func (ships fleet) afterEnemyAttack(target int) []fleet {
+	around := false
+	for _, ship := range ships {
+		switch chebyshev(shipCell(ship), target) {
+		case 0:
+			return nil
+		case 1:
+			around = true
+		}
+	}
+	if !around {
+		return nil
+	}
+	return []fleet{ships}
+}
+
+// afterEnemyMove は敵艦のいずれか1隻を動かした配置を全て返す.
+// 経路と移動先は盤面内で, 撃沈済みの潜水艦と他の敵艦がいないマスでなければならない.
+func (ships fleet) afterEnemyMove(direction shared.Direction, distance int, sunk *[boardSize][boardSize]bool) []fleet {
+	dx, dy := direction.Delta()
+	occupied := make(map[int]bool, len(ships))
+	for _, ship := range ships {
+		occupied[shipCell(ship)] = true
+	}
+	successors := make([]fleet, 0, len(ships))
+	for i, ship := range ships {
+		cell := shipCell(ship)
+		x, y := cell%boardSize, cell/boardSize
+		destination := -1
+		for step := 1; step <= distance; step++ {
+			nextX, nextY := x+dx*step, y+dy*step
+			if nextX < 0 || nextX >= boardSize || nextY < 0 || nextY >= boardSize {
+				destination = -1
+				break
+			}
+			next := nextY*boardSize + nextX
+			if sunk[nextY][nextX] || occupied[next] {
+				destination = -1
+				break
+			}
+			destination = next
+		}
+		if destination < 0 {
+			continue
+		}
+		moved := make(fleet, len(ships))
+		copy(moved, ships)
+		moved[i] = packShip(destination, shipHp(ship))
+		successors = append(successors, moved)
+	}
+	return successors
+}
+
+func (ships fleet) key() fleetKey {
+	sorted := make(fleet, len(ships))
+	copy(sorted, ships)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	var key fleetKey
+	for slot := 0; slot < shared.SubmarineCount; slot++ {
+		ship := uint8(emptyShip)
+		if slot < len(sorted) {
+			ship = sorted[slot]
+		}
+		key |= fleetKey(ship) << (shipBits * slot)
+	}
+	return key
+}
+
+func (key fleetKey) fleet() fleet {
+	ships := make(fleet, 0, shared.SubmarineCount)
+	for slot := 0; slot < shared.SubmarineCount; slot++ {
+		ship := uint8(key >> (shipBits * slot) & shipMask)
+		if ship == emptyShip {
+			break
+		}
+		ships = append(ships, ship)
+	}
+	return ships
+}
+
+func packShip(cell int, hp int) uint8 {
+	return uint8(cell<<hpBits | hp)
+}
+
+func shipCell(ship uint8) int {
+	return int(ship >> hpBits)
+}
+
+func shipHp(ship uint8) int {
+	return int(ship & hpMask)
+}
+
+// cellOf は position を左上から数えたマスの番号に変換する.
+func cellOf(position *Position) (int, error) {
+	within, err := position.withinBoard()
+	if err != nil {
+		return 0, err
+	}
+	if !within {
+		return 0, shared.ErrOutOfBoard
+	}
+	return (position.y-shared.MinPosition)*boardSize + position.x - shared.MinPosition, nil
+}
+
+// chebyshev は2つのマスの縦横斜めの距離を返す. 周囲8マスであれば1になる.
+func chebyshev(a int, b int) int {
+	dx := a%boardSize - b%boardSize
+	dy := a/boardSize - b/boardSize
+	if dx < 0 {
+		dx = -dx
+	}
+	if dy < 0 {
+		dy = -dy
+	}
+	if dx > dy {
+		return dx
+	}
+	return dy
+}
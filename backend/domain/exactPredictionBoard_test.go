@@ -0,0 +1,250 @@
+package domain
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	shared "backend/domain/shared"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newExactTestLog(t *testing.T, turn int, playerId shared.PlayerId, target *Position, attackReport shared.AttackReportType) *TurnLog {
+	t.Helper()
+	log, err := NewTurnLog("g1", turn, playerId, "", shared.Attack, target, shared.DirectionUnknown, 0, attackReport, shared.MoveReportUnknown, shared.ErrorNone, time.Time{})
+	assert.NoError(t, err)
+	return log
+}
+
+func sumGrid(grid [][]float64) float64 {
+	sum := 0.0
+	for y := range grid {
+		for x := range grid[y] {
+			sum += grid[y][x]
+		}
+	}
+	return sum
+}
+
+func contains(cells []int, cell int) bool {
+	for _, c := range cells {
+		if c == cell {
+			return true
+		}
+	}
+	return false
+}
+
+func TestNewExactPredictionBoard(t *testing.T) {
+	board := NewExactPredictionBoard()
+	assert.Equal(t, 12650, board.ConfigurationCount())
+	for _, row := range board.Grid() {
+		for _, possibility := range row {
+			assert.InDelta(t, 4.0/25, possibility, 1e-9)
+		}
+	}
+}
+
+func TestExactPredictionBoardApplyTurnLog(t *testing.T) {
+	testList := []struct {
+		name          string
+		log           *TurnLog
+		expectedCount int
+		position      *Position
+		expected      float64
+	}{
+		{
+			"[ExactPredictionBoard: 異常なしなら周囲9マスに敵艦はいない]",
+			newExactTestLog(t, 1, "p1", &Position{3, 3}, shared.Miss),
+			1820,
+			&Position{2, 2},
+			0,
+		},
+		{
+			"[ExactPredictionBoard: 異常なしの範囲外は残り16マスに均等]",
+			newExactTestLog(t, 1, "p1", &Position{3, 3}, shared.Miss),
+			1820,
+			&Position{1, 1},
+			4.0 / 16,
+		},
+		{
+			"[ExactPredictionBoard: 波高しなら攻撃座標に敵艦はおらず周囲にいる]",
+			newExactTestLog(t, 1, "p1", &Position{1, 1}, shared.WaveHigh),
+			4641,
+			&Position{1, 1},
+			0,
+		},
+		{
+			"[ExactPredictionBoard: 命中したマスには必ず敵艦がいる]",
+			newExactTestLog(t, 1, "p1", &Position{3, 3}, shared.Hit),
+			2024,
+			&Position{3, 3},
+			1,
+		},
+		{
+			"[ExactPredictionBoard: 敵の攻撃は波高しと同じ制約]",
+			newExactTestLog(t, 1, "p2", &Position{1, 1}, shared.Miss),
+			4641,
+			&Position{1, 1},
+			0,
+		},
+	}
+	for _, tl := range testList {
+		t.Run(tl.name, func(t *testing.T) {
+			board := NewExactPredictionBoard()
+			assert.NoError(t, board.ApplyTurnLog("p1", tl.log))
+			assert.Equal(t, tl.expectedCount, board.ConfigurationCount())
+			possibility, err := board.Possibility(tl.position)
+			assert.NoError(t, err)
+			assert.InDelta(t, tl.expected, possibility, 1e-9)
+			assert.InDelta(t, 4, sumGrid(board.Grid()), 1e-9)
+		})
+	}
+
+	t.Run("[ExactPredictionBoard: 3回命中させると撃沈する]", func(t *testing.T) {
+		board := NewExactPredictionBoard()
+		target := &Position{3, 3}
+		assert.NoError(t, board.ApplyTurnLog("p1", newExactTestLog(t, 1, "p1", target, shared.Hit)))
+		assert.NoError(t, board.ApplyTurnLog("p1", newExactTestLog(t, 3, "p1", target, shared.Hit)))
+		assert.NoError(t, board.ApplyTurnLog("p1", newExactTestLog(t, 5, "p1", target, shared.HitAndSunk)))
+		assert.Equal(t, 2024, board.ConfigurationCount())
+		possibility, err := board.Possibility(target)
+		assert.NoError(t, err)
+		assert.InDelta(t, 0, possibility, 1e-9)
+		assert.InDelta(t, 3, sumGrid(board.Grid()), 1e-9)
+	})
+
+	t.Run("[ExactPredictionBoard: 敵の移動で命中した敵艦の位置がずれる]", func(t *testing.T) {
+		board := NewExactPredictionBoard()
+		assert.NoError(t, board.ApplyTurnLog("p1", newExactTestLog(t, 1, "p1", &Position{1, 1}, shared.Hit)))
+		move, err := NewTurnLog("g1", 2, "p2", "", shared.Move, nil, shared.South, 2, shared.AttackReportUnknown, shared.MoveSuccess, shared.ErrorNone, time.Time{})
+		assert.NoError(t, err)
+		assert.NoError(t, board.ApplyTurnLog("p1", move))
+		assert.InDelta(t, 4, sumGrid(board.Grid()), 1e-9)
+		hitCell, err := cellOf(&Position{1, 1})
+		assert.NoError(t, err)
+		movedCell, err := cellOf(&Position{1, 3})
+		assert.NoError(t, err)
+		for key := range board.weights {
+			cells := make([]int, 0, shared.SubmarineCount)
+			for _, ship := range key.fleet() {
+				cells = append(cells, shipCell(ship))
+			}
+			assert.True(t, contains(cells, hitCell) || contains(cells, movedCell))
+		}
+		moved, err := board.Possibility(&Position{1, 3})
+		assert.NoError(t, err)
+		assert.Greater(t, moved, 4.0/25)
+	})
+
+	t.Run("[ExactPredictionBoard: 矛盾する記録は反映しない]", func(t *testing.T) {
+		board := NewExactPredictionBoard()
+		err := board.ApplyTurnLog("p1", newExactTestLog(t, 1, "p1", &Position{3, 3}, shared.HitAndSunk))
+		assert.ErrorIs(t, err, shared.ErrNoConsistentFleet)
+		assert.Equal(t, 12650, board.ConfigurationCount())
+		assert.Equal(t, 0, board.GetCurrentTurn())
+	})
+
+	t.Run("[ExactPredictionBoard: 古いターンの記録]", func(t *testing.T) {
+		board := NewExactPredictionBoard()
+		assert.NoError(t, board.ApplyTurnLog("p1", newExactTestLog(t, 3, "p1", &Position{3, 3}, shared.Miss)))
+		err := board.ApplyTurnLog("p1", newExactTestLog(t, 2, "p1", &Position{1, 1}, shared.Miss))
+		assert.ErrorIs(t, err, shared.ErrInvalidTurn)
+		assert.ErrorIs(t, board.ApplyTurnLog("p1", nil), shared.ErrTurnLogIsNil)
+	})
+}
+
+// 実際に対戦させ, 本当の敵艦隊の配置が常に候補に含まれていることを確認する.
+func TestExactPredictionBoardTracksRealGame(t *testing.T) {
+	for seed := int64(1); seed <= 5; seed++ {
+		random := rand.New(rand.NewSource(seed))
+		game := newStartedTestGame(t, defaultP1Positions, defaultP2Positions)
+		boards := map[shared.PlayerId]*ExactPredictionBoard{
+			"p1": NewExactPredictionBoard(),
+			"p2": NewExactPredictionBoard(),
+		}
+		directions := []shared.Direction{shared.North, shared.East, shared.South, shared.West}
+		for i := 0; i < 300 && !game.IsFinished(); i++ {
+			playerId := game.GetCurrentPlayerId()
+			var command *ActionCommand
+			if random.Intn(3) == 0 {
+				command = newTestMove(t, playerId, directions[random.Intn(len(directions))], random.Intn(shared.MaxDistance)+1)
+			} else {
+				command = newTestAttack(t, playerId, random.Intn(boardSize)+1, random.Intn(boardSize)+1)
+			}
+			_, _ = game.Apply(command)
+			for _, log := range game.PullTurnLogs() {
+				for viewerId, board := range boards {
+					assert.NoError(t, board.ApplyTurnLog(viewerId, log), "seed=%d turn=%d", seed, log.GetTurn())
+				}
+			}
+			for viewerId, board := range boards {
+				enemyId := game.GetOpponentId(viewerId)
+				ships := make(fleet, 0, shared.SubmarineCount)
+				for _, submarine := range game.GetBoard().GetAllySubmarines(enemyId) {
+					if submarine.IsSunk() {
+						continue
+					}
+					cell, err := cellOf(submarine.GetPosition())
+					assert.NoError(t, err)
+					ships = append(ships, packShip(cell, submarine.hp))
+				}
+				assert.Greater(t, board.weights[ships.key()], 0.0, "seed=%d viewer=%s", seed, viewerId)
+				assert.InDelta(t, float64(len(ships)), sumGrid(board.Grid()), 1e-6)
+			}
+		}
+	}
+}
+
+func TestExactPredictionBoardSampleFleet(t *testing.T) {
+	board := NewExactPredictionBoard()
+	assert.NoError(t, board.ApplyTurnLog("p1", newExactTestLog(t, 1, "p1", &Position{3, 3}, shared.Hit)))
+
+	t.Run("[SampleFleet: 矛盾しない配置だけを選ぶ]", func(t *testing.T) {
+		random := rand.New(rand.NewSource(1))
+		for i := 0; i < 100; i++ {
+			submarines, err := board.SampleFleet(random, "p2")
+			assert.NoError(t, err)
+			assert.Len(t, submarines, shared.SubmarineCount)
+			ships := make(fleet, 0, len(submarines))
+			for _, submarine := range submarines {
+				assert.Equal(t, shared.PlayerId("p2"), submarine.GetOwnerId())
+				cell, err := cellOf(submarine.GetPosition())
+				assert.NoError(t, err)
+				ships = append(ships, packShip(cell, submarine.hp))
+			}
+			assert.Greater(t, board.weights[ships.key()], 0.0)
+		}
+	})
+
+	t.Run("[SampleFleet: 同じ乱数なら同じ配置]", func(t *testing.T) {
+		first, err := board.SampleFleet(rand.New(rand.NewSource(7)), "p2")
+		assert.NoError(t, err)
+		second, err := board.SampleFleet(rand.New(rand.NewSource(7)), "p2")
+		assert.NoError(t, err)
+		assert.Equal(t, first, second)
+	})
+}
+
+func TestPossibilityMap(t *testing.T) {
+	testList := []struct {
+		name  string
+		board PossibilityMap
+	}{
+		{"[PossibilityMap: PredictionBoard]", NewPredictionBoard()},
+		{"[PossibilityMap: ExactPredictionBoard]", NewExactPredictionBoard()},
+	}
+	for _, tl := range testList {
+		t.Run(tl.name, func(t *testing.T) {
+			assert.NoError(t, tl.board.ApplyTurnLog("p1", newExactTestLog(t, 1, "p1", &Position{3, 3}, shared.Miss)))
+			assert.Equal(t, 1, tl.board.GetCurrentTurn())
+			grid := tl.board.Grid()
+			assert.InDelta(t, 0, grid[1][1], 1e-9)
+			grid[1][1] = 1
+			possibility, err := tl.board.Possibility(&Position{2, 2})
+			assert.NoError(t, err)
+			assert.InDelta(t, 0, possibility, 1e-9)
+		})
+	}
+}
@@ -0,0 +1,521 @@
+package domain
+
+import (
+	shared "backend/domain/shared"
+	"strings"
+	"time"
+)
+
+type Game struct {
+	id                shared.GameId
+	status            shared.GameStatus
+	turn              int
+	playerAId         shared.PlayerId
+	playerBId         shared.PlayerId
+	playerA           *Player
+	playerB           *Player
+	currentPlayerId   shared.PlayerId
+	winnerId          shared.PlayerId
+	finishReason      shared.FinishReason
+	board             *Board
+	clock             Clock
+	matchDuration     time.Duration
+	turnTimeLimit     time.Duration
+	turnTimeoutPolicy shared.TurnTimeoutPolicy
+	cpuProfile        *CpuProfile
+	debug             bool
+	startedAt         time.Time
+	turnStartedAt     time.Time
+	createdAt         time.Time
+	updatedAt         time.Time
+	version           int
+	pendingLogs       []*TurnLog
+}
+
+func NewGame(id shared.GameId, playerAId shared.PlayerId, playerBId shared.PlayerId, board *Board) (*Game, error) {
+	if strings.TrimSpace(id.String()) == "" {
+		return nil, shared.ErrInvalidGameId
+	}
+	if strings.TrimSpace(playerAId.String()) == "" || strings.TrimSpace(playerBId.String()) == "" || playerAId == playerBId {
+		return nil, shared.ErrInvalidPlayerID
+	}
+	if board == nil {
+		return nil, shared.ErrBoardIsNil
+	}
+	playerA, err := newFleetPlayer(playerAId, board)
+	if err != nil {
+		return nil, err
+	}
+	playerB, err := newFleetPlayer(playerBId, board)
+	if err != nil {
+		return nil, err
+	}
+	clock := SystemClock{}
+	now := clock.Now()
+	return &Game{
+		id:            id,
+		status:        shared.Waiting,
+		turn:          0,
+		playerAId:     playerAId,
+		playerBId:     playerBId,
+		playerA:       playerA,
+		playerB:       playerB,
+		finishReason:  shared.FinishReasonUnknown,
+		board:         board,
+		clock:         clock,
+		matchDuration: shared.MatchDuration,
+		createdAt:     now,
+		updatedAt:     now,
+	}, nil
+}
+
+// SetClock はゲームが参照する時計を差し替える.
+func (game *Game) SetClock(clock Clock) error {
+	if game == nil {
+		return shared.ErrGameIsNil
+	}
+	if clock == nil {
+		return shared.ErrClockIsNil
+	}
+	game.clock = clock
+	return nil
+}
+
+// SetMatchDuration は試合時間を変更する. 開始後は変更できない.
+func (game *Game) SetMatchDuration(duration time.Duration) error {
+	if game == nil {
+		return shared.ErrGameIsNil
+	}
+	if game.status != shared.Waiting {
+		return shared.ErrGameAlreadyStarted
+	}
+	if duration <= 0 {
+		return shared.ErrInvalidDuration
+	}
+	game.matchDuration = duration
+	return nil
+}
+
+// SetTurnTimeLimit は1手あたりの制限時間と, 超過した場合の扱いを設定する. limit が0なら制限なし.
+func (game *Game) SetTurnTimeLimit(limit time.Duration, policy shared.TurnTimeoutPolicy) error {
+	if game == nil {
+		return shared.ErrGameIsNil
+	}
+	if game.status != shared.Waiting {
+		return shared.ErrGameAlreadyStarted
+	}
+	if limit < 0 || (policy != shared.TurnTimeoutPass && policy != shared.TurnTimeoutForfeit) {
+		return shared.ErrInvalidDuration
+	}
+	game.turnTimeLimit = limit
+	game.turnTimeoutPolicy = policy
+	return nil
+}
+
+// SetCpuProfile はプレイヤーBを CPU に任せる. 開始後は変更できない.
+func (game *Game) SetCpuProfile(profile *CpuProfile) error {
+	if game == nil {
+		return shared.ErrGameIsNil
+	}
+	if game.status != shared.Waiting {
+		return shared.ErrGameAlreadyStarted
+	}
+	if profile == nil {
+		return shared.ErrCpuProfileIsNil
+	}
+	copied := *profile
+	game.cpuProfile = &copied
+	return nil
+}
+
+// SetDebug は CPU が行動を選んだ理由を応答に含めるかどうかを切り替える. 対戦中でも変更できる.
+func (game *Game) SetDebug(debug bool) error {
+	if game == nil {
+		return shared.ErrGameIsNil
+	}
+	game.debug = debug
+	return nil
+}
+
+// SetVersion は保存先が記録している対戦のバージョンを設定する.
+// GameRepository が読み込んだときと保存に成功したときに呼び, 次の保存でほかの更新と競合していないかの確認に使う.
+func (game *Game) SetVersion(version int) error {
+	if game == nil {
+		return shared.ErrGameIsNil
+	}
+	if version < 0 {
+		return shared.ErrInvalidGameVersion
+	}
+	game.version = version
+	return nil
+}
+
+// Clone は盤面と CPU の設定ごと対戦を複製する. 複製した対戦を変更しても元の対戦には影響しない.
+// 時計は共有し, まだ PullTurnLogs で取り出していない TurnLog は複製しない.
+func (game *Game) Clone() *Game {
+	if game == nil {
+		return nil
+	}
+	clone := *game
+	clone.board = game.board.Clone()
+	clone.playerA = game.playerA.withBoard(clone.board)
+	clone.playerB = game.playerB.withBoard(clone.board)
+	clone.cpuProfile = game.GetCpuProfile()
+	clone.pendingLogs = nil
+	return &clone
+}
+
+// Start は両プレイヤーの配置が完了していることを確認し, プレイヤーAの手番から開始する.
+func (game *Game) Start() error {
+	if game == nil {
+		return shared.ErrGameIsNil
+	}
+	if game.status != shared.Waiting {
+		return shared.ErrGameAlreadyStarted
+	}
+	for _, playerId := range []shared.PlayerId{game.playerAId, game.playerBId} {
+		if len(game.board.GetAllySubmarines(playerId)) != shared.SubmarineCount {
+			return shared.ErrFleetIncomplete
+		}
+	}
+	now := game.clock.Now()
+	game.status = shared.InProgress
+	game.turn = 1
+	game.currentPlayerId = game.playerAId
+	game.startedAt = now
+	game.turnStartedAt = now
+	game.updatedAt = now
+	return nil
+}
+
+// Tick は現在時刻を評価する.
+// 試合時間を過ぎていれば残存HPで勝敗を決め, 手番の制限時間を過ぎていれば設定に従いパスか反則負けにする.
+func (game *Game) Tick() error {
+	if game == nil {
+		return shared.ErrGameIsNil
+	}
+	if game.status != shared.InProgress {
+		return nil
+	}
+	now := game.clock.Now()
+	deadline := game.startedAt.Add(game.matchDuration)
+	if game.turnTimeLimit > 0 {
+		for game.status == shared.InProgress && !now.Before(game.turnStartedAt.Add(game.turnTimeLimit)) {
+			expiredAt := game.turnStartedAt.Add(game.turnTimeLimit)
+			if !expiredAt.Before(deadline) {
+				break
+			}
+			switch game.turnTimeoutPolicy {
+			case shared.TurnTimeoutForfeit:
+				game.finish(game.GetOpponentId(game.currentPlayerId), shared.TurnTimeout, expiredAt)
+			default:
+				game.passTurn(expiredAt)
+			}
+		}
+	}
+	if game.status == shared.InProgress && !now.Before(deadline) {
+		game.finishByRemainingHp(deadline)
+	}
+	return nil
+}
+
+// Apply は手番プレイヤーの行動を盤面に反映する.
+// 行動が不正な場合は盤面を変更せず, errorCode を設定した TurnResult とエラーを返す.
+func (game *Game) Apply(command *ActionCommand) (*TurnResult, error) {
+	if game == nil {
+		return nil, shared.ErrGameIsNil
+	}
+	if command == nil {
+		return nil, shared.ErrActionCommandIsNil
+	}
+	if err := game.Tick(); err != nil {
+		return nil, err
+	}
+	if game.status != shared.InProgress {
+		return game.reject(command, shared.ErrGameNotInProgress)
+	}
+	if command.playerId != game.currentPlayerId {
+		return game.reject(command, shared.ErrInvalidTurn)
+	}
+
+	var result *TurnResult
+	var err error
+	switch command.actionType {
+	case shared.Attack:
+		result, err = game.applyAttack(command)
+	case shared.Move:
+		result, err = game.applyMove(command)
+	default:
+		err = shared.ErrInvalidActionType
+	}
+	if err != nil {
+		return game.reject(command, err)
+	}
+
+	now := game.clock.Now()
+	actedTurn := game.turn
+	opponentId := game.GetOpponentId(command.playerId)
+	if game.GetPlayer(opponentId).IsFleetDestroyed() {
+		game.turn++
+		game.finish(command.playerId, shared.FleetDestroyed, now)
+	} else {
+		game.passTurn(now)
+	}
+	result.errorCode = shared.ErrorNone
+	result.nextPlayerId = game.currentPlayerId
+	game.record(actedTurn, command, result, now)
+	return result, nil
+}
+
+// PullTurnLogs は前回の呼び出し以降に Apply が記録した TurnLog を古い順に返し, 保持していた分を破棄する.
+func (game *Game) PullTurnLogs() []*TurnLog {
+	if game == nil {
+		return nil
+	}
+	logs := game.pendingLogs
+	game.pendingLogs = nil
+	return logs
+}
+
+// PendingTurnLogs は PullTurnLogs でまだ取り出していない TurnLog を古い順に返す. 保持している分は破棄しない.
+func (game *Game) PendingTurnLogs() []*TurnLog {
+	if game == nil {
+		return nil
+	}
+	return append([]*TurnLog{}, game.pendingLogs...)
+}
+
+func (game *Game) IsFinished() bool {
+	if game == nil {
+		return false
+	}
+	return game.status == shared.Finished
+}
+
+func (game *Game) applyAttack(command *ActionCommand) (*TurnResult, error) {
+	shooter, err := command.ResolveSubmarine(game.board)
+	if err != nil {
+		return nil, err
+	}
+	if shooter != nil {
+		inRange, err := isWithinReach(shooter, command.target)
+		if err != nil {
+			return nil, err
+		}
+		if !inRange {
+			return nil, shared.ErrTargetOutOfRange
+		}
+	}
+	outcome, err := ResolveAttack(game.board, command.playerId, command.target)
+	if err != nil {
+		return nil, err
+	}
+	return &TurnResult{
+		AttackReport: outcome.GetReportType(),
+		MoveReport:   shared.MoveReportUnknown,
+		HitCount:     len(outcome.GetHitPositions()),
+		sunkCount:    len(outcome.GetSunkSubmarines()),
+	}, nil
+}
+
+func (game *Game) applyMove(command *ActionCommand) (*TurnResult, error) {
+	mover, err := command.ResolveSubmarine(game.board)
+	if err != nil {
+		return nil, err
+	}
+	var outcome *MoveOutcome
+	if mover != nil {
+		outcome, err = ResolveMoveOf(game.board, mover, command.direction, command.distance)
+	} else {
+		outcome, err = ResolveMove(game.board, command.playerId, command.direction, command.distance)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &TurnResult{
+		AttackReport: shared.AttackReportUnknown,
+		MoveReport:   outcome.GetReportType(),
+		moverId:      outcome.GetSubmarine().GetId(),
+	}, nil
+}
+
+// passTurn は手番を相手に渡す.
+func (game *Game) passTurn(at time.Time) {
+	game.turn++
+	game.currentPlayerId = game.GetOpponentId(game.currentPlayerId)
+	game.turnStartedAt = at
+	game.updatedAt = at
+}
+
+// finishByRemainingHp は残存HPの多い方を勝者として終了する. 同じ場合は引き分けとする.
+func (game *Game) finishByRemainingHp(at time.Time) {
+	hpA := game.playerA.RemainingHp()
+	hpB := game.playerB.RemainingHp()
+	switch {
+	case hpA > hpB:
+		game.finish(game.playerAId, shared.TimeUp, at)
+	case hpB > hpA:
+		game.finish(game.playerBId, shared.TimeUp, at)
+	default:
+		game.finish("", shared.TimeUp, at)
+	}
+}
+
+func (game *Game) finish(winnerId shared.PlayerId, reason shared.FinishReason, at time.Time) {
+	game.status = shared.Finished
+	game.winnerId = winnerId
+	game.finishReason = reason
+	game.currentPlayerId = ""
+	game.updatedAt = at
+}
+
+func (game *Game) reject(command *ActionCommand, err error) (*TurnResult, error) {
+	result := &TurnResult{
+		AttackReport: shared.AttackReportUnknown,
+		MoveReport:   shared.MoveReportUnknown,
+		errorCode:    shared.ToErrorCode(err),
+		nextPlayerId: game.currentPlayerId,
+	}
+	switch command.actionType {
+	case shared.Attack:
+		result.AttackReport = shared.InvalidAttack
+	case shared.Move:
+		result.MoveReport = shared.MoveBlocked
+	}
+	game.record(game.turn, command, result, game.clock.Now())
+	return result, err
+}
+
+func (game *Game) record(turn int, command *ActionCommand, result *TurnResult, at time.Time) {
+	log := newTurnLogFromResult(game.id, turn, command, result, at)
+	result.turnLog = log
+	game.pendingLogs = append(game.pendingLogs, log)
+}
+
+func (game *Game) GetId() shared.GameId {
+	return game.id
+}
+
+func (game *Game) GetStatus() shared.GameStatus {
+	return game.status
+}
+
+func (game *Game) GetTurn() int {
+	return game.turn
+}
+
+func (game *Game) GetPlayerAId() shared.PlayerId {
+	return game.playerAId
+}
+
+func (game *Game) GetPlayerBId() shared.PlayerId {
+	return game.playerBId
+}
+
+func (game *Game) GetCurrentPlayerId() shared.PlayerId {
+	return game.currentPlayerId
+}
+
+func (game *Game) GetWinnerId() shared.PlayerId {
+	return game.winnerId
+}
+
+// IsDraw は時間切れで残存HPが同じだった場合に true を返す.
+func (game *Game) IsDraw() bool {
+	return game.IsFinished() && game.winnerId == ""
+}
+
+func (game *Game) GetFinishReason() shared.FinishReason {
+	return game.finishReason
+}
+
+func (game *Game) GetMatchDuration() time.Duration {
+	return game.matchDuration
+}
+
+func (game *Game) GetTurnTimeLimit() time.Duration {
+	return game.turnTimeLimit
+}
+
+func (game *Game) GetTurnTimeoutPolicy() shared.TurnTimeoutPolicy {
+	return game.turnTimeoutPolicy
+}
+
+// GetCpuProfile はプレイヤーBを担当する CPU の設定の複製を返す. 人間同士の対戦なら nil を返す.
+func (game *Game) GetCpuProfile() *CpuProfile {
+	if game.cpuProfile == nil {
+		return nil
+	}
+	copied := *game.cpuProfile
+	return &copied
+}
+
+func (game *Game) IsDebug() bool {
+	return game.debug
+}
+
+func (game *Game) GetStartedAt() time.Time {
+	return game.startedAt
+}
+
+func (game *Game) GetTurnStartedAt() time.Time {
+	return game.turnStartedAt
+}
+
+// GetRemainingTime は試合終了までの残り時間を返す. 開始前は試合時間そのもの, 終了後は0を返す.
+func (game *Game) GetRemainingTime() time.Duration {
+	switch game.status {
+	case shared.Waiting:
+		return game.matchDuration
+	case shared.InProgress:
+		remaining := game.startedAt.Add(game.matchDuration).Sub(game.clock.Now())
+		if remaining < 0 {
+			return 0
+		}
+		return remaining
+	default:
+		return 0
+	}
+}
+
+// GetOpponentId は playerId の対戦相手のidを返す. 参加していない場合は空文字を返す.
+func (game *Game) GetOpponentId(playerId shared.PlayerId) shared.PlayerId {
+	switch playerId {
+	case game.playerAId:
+		return game.playerBId
+	case game.playerBId:
+		return game.playerAId
+	default:
+		return ""
+	}
+}
+
+// GetPlayer は playerId のプレイヤーを返す. 対戦のプレイヤーでなければ nil を返す.
+// プレイヤーは対戦の盤面に紐づいており, RemainingHp などは盤面上の艦隊から求める.
+func (game *Game) GetPlayer(playerId shared.PlayerId) *Player {
+	switch playerId {
+	case game.playerAId:
+		return game.playerA
+	case game.playerBId:
+		return game.playerB
+	default:
+		return nil
+	}
+}
+
+func (game *Game) GetBoard() *Board {
+	return game.board
+}
+
+func (game *Game) GetCreatedAt() time.Time {
+	return game.createdAt
+}
+
+func (game *Game) GetUpdatedAt() time.Time {
+	return game.updatedAt
+}
+
+// GetVersion は対戦を最後に読み込んだか保存したときのバージョンを返す. 一度も保存していなければ0.
+func (game *Game) GetVersion() int {
+	return game.version
+}
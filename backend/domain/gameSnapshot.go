@@ -0,0 +1,137 @@
+package domain
+
+import (
+	shared "backend/domain/shared"
+	"time"
+)
+
+// GameSnapshot は対戦を保存先に書き出し, 読み込んで復元するための値.
+// 保存先ごとの表現への変換は infrastructure で行い, RestoreGame で検証してから Game に戻す.
+type GameSnapshot struct {
+	Id                shared.GameId
+	Status            shared.GameStatus
+	Turn              int
+	PlayerAId         shared.PlayerId
+	PlayerBId         shared.PlayerId
+	CurrentPlayerId   shared.PlayerId
+	WinnerId          shared.PlayerId
+	FinishReason      shared.FinishReason
+	MatchDuration     time.Duration
+	TurnTimeLimit     time.Duration
+	TurnTimeoutPolicy shared.TurnTimeoutPolicy
+	CpuProfile        *CpuProfile
+	Debug             bool
+	StartedAt         time.Time
+	TurnStartedAt     time.Time
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+	Version           int
+	Submarines        []*Submarine
+}
+
+// Snapshot は対戦の状態を書き出す. 潜水艦と CPU の設定は複製し, 取り出していない TurnLog は含めない.
+func (game *Game) Snapshot() *GameSnapshot {
+	if game == nil {
+		return nil
+	}
+	submarines := make([]*Submarine, 0, 2*shared.SubmarineCount)
+	for _, playerId := range []shared.PlayerId{game.playerAId, game.playerBId} {
+		for _, submarine := range game.board.GetAllySubmarines(playerId) {
+			copied := *submarine
+			submarines = append(submarines, &copied)
+		}
+	}
+	return &GameSnapshot{
+		Id:                game.id,
+		Status:            game.status,
+		Turn:              game.turn,
+		PlayerAId:         game.playerAId,
+		PlayerBId:         game.playerBId,
+		CurrentPlayerId:   game.currentPlayerId,
+		WinnerId:          game.winnerId,
+		FinishReason:      game.finishReason,
+		MatchDuration:     game.matchDuration,
+		TurnTimeLimit:     game.turnTimeLimit,
+		TurnTimeoutPolicy: game.turnTimeoutPolicy,
+		CpuProfile:        game.GetCpuProfile(),
+		Debug:             game.debug,
+		StartedAt:         game.startedAt,
+		TurnStartedAt:     game.turnStartedAt,
+		CreatedAt:         game.createdAt,
+		UpdatedAt:         game.updatedAt,
+		Version:           game.version,
+		Submarines:        submarines,
+	}
+}
+
+// RestoreGame は書き出した状態から対戦を復元する. 時計はシステムの時計になる.
+// 撃沈済みの潜水艦と同じマスに残った潜水艦もそのまま戻すため, 盤面への配置の制約は確認しない.
+func RestoreGame(snapshot *GameSnapshot) (*Game, error) {
+	if snapshot == nil {
+		return nil, shared.ErrGameIsNil
+	}
+	board := NewBoard()
+	counts := make(map[shared.PlayerId]int, 2)
+	for _, submarine := range snapshot.Submarines {
+		if submarine == nil {
+			return nil, shared.ErrSubmarineIsNil
+		}
+		if submarine.ownerId != snapshot.PlayerAId && submarine.ownerId != snapshot.PlayerBId {
+			return nil, shared.ErrInvalidPlayerID
+		}
+		if _, exists := board.submarines[submarine.id]; exists {
+			return nil, shared.ErrDuplicateSubmarineId
+		}
+		counts[submarine.ownerId]++
+		if counts[submarine.ownerId] > shared.SubmarineCount {
+			return nil, shared.ErrSubmarineLimitExceeded
+		}
+		copied := *submarine
+		board.submarines[copied.id] = &copied
+	}
+	game, err := NewGame(snapshot.Id, snapshot.PlayerAId, snapshot.PlayerBId, board)
+	if err != nil {
+		return nil, err
+	}
+	if snapshot.MatchDuration <= 0 || snapshot.TurnTimeLimit < 0 {
+		return nil, shared.ErrInvalidDuration
+	}
+	if snapshot.Turn < 0 {
+		return nil, shared.ErrInvalidTurn
+	}
+	if snapshot.Version < 0 {
+		return nil, shared.ErrInvalidGameVersion
+	}
+	switch snapshot.Status {
+	case shared.Waiting:
+	case shared.InProgress:
+		if game.GetOpponentId(snapshot.CurrentPlayerId) == "" {
+			return nil, shared.ErrInvalidPlayerID
+		}
+	case shared.Finished:
+		if snapshot.WinnerId != "" && game.GetOpponentId(snapshot.WinnerId) == "" {
+			return nil, shared.ErrInvalidPlayerID
+		}
+	default:
+		return nil, shared.ErrInvalidGameStatus
+	}
+	game.status = snapshot.Status
+	game.turn = snapshot.Turn
+	game.currentPlayerId = snapshot.CurrentPlayerId
+	game.winnerId = snapshot.WinnerId
+	game.finishReason = snapshot.FinishReason
+	game.matchDuration = snapshot.MatchDuration
+	game.turnTimeLimit = snapshot.TurnTimeLimit
+	game.turnTimeoutPolicy = snapshot.TurnTimeoutPolicy
+	if snapshot.CpuProfile != nil {
+		copied := *snapshot.CpuProfile
+		game.cpuProfile = &copied
+	}
+	game.debug = snapshot.Debug
+	game.startedAt = snapshot.StartedAt
+	game.turnStartedAt = snapshot.TurnStartedAt
+	game.createdAt = snapshot.CreatedAt
+	game.updatedAt = snapshot.UpdatedAt
+	game.version = snapshot.Version
+	return game, nil
+}
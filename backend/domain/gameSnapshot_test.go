@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"testing"
+
+	shared "backend/domain/shared"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGameSnapshotRestore(t *testing.T) {
+	game := newStartedTestGame(t, defaultP1Positions, defaultP2Positions)
+	profile, err := NewCpuProfile(shared.CpuHeuristic)
+	assert.NoError(t, err)
+	game.cpuProfile = profile
+	assert.NoError(t, game.SetDebug(true))
+	assert.NoError(t, game.SetVersion(7))
+	for i := 0; i < 2; i++ {
+		_, err = game.Apply(newTestAttack(t, "p1", 4, 4))
+		assert.NoError(t, err)
+		_, err = game.Apply(newTestAttack(t, "p2", 3, 3))
+		assert.NoError(t, err)
+	}
+
+	snapshot := game.Snapshot()
+	assert.Len(t, snapshot.Submarines, 2*shared.SubmarineCount)
+	restored, err := RestoreGame(snapshot)
+	assert.NoError(t, err)
+	assert.Empty(t, restored.PullTurnLogs())
+	assert.Equal(t, game.GetTurn(), restored.GetTurn())
+	assert.Equal(t, game.GetCurrentPlayerId(), restored.GetCurrentPlayerId())
+	assert.Equal(t, game.GetCpuProfile(), restored.GetCpuProfile())
+	assert.True(t, restored.IsDebug())
+	assert.Equal(t, 7, restored.GetVersion())
+	assert.True(t, game.GetStartedAt().Equal(restored.GetStartedAt()))
+	for _, playerId := range []shared.PlayerId{"p1", "p2"} {
+		assert.Equal(t, game.GetBoard().GetAllySubmarines(playerId), restored.GetBoard().GetAllySubmarines(playerId))
+	}
+
+	snapshot.Submarines[0] = nil
+	assert.NotNil(t, restored.GetBoard().GetAllySubmarines("p1")[0])
+}
+
+// 撃沈された潜水艦と同じマスに残った敵の潜水艦も, そのまま復元できる.
+func TestRestoreGameKeepsSharedCells(t *testing.T) {
+	game := newStartedTestGame(t, defaultP1Positions, defaultP2Positions)
+	snapshot := game.Snapshot()
+	for _, submarine := range snapshot.Submarines {
+		if submarine.GetId() == "p1-sub-4" {
+			submarine.hp = 0
+			submarine.position = &Position{4, 4}
+		}
+	}
+	restored, err := RestoreGame(snapshot)
+	assert.NoError(t, err)
+	assert.Equal(t, 2*shared.SubmarineCount, len(restored.GetBoard().GetAllySubmarines("p1"))+len(restored.GetBoard().GetAllySubmarines("p2")))
+}
+
+func TestRestoreGameFail(t *testing.T) {
+	testList := []struct {
+		name        string
+		modify      func(snapshot *GameSnapshot)
+		expectedErr error
+	}{
+		{"[RestoreGame: 不明な状態]", func(snapshot *GameSnapshot) { snapshot.Status = 9 }, shared.ErrInvalidGameStatus},
+		{"[RestoreGame: 手番のプレイヤーが参加していない]", func(snapshot *GameSnapshot) { snapshot.CurrentPlayerId = "p3" }, shared.ErrInvalidPlayerID},
+		{"[RestoreGame: 参加していないプレイヤーの潜水艦]", func(snapshot *GameSnapshot) { snapshot.Submarines[0].ownerId = "p3" }, shared.ErrInvalidPlayerID},
+		{"[RestoreGame: 同じidの潜水艦]", func(snapshot *GameSnapshot) { snapshot.Submarines[1].id = snapshot.Submarines[0].id }, shared.ErrDuplicateSubmarineId},
+		{"[RestoreGame: 試合時間が0]", func(snapshot *GameSnapshot) { snapshot.MatchDuration = 0 }, shared.ErrInvalidDuration},
+		{"[RestoreGame: 負のターン]", func(snapshot *GameSnapshot) { snapshot.Turn = -1 }, shared.ErrInvalidTurn},
+		{"[RestoreGame: 負のバージョン]", func(snapshot *GameSnapshot) { snapshot.Version = -1 }, shared.ErrInvalidGameVersion},
+		{"[RestoreGame: idが空]", func(snapshot *GameSnapshot) { snapshot.Id = "" }, shared.ErrInvalidGameId},
+	}
+	for _, tl := range testList {
+		t.Run(tl.name, func(t *testing.T) {
+			snapshot := newStartedTestGame(t, defaultP1Positions, defaultP2Positions).Snapshot()
+			tl.modify(snapshot)
+			_, err := RestoreGame(snapshot)
+			assert.ErrorIs(t, err, tl.expectedErr)
+		})
+	}
+	_, err := RestoreGame(nil)
+	assert.ErrorIs(t, err, shared.ErrGameIsNil)
+}
@@ -0,0 +1,204 @@
+package domain
+
+import (
+	shared "backend/domain/shared"
+	"context"
+	"fmt"
+)
+
+// GameView は1人のプレイヤーが正当に知ることのできる情報だけを集めた対戦の状態.
+// 盤面には自軍の潜水艦と, 自分の攻撃で撃沈を確認した敵艦だけが含まれる.
+// 行動記録からは相手の拒否された行動と, 相手がどの潜水艦を動かしたかを取り除く.
+type GameView struct {
+	gameId          shared.GameId
+	playerAId       shared.PlayerId
+	playerBId       shared.PlayerId
+	viewerId        shared.PlayerId
+	opponentId      shared.PlayerId
+	status          shared.GameStatus
+	turn            int
+	currentPlayerId shared.PlayerId
+	board           *Board
+	logs            []*TurnLog
+}
+
+// NewGameView は game を viewerId から見た状態に変換する. logs は game のこれまでの行動記録を古い順に渡す.
+func NewGameView(game *Game, viewerId shared.PlayerId, logs []*TurnLog) (*GameView, error) {
+	if game == nil {
+		return nil, shared.ErrGameIsNil
+	}
+	if viewerId == "" || (viewerId != game.playerAId && viewerId != game.playerBId) {
+		return nil, shared.ErrInvalidPlayerID
+	}
+	view := &GameView{
+		gameId:          game.id,
+		playerAId:       game.playerAId,
+		playerBId:       game.playerBId,
+		viewerId:        viewerId,
+		opponentId:      game.GetOpponentId(viewerId),
+		status:          game.status,
+		turn:            game.turn,
+		currentPlayerId: game.currentPlayerId,
+		board:           NewBoard(),
+		logs:            make([]*TurnLog, 0, len(logs)),
+	}
+	for _, submarine := range game.board.GetAllySubmarines(viewerId) {
+		copied := *submarine
+		if err := view.board.AddSubmarine(&copied); err != nil {
+			return nil, err
+		}
+	}
+	sunkCount := 0
+	for _, log := range logs {
+		if log == nil {
+			return nil, shared.ErrTurnLogIsNil
+		}
+		if log.gameId != game.id {
+			return nil, shared.ErrInvalidGameId
+		}
+		if log.playerId != viewerId {
+			if log.IsRejected() {
+				continue
+			}
+			hidden := *log
+			hidden.submarineId = ""
+			log = &hidden
+		} else if log.actionType == shared.Attack && log.attackReport == shared.HitAndSunk {
+			sunkCount++
+			id := shared.SubmarineId(fmt.Sprintf("%s-sunk-%d", view.opponentId, sunkCount))
+			sunk, err := NewSubmarine(id, view.opponentId, log.target, 0)
+			if err != nil {
+				return nil, err
+			}
+			if err := view.board.AddSubmarine(sunk); err != nil {
+				return nil, err
+			}
+		}
+		view.logs = append(view.logs, log)
+	}
+	return view, nil
+}
+
+// LegalAttackTargets は攻撃できるマスを上の行から順に返す.
+func (view *GameView) LegalAttackTargets() []*Position {
+	return view.board.LegalAttackTargets(view.viewerId)
+}
+
+// LegalMoves は移動できる潜水艦と方向, 距離の組み合わせを潜水艦のid順に返す. 盤面は変更しない.
+func (view *GameView) LegalMoves() []*MoveOutcome {
+	return view.board.LegalMoves(view.viewerId)
+}
+
+// Prediction は行動記録から, 自分から見た敵艦の存在確率マップを作る.
+func (view *GameView) Prediction(discountRate float64) (*PredictionBoard, error) {
+	return view.replay(view.viewerId, discountRate)
+}
+
+// Threat は自軍・敵軍のマップを入れ替え, 相手から見た自軍の潜水艦の存在確率マップを作る.
+func (view *GameView) Threat(discountRate float64) (*PredictionBoard, error) {
+	return view.replay(view.opponentId, discountRate)
+}
+
+// ExactPrediction は行動記録と矛盾しない敵艦隊の配置を全て列挙する.
+// 行動記録を1件反映するたびに ctx を確かめ, キャンセルされていればエラーを返す.
+func (view *GameView) ExactPrediction(ctx context.Context) (*ExactPredictionBoard, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	board := NewExactPredictionBoard()
+	for _, log := range view.logs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := board.ApplyTurnLog(view.viewerId, log); err != nil {
+			return nil, err
+		}
+	}
+	return board, nil
+}
+
+// Determinize は見えていない敵艦を enemies の配置と仮定した対戦を作る.
+// 作った対戦は時間が進まず, 元の対戦とは独立に Apply で進めることができる.
+func (view *GameView) Determinize(enemies []*Submarine) (*Game, error) {
+	board := view.board.Clone()
+	for _, enemy := range enemies {
+		if enemy == nil {
+			return nil, shared.ErrSubmarineIsNil
+		}
+		if enemy.ownerId != view.opponentId {
+			return nil, shared.ErrInvalidPlayerID
+		}
+		copied := *enemy
+		if err := board.AddSubmarine(&copied); err != nil {
+			return nil, err
+		}
+	}
+	game, err := NewGame(view.gameId, view.playerAId, view.playerBId, board)
+	if err != nil {
+		return nil, err
+	}
+	clock := fixedClock{at: game.createdAt}
+	game.clock = clock
+	game.status = view.status
+	game.turn = view.turn
+	game.currentPlayerId = view.currentPlayerId
+	game.startedAt = clock.at
+	game.turnStartedAt = clock.at
+	return game, nil
+}
+
+func (view *GameView) replay(viewerId shared.PlayerId, discountRate float64) (*PredictionBoard, error) {
+	board, err := NewPredictionBoardWithDiscountRate(discountRate)
+	if err != nil {
+		return nil, err
+	}
+	for _, log := range view.logs {
+		if err := board.ApplyTurnLog(viewerId, log); err != nil {
+			return nil, err
+		}
+	}
+	if err := board.AdvanceTurn(view.turn); err != nil {
+		return nil, err
+	}
+	return board, nil
+}
+
+func (view *GameView) GetGameId() shared.GameId {
+	return view.gameId
+}
+
+func (view *GameView) GetViewerId() shared.PlayerId {
+	return view.viewerId
+}
+
+func (view *GameView) GetOpponentId() shared.PlayerId {
+	return view.opponentId
+}
+
+func (view *GameView) GetStatus() shared.GameStatus {
+	return view.status
+}
+
+func (view *GameView) GetTurn() int {
+	return view.turn
+}
+
+func (view *GameView) GetCurrentPlayerId() shared.PlayerId {
+	return view.currentPlayerId
+}
+
+// GetBoard は盤面の複製を返す.
+func (view *GameView) GetBoard() *Board {
+	return view.board.Clone()
+}
+
+// GetAllySubmarines は自軍の潜水艦の複製をid順に返す.
+func (view *GameView) GetAllySubmarines() []*Submarine {
+	return view.board.Clone().GetAllySubmarines(view.viewerId)
+}
+
+func (view *GameView) GetLogs() []*TurnLog {
+	logs := make([]*TurnLog, len(view.logs))
+	copy(logs, view.logs)
+	return logs
+}
@@ -0,0 +1,176 @@
+package domain
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	shared "backend/domain/shared"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewGameView(t *testing.T) {
+	game := newStartedTestGame(t, defaultP1Positions, []Position{{4, 4}, {1, 1}, {5, 1}, {1, 5}})
+	commands := []*ActionCommand{
+		newTestAttack(t, "p1", 1, 1),
+		newTestAttack(t, "p2", 5, 5),
+		newTestAttack(t, "p1", 1, 1),
+		newTestMove(t, "p2", shared.North, 1),
+		newTestAttack(t, "p1", 1, 1),
+	}
+	for _, command := range commands {
+		_, err := game.Apply(command)
+		assert.NoError(t, err)
+	}
+	_, err := game.Apply(newTestAttack(t, "p1", 2, 2))
+	assert.ErrorIs(t, err, shared.ErrInvalidTurn)
+	mover, err := NewActionCommandWithSubmarine("p2", "p2-sub-1", shared.Move, nil, shared.South, 1)
+	assert.NoError(t, err)
+	_, err = game.Apply(mover)
+	assert.NoError(t, err)
+	logs := game.PullTurnLogs()
+
+	t.Run("[GameView: 盤面は自軍と撃沈を確認した敵艦だけ]", func(t *testing.T) {
+		view, err := NewGameView(game, "p1", logs)
+		assert.NoError(t, err)
+		board := view.GetBoard()
+		assert.Len(t, board.GetAllySubmarines("p1"), shared.SubmarineCount)
+		opponents := board.GetAllySubmarines("p2")
+		if assert.Len(t, opponents, 1) {
+			assert.True(t, opponents[0].IsSunk())
+			assert.Equal(t, &Position{1, 1}, opponents[0].GetPosition())
+		}
+	})
+
+	t.Run("[GameView: 相手の拒否された行動と潜水艦のidは見えない]", func(t *testing.T) {
+		view, err := NewGameView(game, "p2", logs)
+		assert.NoError(t, err)
+		visible := view.GetLogs()
+		assert.Len(t, visible, len(logs)-1)
+		for _, log := range visible {
+			if log.GetPlayerId() == "p1" {
+				assert.False(t, log.IsRejected())
+			}
+		}
+		assert.Equal(t, shared.SubmarineId("p2-sub-1"), visible[len(visible)-1].GetSubmarineId())
+
+		view, err = NewGameView(game, "p1", logs)
+		assert.NoError(t, err)
+		visible = view.GetLogs()
+		assert.Len(t, visible, len(logs))
+		assert.Equal(t, shared.SubmarineId(""), visible[len(visible)-1].GetSubmarineId())
+		assert.Equal(t, shared.SubmarineId("p2-sub-1"), logs[len(logs)-1].GetSubmarineId())
+	})
+
+	t.Run("[GameView: 不正な引数]", func(t *testing.T) {
+		_, err := NewGameView(nil, "p1", logs)
+		assert.ErrorIs(t, err, shared.ErrGameIsNil)
+		_, err = NewGameView(game, "p3", logs)
+		assert.ErrorIs(t, err, shared.ErrInvalidPlayerID)
+		_, err = NewGameView(game, "p1", []*TurnLog{nil})
+		assert.ErrorIs(t, err, shared.ErrTurnLogIsNil)
+	})
+}
+
+func TestGameViewLegalActions(t *testing.T) {
+	game := newStartedTestGame(t, []Position{{1, 1}, {2, 1}, {1, 2}, {2, 2}}, defaultP2Positions)
+	view, err := NewGameView(game, "p1", nil)
+	assert.NoError(t, err)
+
+	t.Run("[LegalAttackTargets: 味方のいない周囲8マス]", func(t *testing.T) {
+		expected := []*Position{{3, 1}, {3, 2}, {1, 3}, {2, 3}, {3, 3}}
+		assert.Equal(t, expected, view.LegalAttackTargets())
+	})
+
+	t.Run("[LegalMoves: 味方と盤外を避けた移動]", func(t *testing.T) {
+		moves := view.LegalMoves()
+		assert.Len(t, moves, 8)
+		for _, move := range moves {
+			outcome, err := PlanMove(game.GetBoard(), move.GetSubmarine(), move.GetDirection(), move.GetDistance())
+			assert.NoError(t, err)
+			assert.Equal(t, shared.MoveReportType(shared.MoveSuccess), outcome.GetReportType())
+		}
+	})
+
+	t.Run("[GameView: 盤面を変更しても影響しない]", func(t *testing.T) {
+		board := view.GetBoard()
+		_, err := board.MoveSubmarine("p1", "p1-sub-4", shared.South, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, &Position{2, 2}, view.GetAllySubmarines()[3].GetPosition())
+	})
+}
+
+func TestGameViewPrediction(t *testing.T) {
+	game := newStartedTestGame(t, defaultP1Positions, defaultP2Positions)
+	_, err := game.Apply(newTestAttack(t, "p1", 4, 4))
+	assert.NoError(t, err)
+	view, err := NewGameView(game, "p2", game.PullTurnLogs())
+	assert.NoError(t, err)
+
+	threat, err := view.Threat(DefaultDiscountRate)
+	assert.NoError(t, err)
+	possibility, err := threat.Possibility(&Position{4, 4})
+	assert.NoError(t, err)
+	assert.InDelta(t, 1, possibility, 1e-9)
+
+	prediction, err := view.Prediction(DefaultDiscountRate)
+	assert.NoError(t, err)
+	possibility, err = prediction.Possibility(&Position{3, 3})
+	assert.NoError(t, err)
+	assert.InDelta(t, p+1.0/8, possibility, 1e-9)
+	assert.Equal(t, game.GetTurn(), prediction.GetCurrentTurn())
+}
+
+func TestGameViewExactPrediction(t *testing.T) {
+	game := newStartedTestGame(t, defaultP1Positions, defaultP2Positions)
+	_, err := game.Apply(newTestAttack(t, "p1", 4, 4))
+	assert.NoError(t, err)
+	view, err := NewGameView(game, "p2", game.PullTurnLogs())
+	assert.NoError(t, err)
+
+	t.Run("[ExactPrediction: キャンセルされたcontext]", func(t *testing.T) {
+		canceled, cancel := context.WithCancel(context.Background())
+		cancel()
+		belief, err := view.ExactPrediction(canceled)
+		assert.Nil(t, belief)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestGameViewDeterminize(t *testing.T) {
+	game := newStartedTestGame(t, defaultP1Positions, defaultP2Positions)
+	_, err := game.Apply(newTestAttack(t, "p1", 4, 4))
+	assert.NoError(t, err)
+	view, err := NewGameView(game, "p2", game.PullTurnLogs())
+	assert.NoError(t, err)
+	belief, err := view.ExactPrediction(context.Background())
+	assert.NoError(t, err)
+
+	t.Run("[Determinize: 仮定した敵艦で対戦を進められる]", func(t *testing.T) {
+		enemies, err := belief.SampleFleet(rand.New(rand.NewSource(1)), "p1")
+		assert.NoError(t, err)
+		determinized, err := view.Determinize(enemies)
+		assert.NoError(t, err)
+		assert.Equal(t, game.GetTurn(), determinized.GetTurn())
+		assert.Equal(t, shared.PlayerId("p2"), determinized.GetCurrentPlayerId())
+		assert.Len(t, determinized.GetBoard().GetAllySubmarines("p1"), shared.SubmarineCount)
+
+		target := view.LegalAttackTargets()[0]
+		command, err := NewActionCommand("p2", shared.Attack, target, shared.DirectionUnknown, 0)
+		assert.NoError(t, err)
+		_, err = determinized.Apply(command)
+		assert.NoError(t, err)
+		assert.Equal(t, game.GetTurn(), view.GetTurn())
+		assert.Equal(t, shared.PlayerId("p2"), game.GetCurrentPlayerId())
+	})
+
+	t.Run("[Determinize: 相手以外の潜水艦]", func(t *testing.T) {
+		submarine, err := NewSubmarine("p2-sample-1", "p2", &Position{1, 1}, shared.InitialHp)
+		assert.NoError(t, err)
+		_, err = view.Determinize([]*Submarine{submarine})
+		assert.ErrorIs(t, err, shared.ErrInvalidPlayerID)
+		_, err = view.Determinize([]*Submarine{nil})
+		assert.ErrorIs(t, err, shared.ErrSubmarineIsNil)
+	})
+}
@@ -0,0 +1,434 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	shared "backend/domain/shared"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newStartedTestGame は p1(先攻) と p2 が4隻ずつ配置済みの開始済みゲームを返す.
+func newStartedTestGame(t *testing.T, p1Positions []Position, p2Positions []Position) *Game {
+	t.Helper()
+	board := newTestBoard(t, map[shared.PlayerId][]Position{
+		"p1": p1Positions,
+		"p2": p2Positions,
+	})
+	game, err := NewGame("g1", "p1", "p2", board)
+	assert.NoError(t, err)
+	assert.NoError(t, game.Start())
+	return game
+}
+
+func newTestAttack(t *testing.T, playerId shared.PlayerId, x int, y int) *ActionCommand {
+	t.Helper()
+	target, err := NewPosition(x, y)
+	assert.NoError(t, err)
+	command, err := NewActionCommand(playerId, shared.Attack, target, shared.DirectionUnknown, 0)
+	assert.NoError(t, err)
+	return command
+}
+
+func newTestMove(t *testing.T, playerId shared.PlayerId, direction shared.Direction, distance int) *ActionCommand {
+	t.Helper()
+	command, err := NewActionCommand(playerId, shared.Move, nil, direction, distance)
+	assert.NoError(t, err)
+	return command
+}
+
+var (
+	defaultP1Positions = []Position{{2, 2}, {2, 3}, {3, 2}, {3, 3}}
+	defaultP2Positions = []Position{{4, 4}, {4, 5}, {5, 4}, {5, 5}}
+)
+
+func TestNewGameFail(t *testing.T) {
+	testList := []struct {
+		name        string
+		id          shared.GameId
+		playerAId   shared.PlayerId
+		playerBId   shared.PlayerId
+		board       *Board
+		expectedErr error
+	}{
+		{"[NewGame: idが空]", "", "p1", "p2", NewBoard(), shared.ErrInvalidGameId},
+		{"[NewGame: playerAIdが空]", "g1", "", "p2", NewBoard(), shared.ErrInvalidPlayerID},
+		{"[NewGame: 同じプレイヤー]", "g1", "p1", "p1", NewBoard(), shared.ErrInvalidPlayerID},
+		{"[NewGame: boardがnil]", "g1", "p1", "p2", nil, shared.ErrBoardIsNil},
+	}
+	for _, tl := range testList {
+		t.Run(tl.name, func(t *testing.T) {
+			game, err := NewGame(tl.id, tl.playerAId, tl.playerBId, tl.board)
+			assert.Nil(t, game)
+			assert.ErrorIs(t, err, tl.expectedErr)
+		})
+	}
+}
+
+func TestGameStart(t *testing.T) {
+	t.Run("[Start: プレイヤーAの手番から開始]", func(t *testing.T) {
+		game := newStartedTestGame(t, defaultP1Positions, defaultP2Positions)
+		assert.Equal(t, shared.GameStatus(shared.InProgress), game.GetStatus())
+		assert.Equal(t, 1, game.GetTurn())
+		assert.Equal(t, shared.PlayerId("p1"), game.GetCurrentPlayerId())
+		assert.False(t, game.IsFinished())
+	})
+
+	t.Run("[Start: 配置が足りない]", func(t *testing.T) {
+		board := newTestBoard(t, map[shared.PlayerId][]Position{"p1": defaultP1Positions})
+		game, err := NewGame("g1", "p1", "p2", board)
+		assert.NoError(t, err)
+		assert.ErrorIs(t, game.Start(), shared.ErrFleetIncomplete)
+	})
+
+	t.Run("[Start: 二重に開始]", func(t *testing.T) {
+		game := newStartedTestGame(t, defaultP1Positions, defaultP2Positions)
+		assert.ErrorIs(t, game.Start(), shared.ErrGameAlreadyStarted)
+	})
+}
+
+func TestGameApplyAttack(t *testing.T) {
+	testList := []struct {
+		name           string
+		x              int
+		y              int
+		expectedReport shared.AttackReportType
+		expectedHit    int
+	}{
+		{"[Apply: 命中]", 4, 4, shared.Hit, 1},
+		{"[Apply: 波高し]", 3, 4, shared.WaveHigh, 0},
+		{"[Apply: 異常なし]", 1, 1, shared.Miss, 0},
+	}
+	for _, tl := range testList {
+		t.Run(tl.name, func(t *testing.T) {
+			game := newStartedTestGame(t, defaultP1Positions, defaultP2Positions)
+			result, err := game.Apply(newTestAttack(t, "p1", tl.x, tl.y))
+			assert.NoError(t, err)
+			assert.Equal(t, tl.expectedReport, result.AttackReport)
+			assert.Equal(t, tl.expectedHit, result.HitCount)
+			assert.Equal(t, shared.ErrorCode(shared.ErrorNone), result.GetErrorCode())
+			assert.Equal(t, shared.PlayerId("p2"), result.GetNextPlayerId())
+			assert.Equal(t, 2, game.GetTurn())
+		})
+	}
+}
+
+func TestGameGetPlayer(t *testing.T) {
+	game := newStartedTestGame(t, defaultP1Positions, defaultP2Positions)
+	assert.Equal(t, 12, game.GetPlayer("p1").RemainingHp())
+	assert.Equal(t, 12, game.GetPlayer("p2").RemainingHp())
+	assert.Equal(t, "p2", game.GetPlayer("p2").GetId())
+	assert.Nil(t, game.GetPlayer("p3"))
+
+	// プレイヤーは対戦の盤面の艦隊を参照する.
+	_, err := game.Apply(newTestAttack(t, "p1", 4, 4))
+	assert.NoError(t, err)
+	assert.Equal(t, game.GetBoard().RemainingHp("p2"), game.GetPlayer("p2").RemainingHp())
+	assert.Less(t, game.GetPlayer("p2").RemainingHp(), 12)
+
+	restored, err := RestoreGame(game.Snapshot())
+	assert.NoError(t, err)
+	assert.Equal(t, game.GetPlayer("p2").RemainingHp(), restored.GetPlayer("p2").RemainingHp())
+	assert.Equal(t, shared.SubmarineCount, restored.GetPlayer("p1").LiveSubmarineCount())
+}
+
+func TestGameClone(t *testing.T) {
+	game := newStartedTestGame(t, defaultP1Positions, defaultP2Positions)
+	_, err := game.Apply(newTestAttack(t, "p1", 4, 4))
+	assert.NoError(t, err)
+	assert.NoError(t, game.SetVersion(3))
+
+	clone := game.Clone()
+	assert.Empty(t, clone.PullTurnLogs())
+	assert.Equal(t, 3, clone.GetVersion())
+	assert.Equal(t, game.GetTurn(), clone.GetTurn())
+	assert.Equal(t, game.GetCurrentPlayerId(), clone.GetCurrentPlayerId())
+	assert.Equal(t, game.GetBoard().RemainingHp("p2"), clone.GetBoard().RemainingHp("p2"))
+	assert.NotSame(t, game.GetBoard(), clone.GetBoard())
+
+	_, err = clone.Apply(newTestAttack(t, "p2", 3, 3))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, game.GetTurn())
+	assert.Equal(t, shared.PlayerId("p2"), game.GetCurrentPlayerId())
+	assert.Equal(t, game.GetBoard().RemainingHp("p1")-1, clone.GetBoard().RemainingHp("p1"))
+	assert.Equal(t, game.GetPlayer("p1").RemainingHp()-1, clone.GetPlayer("p1").RemainingHp())
+	assert.Len(t, game.PullTurnLogs(), 1)
+
+	assert.Nil(t, (*Game)(nil).Clone())
+}
+
+func TestGamePendingTurnLogs(t *testing.T) {
+	game := newStartedTestGame(t, defaultP1Positions, defaultP2Positions)
+	_, err := game.Apply(newTestAttack(t, "p1", 4, 4))
+	assert.NoError(t, err)
+
+	pending := game.PendingTurnLogs()
+	assert.Len(t, pending, 1)
+	assert.Equal(t, pending, game.PullTurnLogs())
+	assert.Empty(t, game.PendingTurnLogs())
+}
+
+func TestGameSetVersion(t *testing.T) {
+	game := newStartedTestGame(t, defaultP1Positions, defaultP2Positions)
+	assert.Equal(t, 0, game.GetVersion())
+	assert.NoError(t, game.SetVersion(2))
+	assert.Equal(t, 2, game.GetVersion())
+	assert.ErrorIs(t, game.SetVersion(-1), shared.ErrInvalidGameVersion)
+	assert.Equal(t, 2, game.GetVersion())
+	assert.ErrorIs(t, (*Game)(nil).SetVersion(1), shared.ErrGameIsNil)
+}
+
+func TestGameApplyHitAndSunk(t *testing.T) {
+	game := newStartedTestGame(t, defaultP1Positions, defaultP2Positions)
+	var result *TurnResult
+	for i := 0; i < shared.InitialHp; i++ {
+		var err error
+		result, err = game.Apply(newTestAttack(t, "p1", 4, 4))
+		assert.NoError(t, err)
+		_, err = game.Apply(newTestAttack(t, "p2", 3, 5))
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, shared.AttackReportType(shared.HitAndSunk), result.AttackReport)
+	assert.Equal(t, 1, result.GetSunkCount())
+}
+
+func TestGameApplyMove(t *testing.T) {
+	game := newStartedTestGame(t, defaultP1Positions, defaultP2Positions)
+	result, err := game.Apply(newTestMove(t, "p1", shared.North, 1))
+	assert.NoError(t, err)
+	assert.Equal(t, shared.MoveReportType(shared.MoveSuccess), result.MoveReport)
+	assert.Equal(t, shared.AttackReportType(shared.AttackReportUnknown), result.AttackReport)
+
+	submarine, err := game.GetBoard().GetSubmarine("p1-sub-1")
+	assert.NoError(t, err)
+	assert.Equal(t, Position{2, 1}, *submarine.GetPosition())
+}
+
+func TestGameApplyFail(t *testing.T) {
+	t.Run("[Apply: 手番ではない]", func(t *testing.T) {
+		game := newStartedTestGame(t, defaultP1Positions, defaultP2Positions)
+		result, err := game.Apply(newTestAttack(t, "p2", 1, 1))
+		assert.ErrorIs(t, err, shared.ErrInvalidTurn)
+		assert.Equal(t, shared.ErrorCode(shared.InvalidTurn), result.GetErrorCode())
+		assert.Equal(t, shared.AttackReportType(shared.InvalidAttack), result.AttackReport)
+		assert.Equal(t, shared.PlayerId("p1"), result.GetNextPlayerId())
+		assert.Equal(t, 1, game.GetTurn())
+	})
+
+	t.Run("[Apply: 開始前]", func(t *testing.T) {
+		board := newTestBoard(t, map[shared.PlayerId][]Position{"p1": defaultP1Positions, "p2": defaultP2Positions})
+		game, err := NewGame("g1", "p1", "p2", board)
+		assert.NoError(t, err)
+		_, err = game.Apply(newTestAttack(t, "p1", 3, 3))
+		assert.ErrorIs(t, err, shared.ErrGameNotInProgress)
+	})
+
+	t.Run("[Apply: どの潜水艦も移動できない]", func(t *testing.T) {
+		game := newStartedTestGame(t, defaultP1Positions, defaultP2Positions)
+		result, err := game.Apply(newTestMove(t, "p1", shared.North, 2))
+		assert.ErrorIs(t, err, shared.ErrMoveBlocked)
+		assert.Equal(t, shared.ErrorCode(shared.InvalidAction), result.GetErrorCode())
+		assert.Equal(t, shared.MoveReportType(shared.MoveBlocked), result.MoveReport)
+		assert.Equal(t, shared.PlayerId("p1"), game.GetCurrentPlayerId())
+	})
+
+	t.Run("[Apply: 攻撃範囲外]", func(t *testing.T) {
+		game := newStartedTestGame(t, defaultP1Positions, defaultP2Positions)
+		result, err := game.Apply(newTestAttack(t, "p1", 5, 5))
+		assert.ErrorIs(t, err, shared.ErrTargetOutOfRange)
+		assert.Equal(t, shared.ErrorCode(shared.InvalidTarget), result.GetErrorCode())
+		assert.Equal(t, 1, game.GetTurn())
+	})
+
+	t.Run("[Apply: commandがnil]", func(t *testing.T) {
+		game := newStartedTestGame(t, defaultP1Positions, defaultP2Positions)
+		_, err := game.Apply(nil)
+		assert.ErrorIs(t, err, shared.ErrActionCommandIsNil)
+	})
+}
+
+func TestGameFinish(t *testing.T) {
+	p2Positions := []Position{{3, 2}, {3, 3}, {3, 4}, {3, 5}}
+	game := newStartedTestGame(t, []Position{{2, 2}, {2, 3}, {2, 4}, {2, 5}}, p2Positions)
+	var result *TurnResult
+	for _, target := range p2Positions {
+		for i := 0; i < shared.InitialHp; i++ {
+			var err error
+			result, err = game.Apply(newTestAttack(t, "p1", target.x, target.y))
+			assert.NoError(t, err)
+			if game.IsFinished() {
+				break
+			}
+			_, err = game.Apply(newTestAttack(t, "p2", 4, 5))
+			assert.NoError(t, err)
+		}
+	}
+	assert.True(t, game.IsFinished())
+	assert.Equal(t, shared.PlayerId("p1"), game.GetWinnerId())
+	assert.Equal(t, shared.FinishReason(shared.FleetDestroyed), game.GetFinishReason())
+	assert.Equal(t, shared.PlayerId(""), result.GetNextPlayerId())
+
+	_, err := game.Apply(newTestAttack(t, "p2", 4, 5))
+	assert.ErrorIs(t, err, shared.ErrGameNotInProgress)
+}
+
+func TestGameApplyWithSubmarine(t *testing.T) {
+	t.Run("[Apply: 指定した潜水艦を移動する]", func(t *testing.T) {
+		game := newStartedTestGame(t, defaultP1Positions, defaultP2Positions)
+		cmd, err := NewActionCommandWithSubmarine("p1", "p1-sub-4", shared.Move, nil, shared.South, 2)
+		assert.NoError(t, err)
+		_, err = game.Apply(cmd)
+		assert.NoError(t, err)
+		submarine, err := game.GetBoard().GetSubmarine("p1-sub-4")
+		assert.NoError(t, err)
+		assert.Equal(t, Position{3, 5}, *submarine.GetPosition())
+	})
+
+	t.Run("[Apply: 指定した潜水艦の射程外は攻撃できない]", func(t *testing.T) {
+		game := newStartedTestGame(t, defaultP1Positions, defaultP2Positions)
+		target, err := NewPosition(4, 4)
+		assert.NoError(t, err)
+		cmd, err := NewActionCommandWithSubmarine("p1", "p1-sub-1", shared.Attack, target, shared.DirectionUnknown, 0)
+		assert.NoError(t, err)
+		result, err := game.Apply(cmd)
+		assert.ErrorIs(t, err, shared.ErrTargetOutOfRange)
+		assert.Equal(t, shared.ErrorCode(shared.InvalidTarget), result.GetErrorCode())
+	})
+
+	t.Run("[Apply: 相手の潜水艦は指定できない]", func(t *testing.T) {
+		game := newStartedTestGame(t, defaultP1Positions, defaultP2Positions)
+		cmd, err := NewActionCommandWithSubmarine("p1", "p2-sub-1", shared.Move, nil, shared.North, 1)
+		assert.NoError(t, err)
+		result, err := game.Apply(cmd)
+		assert.ErrorIs(t, err, shared.ErrSubmarineNotAvailable)
+		assert.Equal(t, shared.ErrorCode(shared.InvalidAction), result.GetErrorCode())
+	})
+}
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (clock *fakeClock) Now() time.Time {
+	return clock.now
+}
+
+func (clock *fakeClock) Advance(duration time.Duration) {
+	clock.now = clock.now.Add(duration)
+}
+
+// newTimedTestGame は fakeClock を注入した開始済みのゲームを返す.
+func newTimedTestGame(t *testing.T, turnTimeLimit time.Duration, policy shared.TurnTimeoutPolicy) (*Game, *fakeClock) {
+	t.Helper()
+	board := newTestBoard(t, map[shared.PlayerId][]Position{
+		"p1": defaultP1Positions,
+		"p2": defaultP2Positions,
+	})
+	game, err := NewGame("g1", "p1", "p2", board)
+	assert.NoError(t, err)
+	clock := &fakeClock{now: time.Date(2026, 4, 1, 10, 0, 0, 0, time.UTC)}
+	assert.NoError(t, game.SetClock(clock))
+	assert.NoError(t, game.SetTurnTimeLimit(turnTimeLimit, policy))
+	assert.NoError(t, game.Start())
+	return game, clock
+}
+
+func TestGameTimeUp(t *testing.T) {
+	testList := []struct {
+		name           string
+		p1Damage       int
+		p2Damage       int
+		expectedWinner shared.PlayerId
+		expectedDraw   bool
+	}{
+		{"[Tick: 残存HPの多いp1の勝ち]", 0, 2, "p1", false},
+		{"[Tick: 残存HPの多いp2の勝ち]", 1, 0, "p2", false},
+		{"[Tick: 残存HPが同じなら引き分け]", 1, 1, "", true},
+	}
+	for _, tl := range testList {
+		t.Run(tl.name, func(t *testing.T) {
+			game, clock := newTimedTestGame(t, 0, shared.TurnTimeoutPass)
+			for playerId, damage := range map[shared.PlayerId]int{"p1": tl.p1Damage, "p2": tl.p2Damage} {
+				if damage == 0 {
+					continue
+				}
+				submarine := game.GetBoard().GetAllySubmarines(playerId)[0]
+				assert.NoError(t, submarine.TakeDamage(damage))
+			}
+
+			clock.Advance(shared.MatchDuration - time.Second)
+			assert.NoError(t, game.Tick())
+			assert.False(t, game.IsFinished())
+			assert.Equal(t, time.Second, game.GetRemainingTime())
+
+			clock.Advance(time.Second)
+			assert.NoError(t, game.Tick())
+			assert.True(t, game.IsFinished())
+			assert.Equal(t, tl.expectedWinner, game.GetWinnerId())
+			assert.Equal(t, tl.expectedDraw, game.IsDraw())
+			assert.Equal(t, shared.FinishReason(shared.TimeUp), game.GetFinishReason())
+			assert.Equal(t, time.Duration(0), game.GetRemainingTime())
+		})
+	}
+}
+
+func TestGameApplyAfterTimeUp(t *testing.T) {
+	game, clock := newTimedTestGame(t, 0, shared.TurnTimeoutPass)
+	clock.Advance(shared.MatchDuration)
+	result, err := game.Apply(newTestAttack(t, "p1", 4, 4))
+	assert.ErrorIs(t, err, shared.ErrGameNotInProgress)
+	assert.Equal(t, shared.AttackReportType(shared.InvalidAttack), result.AttackReport)
+	assert.True(t, game.IsDraw())
+}
+
+func TestGameTurnTimeLimit(t *testing.T) {
+	t.Run("[Tick: 制限時間を過ぎたら手番をパスする]", func(t *testing.T) {
+		game, clock := newTimedTestGame(t, 30*time.Second, shared.TurnTimeoutPass)
+		clock.Advance(29 * time.Second)
+		assert.NoError(t, game.Tick())
+		assert.Equal(t, shared.PlayerId("p1"), game.GetCurrentPlayerId())
+
+		clock.Advance(time.Second)
+		_, err := game.Apply(newTestAttack(t, "p1", 4, 4))
+		assert.ErrorIs(t, err, shared.ErrInvalidTurn)
+		assert.Equal(t, shared.PlayerId("p2"), game.GetCurrentPlayerId())
+		assert.Equal(t, 2, game.GetTurn())
+
+		clock.Advance(65 * time.Second)
+		assert.NoError(t, game.Tick())
+		assert.Equal(t, shared.PlayerId("p2"), game.GetCurrentPlayerId())
+		assert.Equal(t, 4, game.GetTurn())
+	})
+
+	t.Run("[Tick: 行動すると制限時間がリセットされる]", func(t *testing.T) {
+		game, clock := newTimedTestGame(t, 30*time.Second, shared.TurnTimeoutPass)
+		clock.Advance(20 * time.Second)
+		_, err := game.Apply(newTestAttack(t, "p1", 4, 4))
+		assert.NoError(t, err)
+		clock.Advance(20 * time.Second)
+		assert.NoError(t, game.Tick())
+		assert.Equal(t, shared.PlayerId("p2"), game.GetCurrentPlayerId())
+	})
+
+	t.Run("[Tick: 制限時間を過ぎたら反則負け]", func(t *testing.T) {
+		game, clock := newTimedTestGame(t, 30*time.Second, shared.TurnTimeoutForfeit)
+		clock.Advance(30 * time.Second)
+		assert.NoError(t, game.Tick())
+		assert.True(t, game.IsFinished())
+		assert.Equal(t, shared.PlayerId("p2"), game.GetWinnerId())
+		assert.Equal(t, shared.FinishReason(shared.TurnTimeout), game.GetFinishReason())
+	})
+}
+
+func TestGameTimeSettingsFail(t *testing.T) {
+	game := newStartedTestGame(t, defaultP1Positions, defaultP2Positions)
+	assert.ErrorIs(t, game.SetMatchDuration(time.Minute), shared.ErrGameAlreadyStarted)
+	assert.ErrorIs(t, game.SetClock(nil), shared.ErrClockIsNil)
+
+	board := newTestBoard(t, map[shared.PlayerId][]Position{"p1": defaultP1Positions, "p2": defaultP2Positions})
+	waiting, err := NewGame("g2", "p1", "p2", board)
+	assert.NoError(t, err)
+	assert.ErrorIs(t, waiting.SetMatchDuration(0), shared.ErrInvalidDuration)
+	assert.ErrorIs(t, waiting.SetTurnTimeLimit(-time.Second, shared.TurnTimeoutPass), shared.ErrInvalidDuration)
+}
@@ -1,5 +1,22 @@
 package interfaces
 
+import (
+	"backend/domain"
+	"context"
+)
+
 type CPUPlayer interface {
-	// Decide(game) ActionCommand
-}
\ No newline at end of file
+	// Decide returns the next command for the viewer of view, using only what that player may legally know.
+	Decide(ctx context.Context, view *domain.GameView) (*domain.ActionCommand, error)
+}
+
+type ExplainingCPUPlayer interface {
+	CPUPlayer
+	// DecideWithRationale returns the same command as Decide together with why it was chosen.
+	DecideWithRationale(ctx context.Context, view *domain.GameView) (*domain.ActionCommand, *domain.CpuRationale, error)
+}
+
+type CPUPlayerRegistry interface {
+	// New builds the CPU named by profile, configured with its parameters.
+	New(profile *domain.CpuProfile) (CPUPlayer, error)
+}
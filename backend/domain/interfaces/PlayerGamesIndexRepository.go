@@ -1,7 +1,15 @@
 package interfaces
 
+import (
+	"backend/domain/shared"
+	"context"
+)
+
 type PlayerGamesIndexRepository interface {
-	// AddGame(playerId, gameId) error
-	// RemoveGame(playerId, gameId) error
-	// ListGames(playerId) []string
-}
\ No newline at end of file
+	// AddGame records that playerId takes part in gameId. Adding the same game twice has no effect.
+	AddGame(ctx context.Context, playerId shared.PlayerId, gameId shared.GameId) error
+	// RemoveGame forgets gameId for playerId. Removing a game that was not added has no effect.
+	RemoveGame(ctx context.Context, playerId shared.PlayerId, gameId shared.GameId) error
+	// ListGames returns the games of playerId in ascending order of id, or an empty slice if none was added.
+	ListGames(ctx context.Context, playerId shared.PlayerId) ([]shared.GameId, error)
+}
@@ -1,6 +1,16 @@
 package interfaces
 
+import (
+	"backend/domain"
+	"backend/domain/shared"
+	"context"
+)
+
 type TurnLogRepository interface {
-	// Append(gameId, log) error
-	// FindByGameId(gameId) []TurnLog
-}
\ No newline at end of file
+	// Append adds log to the end of the logs of gameId.
+	Append(ctx context.Context, gameId shared.GameId, log *domain.TurnLog) error
+	// FindByGameId returns every log of gameId in the order they were appended.
+	FindByGameId(ctx context.Context, gameId shared.GameId) ([]*domain.TurnLog, error)
+	// FindRange returns at most limit logs of gameId whose turn is fromTurn or later, in the order they were appended.
+	FindRange(ctx context.Context, gameId shared.GameId, fromTurn int, limit int) ([]*domain.TurnLog, error)
+}
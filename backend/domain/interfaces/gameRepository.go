@@ -1,13 +1,19 @@
 package interfaces
 
 import (
+	"backend/domain"
 	"backend/domain/shared"
 	"context"
 )
 
 type GameRepository interface {
-	// Save persists a game identified by gameID.
-	Save(ctx context.Context, gameID shared.GameId) error
-	// FindByID retrieves a game by its ID.
-	FindByID(ctx context.Context, gameID shared.GameId) (interface{}, error)
+	// Save stores game under its id, replacing any previous state. Turn logs not yet pulled from game are not stored.
+	// Save is a compare-and-swap: it succeeds only if the stored version still equals game.GetVersion()
+	// (0 for a game that has never been saved), and then advances the version of both the stored game and game.
+	// Otherwise it returns shared.ErrConcurrentModification and leaves both unchanged.
+	Save(ctx context.Context, game *domain.Game) error
+	// FindByID returns the game stored under gameId with its stored version, or shared.ErrGameNotFound if none was saved.
+	FindByID(ctx context.Context, gameId shared.GameId) (*domain.Game, error)
+	// Delete removes the game stored under gameId, or returns shared.ErrGameNotFound if none was saved.
+	Delete(ctx context.Context, gameId shared.GameId) error
 }
@@ -0,0 +1,11 @@
+package interfaces
+
+import (
+	"backend/domain"
+	"context"
+)
+
+type PlacementStrategy interface {
+	// Place returns shared.SubmarineCount distinct positions for one player's initial fleet.
+	Place(ctx context.Context) ([]*domain.Position, error)
+}
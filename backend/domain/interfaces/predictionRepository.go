@@ -1,6 +1,14 @@
 package interfaces
 
+import (
+	"backend/domain"
+	"backend/domain/shared"
+	"context"
+)
+
 type PredictionRepository interface {
-	// Save(gameId, board) error
-	// Find(gameId) PredictionBoard
-}
\ No newline at end of file
+	// Save stores board as the prediction of playerId in gameId, replacing any previous one.
+	Save(ctx context.Context, gameId shared.GameId, playerId shared.PlayerId, board *domain.PredictionBoard) error
+	// Find returns the prediction of playerId in gameId, or shared.ErrPredictionBoardNotFound if none was saved.
+	Find(ctx context.Context, gameId shared.GameId, playerId shared.PlayerId) (*domain.PredictionBoard, error)
+}
@@ -0,0 +1,29 @@
+package interfaces
+
+import (
+	"backend/domain"
+	"backend/domain/shared"
+	"context"
+)
+
+type UnitOfWork interface {
+	// Begin starts a transaction for the writes of one turn. Nothing is written until Commit,
+	// so a transaction that is abandoned after an error leaves the store untouched.
+	Begin() TurnTransaction
+}
+
+type TurnTransaction interface {
+	// SaveGame stages game like GameRepository.Save. The version check happens on Commit.
+	SaveGame(game *domain.Game) error
+	// AppendTurnLog stages log to be appended to the turn logs of gameId like TurnLogRepository.Append.
+	AppendTurnLog(gameId shared.GameId, log *domain.TurnLog) error
+	// SavePrediction stages board as the prediction of playerId in gameId like PredictionRepository.Save.
+	SavePrediction(gameId shared.GameId, playerId shared.PlayerId, board *domain.PredictionBoard) error
+	// AddPlayerGame stages gameId to be added to the games of playerId like PlayerGamesIndexRepository.AddGame.
+	AddPlayerGame(playerId shared.PlayerId, gameId shared.GameId) error
+	// Commit writes every staged change at once: readers see either none of them or all of them.
+	// If a staged game's stored version has moved, Commit writes nothing and returns shared.ErrConcurrentModification.
+	// On success the version of every staged game is advanced as by GameRepository.Save.
+	// A transaction can be committed only once; later calls return shared.ErrTransactionClosed.
+	Commit(ctx context.Context) error
+}
@@ -0,0 +1,163 @@
+package domain
+
+import (
+	shared "backend/domain/shared"
+)
+
+// MoveOutcome は移動コマンドを解決した結果.
+// 移動できない場合は reportType が MoveBlocked となり, blockedAt に原因となったマスが入る.
+type MoveOutcome struct {
+	reportType shared.MoveReportType
+	submarine  *Submarine
+	direction  shared.Direction
+	distance   int
+	from       *Position
+	path       []*Position
+	blockedAt  *Position
+}
+
+// PlanMove は submarine を direction に distance マス動かした場合の経路を盤面を変更せずに求める.
+// 撃沈済みの潜水艦と味方の潜水艦は経路上にも移動先にも存在してはならない.
+func PlanMove(board *Board, submarine *Submarine, direction shared.Direction, distance int) (*MoveOutcome, error) {
+	if board == nil {
+		return nil, shared.ErrBoardIsNil
+	}
+	if submarine == nil {
+		return nil, shared.ErrSubmarineIsNil
+	}
+	if distance < shared.MinDistance || distance > shared.MaxDistance {
+		return nil, shared.ErrInvalidMoveDistance
+	}
+	if direction == shared.DirectionUnknown {
+		return nil, shared.ErrInvalidAction
+	}
+	if submarine.IsSunk() {
+		return nil, shared.ErrSubmarineNotFound
+	}
+	x, y, err := submarine.GetPosition().GetPosition()
+	if err != nil {
+		return nil, err
+	}
+	dx, dy := direction.Delta()
+	outcome := &MoveOutcome{
+		reportType: shared.MoveSuccess,
+		submarine:  submarine,
+		direction:  direction,
+		distance:   distance,
+		from:       submarine.GetPosition(),
+		path:       make([]*Position, 0, distance),
+	}
+	for step := 1; step <= distance; step++ {
+		next, err := NewPosition(x+dx*step, y+dy*step)
+		if err != nil {
+			return nil, err
+		}
+		outcome.path = append(outcome.path, next)
+		if outcome.blockedAt != nil {
+			continue
+		}
+		blocked, err := board.isBlockedFor(submarine.GetOwnerId(), next)
+		if err != nil {
+			return nil, err
+		}
+		if blocked {
+			outcome.reportType = shared.MoveBlocked
+			outcome.blockedAt = next
+		}
+	}
+	return outcome, nil
+}
+
+// ResolveMove は playerId の潜水艦のうち, id順で最初に移動可能なものを動かす.
+// どの潜水艦も動かせない場合は最初に塞がれた潜水艦の結果と ErrMoveBlocked を返す.
+func ResolveMove(board *Board, playerId shared.PlayerId, direction shared.Direction, distance int) (*MoveOutcome, error) {
+	if board == nil {
+		return nil, shared.ErrBoardIsNil
+	}
+	var blockedOutcome *MoveOutcome
+	var firstErr error
+	for _, submarine := range board.GetAllySubmarines(playerId) {
+		if submarine.IsSunk() {
+			continue
+		}
+		outcome, err := PlanMove(board, submarine, direction, distance)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if outcome.reportType == shared.MoveBlocked {
+			if blockedOutcome == nil {
+				blockedOutcome = outcome
+			}
+			continue
+		}
+		if err := outcome.apply(); err != nil {
+			return nil, err
+		}
+		return outcome, nil
+	}
+	if blockedOutcome != nil {
+		return blockedOutcome, shared.ErrMoveBlocked
+	}
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return nil, shared.ErrSubmarineNotFound
+}
+
+// ResolveMoveOf は指定された submarine を動かす. 移動できない場合は結果と ErrMoveBlocked を返す.
+func ResolveMoveOf(board *Board, submarine *Submarine, direction shared.Direction, distance int) (*MoveOutcome, error) {
+	outcome, err := PlanMove(board, submarine, direction, distance)
+	if err != nil {
+		return nil, err
+	}
+	if err := outcome.apply(); err != nil {
+		return outcome, err
+	}
+	return outcome, nil
+}
+
+func (outcome *MoveOutcome) apply() error {
+	if outcome.reportType != shared.MoveSuccess {
+		return shared.ErrMoveBlocked
+	}
+	return outcome.submarine.MoveTo(outcome.GetDestination())
+}
+
+func (outcome *MoveOutcome) GetReportType() shared.MoveReportType {
+	return outcome.reportType
+}
+
+func (outcome *MoveOutcome) GetSubmarine() *Submarine {
+	return outcome.submarine
+}
+
+func (outcome *MoveOutcome) GetDirection() shared.Direction {
+	return outcome.direction
+}
+
+func (outcome *MoveOutcome) GetDistance() int {
+	return outcome.distance
+}
+
+func (outcome *MoveOutcome) GetFrom() *Position {
+	return outcome.from
+}
+
+func (outcome *MoveOutcome) GetPath() []*Position {
+	return outcome.path
+}
+
+// GetDestination は経路の最後のマスを返す.
+func (outcome *MoveOutcome) GetDestination() *Position {
+	if len(outcome.path) == 0 {
+		return nil
+	}
+	return outcome.path[len(outcome.path)-1]
+}
+
+func (outcome *MoveOutcome) GetBlockedAt() *Position {
+	return outcome.blockedAt
+}
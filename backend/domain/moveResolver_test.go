@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"testing"
+
+	shared "backend/domain/shared"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanMove(t *testing.T) {
+	testList := []struct {
+		name              string
+		direction         shared.Direction
+		distance          int
+		expectedReport    shared.MoveReportType
+		expectedPath      []Position
+		expectedBlockedAt *Position
+	}{
+		{"[PlanMove: 東に2マス]", shared.East, 2, shared.MoveSuccess, []Position{{4, 3}, {5, 3}}, nil},
+		{"[PlanMove: 敵の潜水艦のマスは通れる]", shared.West, 1, shared.MoveSuccess, []Position{{2, 3}}, nil},
+		{"[PlanMove: 撃沈済みの潜水艦を越えられない]", shared.South, 2, shared.MoveBlocked, []Position{{3, 4}, {3, 5}}, &Position{3, 4}},
+		{"[PlanMove: 味方の潜水艦を越えられない]", shared.North, 2, shared.MoveBlocked, []Position{{3, 2}, {3, 1}}, &Position{3, 2}},
+	}
+	for _, tl := range testList {
+		t.Run(tl.name, func(t *testing.T) {
+			board := newTestBoard(t, map[shared.PlayerId][]Position{
+				"p1": {{3, 3}, {3, 2}},
+				"p2": {{3, 4}, {2, 3}},
+			})
+			sunk, err := board.GetSubmarine("p2-sub-1")
+			assert.NoError(t, err)
+			assert.NoError(t, sunk.TakeDamage(shared.InitialHp))
+			submarine, err := board.GetSubmarine("p1-sub-1")
+			assert.NoError(t, err)
+
+			outcome, err := PlanMove(board, submarine, tl.direction, tl.distance)
+			assert.NoError(t, err)
+			assert.Equal(t, tl.expectedReport, outcome.GetReportType())
+			path := make([]Position, 0, len(outcome.GetPath()))
+			for _, position := range outcome.GetPath() {
+				path = append(path, *position)
+			}
+			assert.Equal(t, tl.expectedPath, path)
+			assert.Equal(t, tl.expectedBlockedAt, outcome.GetBlockedAt())
+			assert.Equal(t, Position{3, 3}, *submarine.GetPosition())
+		})
+	}
+}
+
+func TestPlanMoveFail(t *testing.T) {
+	testList := []struct {
+		name        string
+		direction   shared.Direction
+		distance    int
+		expectedErr error
+	}{
+		{"[PlanMove: 盤外]", shared.North, 1, shared.ErrOutOfBoard},
+		{"[PlanMove: 移動距離が不正]", shared.South, 3, shared.ErrInvalidMoveDistance},
+		{"[PlanMove: directionがUnknown]", shared.DirectionUnknown, 1, shared.ErrInvalidAction},
+	}
+	for _, tl := range testList {
+		t.Run(tl.name, func(t *testing.T) {
+			board := newTestBoard(t, map[shared.PlayerId][]Position{"p1": {{1, 1}}})
+			submarine, err := board.GetSubmarine("p1-sub-1")
+			assert.NoError(t, err)
+			outcome, err := PlanMove(board, submarine, tl.direction, tl.distance)
+			assert.Nil(t, outcome)
+			assert.ErrorIs(t, err, tl.expectedErr)
+		})
+	}
+}
+
+func TestResolveMove(t *testing.T) {
+	t.Run("[ResolveMove: 塞がれた潜水艦を飛ばして次の潜水艦を動かす]", func(t *testing.T) {
+		board := newTestBoard(t, map[shared.PlayerId][]Position{"p1": {{1, 1}, {3, 3}}})
+		outcome, err := ResolveMove(board, "p1", shared.North, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, shared.SubmarineId("p1-sub-2"), outcome.GetSubmarine().GetId())
+		assert.Equal(t, Position{3, 3}, *outcome.GetFrom())
+		assert.Equal(t, Position{3, 2}, *outcome.GetDestination())
+		assert.Equal(t, Position{3, 2}, *outcome.GetSubmarine().GetPosition())
+	})
+
+	t.Run("[ResolveMove: すべて塞がれている]", func(t *testing.T) {
+		board := newTestBoard(t, map[shared.PlayerId][]Position{"p1": {{1, 1}, {1, 2}}})
+		outcome, err := ResolveMove(board, "p1", shared.North, 1)
+		assert.ErrorIs(t, err, shared.ErrMoveBlocked)
+		assert.Equal(t, shared.MoveReportType(shared.MoveBlocked), outcome.GetReportType())
+		assert.Equal(t, shared.SubmarineId("p1-sub-2"), outcome.GetSubmarine().GetId())
+		assert.Equal(t, Position{1, 1}, *outcome.GetBlockedAt())
+	})
+
+	t.Run("[ResolveMove: すべて盤外]", func(t *testing.T) {
+		board := newTestBoard(t, map[shared.PlayerId][]Position{"p1": {{1, 1}, {2, 1}}})
+		outcome, err := ResolveMove(board, "p1", shared.North, 1)
+		assert.Nil(t, outcome)
+		assert.ErrorIs(t, err, shared.ErrOutOfBoard)
+	})
+
+	t.Run("[ResolveMove: 潜水艦がいない]", func(t *testing.T) {
+		_, err := ResolveMove(NewBoard(), "p1", shared.North, 1)
+		assert.ErrorIs(t, err, shared.ErrSubmarineNotFound)
+	})
+}
@@ -6,8 +6,9 @@ import (
 )
 
 type Player struct {
-	id   string
-	name string
+	id    string
+	name  string
+	board *Board
 }
 
 func NewPlayer(id string, name string) (*Player, error) {
@@ -23,13 +24,57 @@ func NewPlayer(id string, name string) (*Player, error) {
 	}, nil
 }
 
-func (player *Player) RemainingHp() int {
+// newFleetPlayer は対戦のプレイヤーを board に紐づけて作る. 対戦は表示名を持たないため, 名前は playerId とする.
+func newFleetPlayer(playerId shared.PlayerId, board *Board) (*Player, error) {
+	player, err := NewPlayer(playerId.String(), playerId.String())
+	if err != nil {
+		return nil, err
+	}
+	if err := player.AttachBoard(board); err != nil {
+		return nil, err
+	}
+	return player, nil
+}
+
+// withBoard は player を board に紐づけ直した複製を返す.
+func (player *Player) withBoard(board *Board) *Player {
 	if player == nil {
+		return nil
+	}
+	copied := *player
+	copied.board = board
+	return &copied
+}
+
+// AttachBoard は player の艦隊を保持する盤面を紐づける.
+func (player *Player) AttachBoard(board *Board) error {
+	if player == nil {
+		return shared.ErrInvalidPlayerID
+	}
+	if board == nil {
+		return shared.ErrBoardIsNil
+	}
+	player.board = board
+	return nil
+}
+
+// RemainingHp は撃沈されていない自艦のHPの合計を返す. 盤面が紐づいていない場合は0を返す.
+func (player *Player) RemainingHp() int {
+	if player == nil || player.board == nil {
+		return 0
+	}
+	return player.board.RemainingHp(shared.PlayerId(player.id))
+}
+
+func (player *Player) LiveSubmarineCount() int {
+	if player == nil || player.board == nil {
 		return 0
 	}
-	// submarineマージまでの対応.
-	// interface を呼び出す(sugaくんの実装).
-	return 12
+	return player.board.LiveSubmarineCount(shared.PlayerId(player.id))
+}
+
+func (player *Player) IsFleetDestroyed() bool {
+	return player.LiveSubmarineCount() == 0
 }
 
 func (player *Player) GetId() string {
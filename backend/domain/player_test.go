@@ -84,10 +84,46 @@ func TestNewPlayerFail(t *testing.T) {
 }
 
 func TestRemainingHp(t *testing.T) {
-	t.Run("[RemainingHp: 初期残HPは12]", func(t *testing.T) {
+	testList := []struct {
+		name              string
+		damages           map[shared.SubmarineId]int
+		expectedHp        int
+		expectedLiveCount int
+		expectedDestroyed bool
+	}{
+		{"[RemainingHp: 初期残HPは12]", map[shared.SubmarineId]int{}, 12, 4, false},
+		{"[RemainingHp: 被弾した分だけ減る]", map[shared.SubmarineId]int{"p1-sub-1": 1, "p1-sub-2": 2}, 9, 4, false},
+		{"[RemainingHp: 撃沈された潜水艦は数えない]", map[shared.SubmarineId]int{"p1-sub-1": 3}, 9, 3, false},
+		{"[RemainingHp: 全滅]", map[shared.SubmarineId]int{"p1-sub-1": 3, "p1-sub-2": 3, "p1-sub-3": 3, "p1-sub-4": 3}, 0, 0, true},
+	}
+	for _, tl := range testList {
+		t.Run(tl.name, func(t *testing.T) {
+			board := newTestBoard(t, map[shared.PlayerId][]Position{
+				"p1": {{1, 1}, {2, 2}, {3, 3}, {4, 4}},
+				"p2": {{5, 5}},
+			})
+			for submarineId, damage := range tl.damages {
+				submarine, err := board.GetSubmarine(submarineId)
+				assert.NoError(t, err)
+				assert.NoError(t, submarine.TakeDamage(damage))
+			}
+			game, err := NewGame("g1", "p1", "p2", board)
+			assert.NoError(t, err)
+			player := game.GetPlayer("p1")
+
+			assert.Equal(t, tl.expectedHp, player.RemainingHp())
+			assert.Equal(t, tl.expectedLiveCount, player.LiveSubmarineCount())
+			assert.Equal(t, tl.expectedDestroyed, player.IsFleetDestroyed())
+		})
+	}
+
+	t.Run("[RemainingHp: 盤面を紐づけたプレイヤー]", func(t *testing.T) {
+		board := newTestBoard(t, map[shared.PlayerId][]Position{"p1": {{1, 1}, {2, 2}}})
 		player, err := NewPlayer("p1", "Alice")
 		assert.NoError(t, err)
-		assert.Equal(t, 12, player.RemainingHp())
+		assert.NoError(t, player.AttachBoard(board))
+		assert.Equal(t, 6, player.RemainingHp())
+		assert.ErrorIs(t, player.AttachBoard(nil), shared.ErrBoardIsNil)
 	})
 
 	t.Run("[RemainingHp: nil receiverは0]", func(t *testing.T) {
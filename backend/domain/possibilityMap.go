@@ -0,0 +1,12 @@
+package domain
+
+import shared "backend/domain/shared"
+
+// PossibilityMap は行動記録から求めた敵艦の存在確率マップ.
+// 割引を掛けて近似する PredictionBoard と, 配置を全て列挙する ExactPredictionBoard のどちらも満たす.
+type PossibilityMap interface {
+	ApplyTurnLog(viewerId shared.PlayerId, log *TurnLog) error
+	Possibility(position *Position) (float64, error)
+	Grid() [][]float64
+	GetCurrentTurn() int
+}
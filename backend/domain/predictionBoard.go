@@ -0,0 +1,378 @@
+package domain
+
+import (
+	shared "backend/domain/shared"
+	"math"
+)
+
+const boardSize = shared.MaxPosition - shared.MinPosition + 1
+
+// 何も分かっていないマスの存在確率. 敵艦の数を盤面のマス数で割った値とする.
+const priorPossibility = float64(shared.SubmarineCount) / float64(boardSize*boardSize)
+
+const (
+	maxPossibility    = 1.0
+	moveBandLowerEdge = 0.5
+	moveBandUpperEdge = 0.75
+)
+
+const DefaultDiscountRate = 0.8
+
+// 経過ターン数がこの値以下の情報は割り引かない.
+const undiscountedTurns = 2
+
+type evidenceKind int
+
+const (
+	evidenceMiss evidenceKind = iota
+	evidenceHighWave
+	evidenceHit
+	evidenceSunk
+	evidenceEnemyAttack
+	evidenceEnemyMove
+)
+
+// evidence は存在確率マップに加えた1件の情報と, それを得たターン.
+// 敵艦の移動の場合は position の代わりに direction と distance を使う.
+type evidence struct {
+	kind      evidenceKind
+	position  Position
+	direction shared.Direction
+	distance  int
+	turn      int
+}
+
+// PredictionBoard は各マスに敵艦が存在する確率を 0.0~1.0 で管理する.
+// 更新規則は 06_移動・行動アルゴリズム.md の 2.1 に従い, 2.4 の割引を適用するため
+// 得た情報をターン付きで保持し, ターンが進むたびに確率を計算し直す.
+type PredictionBoard struct {
+	evidences    []evidence
+	currentTurn  int
+	discountRate float64
+	possibility  [boardSize][boardSize]float64
+}
+
+func NewPredictionBoard() *PredictionBoard {
+	board, _ := NewPredictionBoardWithDiscountRate(DefaultDiscountRate)
+	return board
+}
+
+// NewPredictionBoardWithDiscountRate は割引率 Δ を指定して生成する. Δ は 0 より大きく 1 以下.
+func NewPredictionBoardWithDiscountRate(discountRate float64) (*PredictionBoard, error) {
+	if discountRate <= 0 || discountRate > 1 {
+		return nil, shared.ErrInvalidDiscountRate
+	}
+	board := &PredictionBoard{
+		evidences:    make([]evidence, 0),
+		discountRate: discountRate,
+	}
+	board.recompute()
+	return board, nil
+}
+
+// AdvanceTurn は現在のターンを進め, 経過ターン数に応じて確率を計算し直す.
+func (board *PredictionBoard) AdvanceTurn(turn int) error {
+	if board == nil {
+		return shared.ErrPredictionBoardIsNil
+	}
+	if turn < board.currentTurn {
+		return shared.ErrInvalidTurn
+	}
+	board.currentTurn = turn
+	board.recompute()
+	return nil
+}
+
+func (board *PredictionBoard) GetCurrentTurn() int {
+	return board.currentTurn
+}
+
+func (board *PredictionBoard) GetDiscountRate() float64 {
+	return board.discountRate
+}
+
+// MarkMiss は攻撃が「異常なし」だった場合に, 攻撃座標と周囲8マスを0にする.
+func (board *PredictionBoard) MarkMiss(position *Position) error {
+	return board.addEvidence(evidenceMiss, position)
+}
+
+// MarkHighWave は攻撃が「波高し」だった場合に, 攻撃座標を0にして周囲8マスのうち盤面内の N マスへ 1/N ずつ加算する.
+func (board *PredictionBoard) MarkHighWave(position *Position) error {
+	return board.addEvidence(evidenceHighWave, position)
+}
+
+// MarkHit は攻撃が命中した場合に, 攻撃座標を1.0にする.
+func (board *PredictionBoard) MarkHit(position *Position) error {
+	return board.addEvidence(evidenceHit, position)
+}
+
+// MarkSunk は撃沈した場合に, 攻撃座標を0にする. 撃沈済みの潜水艦は動かないため割り引かない.
+func (board *PredictionBoard) MarkSunk(position *Position) error {
+	return board.addEvidence(evidenceSunk, position)
+}
+
+// MarkEnemyAttack は敵が攻撃した場合に, 攻撃座標を0にして周囲8マスのうち盤面内の N マスへ 1/N ずつ加算する.
+// 敵は自軍の潜水艦がいるマスを攻撃できず, 攻撃できるのは自軍の潜水艦の周囲8マスだけであるため.
+func (board *PredictionBoard) MarkEnemyAttack(position *Position) error {
+	return board.addEvidence(evidenceEnemyAttack, position)
+}
+
+// MarkEnemyMove は敵が移動した場合に, 各マスの存在確率を direction へ distance マスずらす.
+func (board *PredictionBoard) MarkEnemyMove(direction shared.Direction, distance int) error {
+	if board == nil {
+		return shared.ErrPredictionBoardIsNil
+	}
+	if distance < shared.MinDistance || distance > shared.MaxDistance {
+		return shared.ErrInvalidMoveDistance
+	}
+	if direction == shared.DirectionUnknown {
+		return shared.ErrInvalidAction
+	}
+	board.evidences = append(board.evidences, evidence{
+		kind:      evidenceEnemyMove,
+		direction: direction,
+		distance:  distance,
+		turn:      board.currentTurn,
+	})
+	board.recompute()
+	return nil
+}
+
+// ApplyTurnLog は viewerId から見た1件の行動記録を存在確率マップに反映する.
+// 自分の攻撃は結果に応じて, 相手の攻撃と移動は 2.2, 2.3 に従って反映する. 拒否された行動は何も分からないため無視する.
+func (board *PredictionBoard) ApplyTurnLog(viewerId shared.PlayerId, log *TurnLog) error {
+	if board == nil {
+		return shared.ErrPredictionBoardIsNil
+	}
+	if log == nil {
+		return shared.ErrTurnLogIsNil
+	}
+	if err := board.AdvanceTurn(log.turn); err != nil {
+		return err
+	}
+	if log.IsRejected() {
+		return nil
+	}
+	if log.playerId == viewerId {
+		if log.actionType != shared.Attack {
+			return nil
+		}
+		switch log.attackReport {
+		case shared.Miss:
+			return board.MarkMiss(log.target)
+		case shared.WaveHigh:
+			return board.MarkHighWave(log.target)
+		case shared.Hit:
+			return board.MarkHit(log.target)
+		case shared.HitAndSunk:
+			return board.MarkSunk(log.target)
+		}
+		return nil
+	}
+	switch log.actionType {
+	case shared.Attack:
+		return board.MarkEnemyAttack(log.target)
+	case shared.Move:
+		return board.MarkEnemyMove(log.direction, log.distance)
+	}
+	return nil
+}
+
+// BestAttackCell は candidates のうち存在確率が最も高いマスを返す. 同じ値の場合は先に並んでいるマスを優先する.
+func (board *PredictionBoard) BestAttackCell(candidates []*Position) (*Position, error) {
+	if board == nil {
+		return nil, shared.ErrPredictionBoardIsNil
+	}
+	var best *Position
+	bestPossibility := -1.0
+	for _, candidate := range candidates {
+		possibility, err := board.Possibility(candidate)
+		if err != nil {
+			return nil, err
+		}
+		if possibility > bestPossibility {
+			best = candidate
+			bestPossibility = possibility
+		}
+	}
+	if best == nil {
+		return nil, shared.ErrNoCandidateCell
+	}
+	return best, nil
+}
+
+// BestMoveCell は candidates のうち存在確率が 0.5~0.75 のマスから最も高いものを返す.
+// 該当するマスがない場合は nil を返す.
+func (board *PredictionBoard) BestMoveCell(candidates []*Position) (*Position, error) {
+	if board == nil {
+		return nil, shared.ErrPredictionBoardIsNil
+	}
+	inBand := make([]*Position, 0, len(candidates))
+	for _, candidate := range candidates {
+		possibility, err := board.Possibility(candidate)
+		if err != nil {
+			return nil, err
+		}
+		if possibility >= moveBandLowerEdge && possibility <= moveBandUpperEdge {
+			inBand = append(inBand, candidate)
+		}
+	}
+	if len(inBand) == 0 {
+		return nil, nil
+	}
+	return board.BestAttackCell(inBand)
+}
+
+func (board *PredictionBoard) Possibility(position *Position) (float64, error) {
+	if board == nil {
+		return 0, shared.ErrPredictionBoardIsNil
+	}
+	within, err := position.withinBoard()
+	if err != nil {
+		return 0, err
+	}
+	if !within {
+		return 0, shared.ErrOutOfBoard
+	}
+	return board.possibility[position.y-shared.MinPosition][position.x-shared.MinPosition], nil
+}
+
+// Grid は存在確率を [y][x] の順で複製して返す.
+func (board *PredictionBoard) Grid() [][]float64 {
+	grid := make([][]float64, boardSize)
+	for y := range board.possibility {
+		grid[y] = make([]float64, boardSize)
+		copy(grid[y], board.possibility[y][:])
+	}
+	return grid
+}
+
+// Clone は保持している情報ごと複製する.
+func (board *PredictionBoard) Clone() *PredictionBoard {
+	if board == nil {
+		return nil
+	}
+	clone := *board
+	clone.evidences = make([]evidence, len(board.evidences))
+	copy(clone.evidences, board.evidences)
+	return &clone
+}
+
+func (board *PredictionBoard) addEvidence(kind evidenceKind, position *Position) error {
+	if board == nil {
+		return shared.ErrPredictionBoardIsNil
+	}
+	within, err := position.withinBoard()
+	if err != nil {
+		return err
+	}
+	if !within {
+		return shared.ErrOutOfBoard
+	}
+	board.evidences = append(board.evidences, evidence{
+		kind:     kind,
+		position: *position,
+		turn:     board.currentTurn,
+	})
+	board.recompute()
+	return nil
+}
+
+// discount は r ターン前に得た情報の重み Δ^r を返す. r が2以下なら割り引かない.
+func (board *PredictionBoard) discount(turn int) float64 {
+	elapsed := board.currentTurn - turn
+	if elapsed <= undiscountedTurns {
+		return 1
+	}
+	return math.Pow(board.discountRate, float64(elapsed))
+}
+
+// recompute は事前確率から始めて, 保持している情報を古い順に割引を掛けて適用する.
+// 0にする情報も同じ重みで事前確率へ戻していき, 移動したかもしれない敵艦の情報が残り続けないようにする.
+// 敵艦の移動は情報を加えず確率をずらすだけなので割り引かない. 撃沈済みのマスには敵艦が入れないため最後に0にする.
+func (board *PredictionBoard) recompute() {
+	for y := range board.possibility {
+		for x := range board.possibility[y] {
+			board.possibility[y][x] = priorPossibility
+		}
+	}
+	var sunk [boardSize][boardSize]bool
+	for i := range board.evidences {
+		evidence := &board.evidences[i]
+		weight := board.discount(evidence.turn)
+		switch evidence.kind {
+		case evidenceMiss:
+			board.clear(&evidence.position, weight)
+			neighbors, _ := evidence.position.Neighbors8()
+			for _, neighbor := range neighbors {
+				board.clear(neighbor, weight)
+			}
+		case evidenceHighWave, evidenceEnemyAttack:
+			board.clear(&evidence.position, weight)
+			board.spreadAround(&evidence.position, weight)
+		case evidenceHit:
+			board.set(&evidence.position, priorPossibility+(maxPossibility-priorPossibility)*weight)
+		case evidenceSunk:
+			board.set(&evidence.position, 0)
+			sunk[evidence.position.y-shared.MinPosition][evidence.position.x-shared.MinPosition] = true
+		case evidenceEnemyMove:
+			board.shift(evidence.direction, evidence.distance, &sunk)
+		}
+	}
+	for y := range sunk {
+		for x := range sunk[y] {
+			if sunk[y][x] {
+				board.possibility[y][x] = 0
+			}
+		}
+	}
+}
+
+// shift は存在確率を direction へ distance マスずらす.
+// 盤面の外や撃沈済みのマスから動いてくる敵艦はいないため, そこから入るマスは事前確率とする.
+func (board *PredictionBoard) shift(direction shared.Direction, distance int, sunk *[boardSize][boardSize]bool) {
+	dx, dy := direction.Delta()
+	previous := board.possibility
+	for y := range board.possibility {
+		for x := range board.possibility[y] {
+			fromX, fromY := x-dx*distance, y-dy*distance
+			if fromX < 0 || fromX >= boardSize || fromY < 0 || fromY >= boardSize || sunk[fromY][fromX] {
+				board.possibility[y][x] = priorPossibility
+				continue
+			}
+			board.possibility[y][x] = previous[fromY][fromX]
+		}
+	}
+}
+
+// clear は weight が1のとき0に, 小さくなるほど事前確率に近い値にする.
+func (board *PredictionBoard) clear(position *Position, weight float64) {
+	board.set(position, priorPossibility*(1-weight))
+}
+
+// spreadAround は周囲8マスのうち存在可能なマスに amount を等分して加算する.
+// 盤面の外や, 異常なし・撃沈で0になったマスには敵艦がいないため数えない.
+func (board *PredictionBoard) spreadAround(center *Position, amount float64) {
+	neighbors, _ := center.Neighbors8()
+	possible := make([]*Position, 0, len(neighbors))
+	for _, neighbor := range neighbors {
+		if board.possibility[neighbor.y-shared.MinPosition][neighbor.x-shared.MinPosition] > 0 {
+			possible = append(possible, neighbor)
+		}
+	}
+	for _, neighbor := range possible {
+		board.add(neighbor, amount/float64(len(possible)))
+	}
+}
+
+func (board *PredictionBoard) set(position *Position, possibility float64) {
+	board.possibility[position.y-shared.MinPosition][position.x-shared.MinPosition] = possibility
+}
+
+func (board *PredictionBoard) add(position *Position, amount float64) {
+	cell := &board.possibility[position.y-shared.MinPosition][position.x-shared.MinPosition]
+	*cell += amount
+	if *cell > maxPossibility {
+		*cell = maxPossibility
+	}
+}
@@ -0,0 +1,553 @@
+package domain
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	shared "backend/domain/shared"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// p は期待値の表を短く書くための事前確率.
+const p = priorPossibility
+
+func TestPredictionBoardMark(t *testing.T) {
+	testList := []struct {
+		name     string
+		mark     func(board *PredictionBoard) error
+		expected [boardSize][boardSize]float64
+	}{
+		{
+			"[MarkMiss: 攻撃座標と周囲8マスが0になる]",
+			func(board *PredictionBoard) error { return board.MarkMiss(&Position{3, 3}) },
+			[boardSize][boardSize]float64{
+				{p, p, p, p, p},
+				{p, 0, 0, 0, p},
+				{p, 0, 0, 0, p},
+				{p, 0, 0, 0, p},
+				{p, p, p, p, p},
+			},
+		},
+		{
+			"[MarkMiss: 角では盤面内のマスだけ0になる]",
+			func(board *PredictionBoard) error { return board.MarkMiss(&Position{1, 1}) },
+			[boardSize][boardSize]float64{
+				{0, 0, p, p, p},
+				{0, 0, p, p, p},
+				{p, p, p, p, p},
+				{p, p, p, p, p},
+				{p, p, p, p, p},
+			},
+		},
+		{
+			"[MarkHighWave: 攻撃座標が0になり周囲8マスに1/8ずつ加算]",
+			func(board *PredictionBoard) error { return board.MarkHighWave(&Position{3, 3}) },
+			[boardSize][boardSize]float64{
+				{p, p, p, p, p},
+				{p, p + 1.0/8, p + 1.0/8, p + 1.0/8, p},
+				{p, p + 1.0/8, 0, p + 1.0/8, p},
+				{p, p + 1.0/8, p + 1.0/8, p + 1.0/8, p},
+				{p, p, p, p, p},
+			},
+		},
+		{
+			"[MarkHighWave: 辺では存在可能な5マスに1/5ずつ加算]",
+			func(board *PredictionBoard) error { return board.MarkHighWave(&Position{3, 1}) },
+			[boardSize][boardSize]float64{
+				{p, p + 1.0/5, 0, p + 1.0/5, p},
+				{p, p + 1.0/5, p + 1.0/5, p + 1.0/5, p},
+				{p, p, p, p, p},
+				{p, p, p, p, p},
+				{p, p, p, p, p},
+			},
+		},
+		{
+			"[MarkHighWave: 異常なしで0になったマスを除いて等分する]",
+			func(board *PredictionBoard) error {
+				if err := board.MarkMiss(&Position{1, 1}); err != nil {
+					return err
+				}
+				return board.MarkHighWave(&Position{2, 3})
+			},
+			[boardSize][boardSize]float64{
+				{0, 0, p, p, p},
+				{0, 0, p + 1.0/6, p, p},
+				{p + 1.0/6, 0, p + 1.0/6, p, p},
+				{p + 1.0/6, p + 1.0/6, p + 1.0/6, p, p},
+				{p, p, p, p, p},
+			},
+		},
+		{
+			"[MarkHighWave: 存在可能なマスがなければ加算しない]",
+			func(board *PredictionBoard) error {
+				if err := board.MarkMiss(&Position{2, 2}); err != nil {
+					return err
+				}
+				return board.MarkHighWave(&Position{1, 1})
+			},
+			[boardSize][boardSize]float64{
+				{0, 0, 0, p, p},
+				{0, 0, 0, p, p},
+				{0, 0, 0, p, p},
+				{p, p, p, p, p},
+				{p, p, p, p, p},
+			},
+		},
+		{
+			"[MarkHighWave: 1.0を超えない]",
+			func(board *PredictionBoard) error {
+				for i := 0; i < 3; i++ {
+					if err := board.MarkHighWave(&Position{1, 1}); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			[boardSize][boardSize]float64{
+				{0, 1, p, p, p},
+				{1, 1, p, p, p},
+				{p, p, p, p, p},
+				{p, p, p, p, p},
+				{p, p, p, p, p},
+			},
+		},
+		{
+			"[MarkHit: 攻撃座標が1.0になる]",
+			func(board *PredictionBoard) error { return board.MarkHit(&Position{2, 4}) },
+			[boardSize][boardSize]float64{
+				{p, p, p, p, p},
+				{p, p, p, p, p},
+				{p, p, p, p, p},
+				{p, 1, p, p, p},
+				{p, p, p, p, p},
+			},
+		},
+		{
+			"[MarkSunk: 命中の後に撃沈すると0になる]",
+			func(board *PredictionBoard) error {
+				if err := board.MarkHit(&Position{2, 4}); err != nil {
+					return err
+				}
+				return board.MarkSunk(&Position{2, 4})
+			},
+			[boardSize][boardSize]float64{
+				{p, p, p, p, p},
+				{p, p, p, p, p},
+				{p, p, p, p, p},
+				{p, 0, p, p, p},
+				{p, p, p, p, p},
+			},
+		},
+	}
+	for _, tl := range testList {
+		t.Run(tl.name, func(t *testing.T) {
+			board := NewPredictionBoard()
+			assert.NoError(t, tl.mark(board))
+			grid := board.Grid()
+			for y := range tl.expected {
+				assert.InDeltaSlice(t, tl.expected[y][:], grid[y], 1e-9, "y=%d", y+shared.MinPosition)
+			}
+		})
+	}
+}
+
+func TestPredictionBoardMarkFail(t *testing.T) {
+	board := NewPredictionBoard()
+	assert.ErrorIs(t, board.MarkMiss(nil), shared.ErrPositionIsNil)
+	assert.ErrorIs(t, board.MarkHighWave(nil), shared.ErrPositionIsNil)
+	assert.ErrorIs(t, board.MarkHit(nil), shared.ErrPositionIsNil)
+	assert.ErrorIs(t, board.MarkSunk(nil), shared.ErrPositionIsNil)
+
+	var nilBoard *PredictionBoard
+	assert.ErrorIs(t, nilBoard.MarkHit(&Position{1, 1}), shared.ErrPredictionBoardIsNil)
+}
+
+func TestPredictionBoardBestAttackCell(t *testing.T) {
+	board := NewPredictionBoard()
+	assert.NoError(t, board.MarkHighWave(&Position{3, 3}))
+	assert.NoError(t, board.MarkHit(&Position{5, 5}))
+
+	testList := []struct {
+		name       string
+		candidates []*Position
+		expected   *Position
+	}{
+		{"[BestAttackCell: 命中したマス]", []*Position{{1, 1}, {5, 5}, {2, 2}}, &Position{5, 5}},
+		{"[BestAttackCell: 波高しの周囲]", []*Position{{1, 1}, {3, 3}, {2, 2}}, &Position{2, 2}},
+		{"[BestAttackCell: 同じ値なら先の候補]", []*Position{{4, 4}, {2, 2}}, &Position{4, 4}},
+	}
+	for _, tl := range testList {
+		t.Run(tl.name, func(t *testing.T) {
+			best, err := board.BestAttackCell(tl.candidates)
+			assert.NoError(t, err)
+			assert.Equal(t, tl.expected, best)
+		})
+	}
+
+	t.Run("[BestAttackCell: 候補なし]", func(t *testing.T) {
+		best, err := board.BestAttackCell(nil)
+		assert.Nil(t, best)
+		assert.ErrorIs(t, err, shared.ErrNoCandidateCell)
+	})
+}
+
+func TestPredictionBoardBestMoveCell(t *testing.T) {
+	board := NewPredictionBoard()
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, board.MarkHighWave(&Position{3, 3}))
+	}
+	assert.NoError(t, board.MarkHighWave(&Position{3, 1}))
+	assert.NoError(t, board.MarkHit(&Position{5, 1}))
+
+	testList := []struct {
+		name       string
+		candidates []*Position
+		expected   *Position
+	}{
+		{"[BestMoveCell: 0.5~0.75のマス]", []*Position{{5, 1}, {2, 3}, {1, 1}}, &Position{2, 3}},
+		{"[BestMoveCell: 範囲内で最も高いマス]", []*Position{{2, 3}, {3, 2}}, &Position{3, 2}},
+		{"[BestMoveCell: 該当なし]", []*Position{{5, 1}, {1, 1}}, nil},
+	}
+	for _, tl := range testList {
+		t.Run(tl.name, func(t *testing.T) {
+			best, err := board.BestMoveCell(tl.candidates)
+			assert.NoError(t, err)
+			assert.Equal(t, tl.expected, best)
+		})
+	}
+}
+
+func TestPredictionBoardDiscount(t *testing.T) {
+	testList := []struct {
+		name     string
+		mark     func(board *PredictionBoard) error
+		turn     int
+		position *Position
+		expected float64
+	}{
+		{
+			"[割引: 2ターン後までは割り引かない]",
+			func(board *PredictionBoard) error { return board.MarkHighWave(&Position{3, 3}) },
+			3,
+			&Position{2, 2},
+			p + 1.0/8,
+		},
+		{
+			"[割引: 3ターン後の波高しは Δ^3 倍]",
+			func(board *PredictionBoard) error { return board.MarkHighWave(&Position{3, 3}) },
+			4,
+			&Position{2, 2},
+			p + 0.512/8,
+		},
+		{
+			"[割引: 異常なしは事前確率へ戻っていく]",
+			func(board *PredictionBoard) error { return board.MarkMiss(&Position{3, 3}) },
+			5,
+			&Position{2, 2},
+			p * (1 - 0.4096),
+		},
+		{
+			"[割引: 命中は事前確率へ戻っていく]",
+			func(board *PredictionBoard) error { return board.MarkHit(&Position{3, 3}) },
+			4,
+			&Position{3, 3},
+			p + (1-p)*0.512,
+		},
+		{
+			"[割引: 撃沈は割り引かない]",
+			func(board *PredictionBoard) error { return board.MarkSunk(&Position{3, 3}) },
+			10,
+			&Position{3, 3},
+			0,
+		},
+	}
+	for _, tl := range testList {
+		t.Run(tl.name, func(t *testing.T) {
+			board := NewPredictionBoard()
+			assert.NoError(t, board.AdvanceTurn(1))
+			assert.NoError(t, tl.mark(board))
+			assert.NoError(t, board.AdvanceTurn(tl.turn))
+			possibility, err := board.Possibility(tl.position)
+			assert.NoError(t, err)
+			assert.InDelta(t, tl.expected, possibility, 1e-9)
+		})
+	}
+
+	t.Run("[割引: 新しい情報で上書きされる]", func(t *testing.T) {
+		board := NewPredictionBoard()
+		assert.NoError(t, board.MarkHit(&Position{3, 3}))
+		assert.NoError(t, board.AdvanceTurn(5))
+		assert.NoError(t, board.MarkMiss(&Position{3, 3}))
+		possibility, err := board.Possibility(&Position{3, 3})
+		assert.NoError(t, err)
+		assert.InDelta(t, 0, possibility, 1e-9)
+	})
+}
+
+func TestPredictionBoardDiscountFail(t *testing.T) {
+	t.Run("[割引率: 範囲外]", func(t *testing.T) {
+		for _, rate := range []float64{0, -0.5, 1.5} {
+			board, err := NewPredictionBoardWithDiscountRate(rate)
+			assert.Nil(t, board)
+			assert.ErrorIs(t, err, shared.ErrInvalidDiscountRate)
+		}
+	})
+	t.Run("[AdvanceTurn: ターンを戻す]", func(t *testing.T) {
+		board := NewPredictionBoard()
+		assert.NoError(t, board.AdvanceTurn(3))
+		assert.ErrorIs(t, board.AdvanceTurn(2), shared.ErrInvalidTurn)
+		assert.Equal(t, 3, board.GetCurrentTurn())
+	})
+}
+
+func TestPredictionBoardMarkEnemy(t *testing.T) {
+	testList := []struct {
+		name     string
+		mark     func(board *PredictionBoard) error
+		expected [boardSize][boardSize]float64
+	}{
+		{
+			"[MarkEnemyAttack: 攻撃座標が0になり周囲8マスに1/8ずつ加算]",
+			func(board *PredictionBoard) error { return board.MarkEnemyAttack(&Position{3, 3}) },
+			[boardSize][boardSize]float64{
+				{p, p, p, p, p},
+				{p, p + 1.0/8, p + 1.0/8, p + 1.0/8, p},
+				{p, p + 1.0/8, 0, p + 1.0/8, p},
+				{p, p + 1.0/8, p + 1.0/8, p + 1.0/8, p},
+				{p, p, p, p, p},
+			},
+		},
+		{
+			"[MarkEnemyMove: 命中したマスが移動方向へずれる]",
+			func(board *PredictionBoard) error {
+				if err := board.MarkHit(&Position{1, 1}); err != nil {
+					return err
+				}
+				return board.MarkEnemyMove(shared.East, 2)
+			},
+			[boardSize][boardSize]float64{
+				{p, p, 1, p, p},
+				{p, p, p, p, p},
+				{p, p, p, p, p},
+				{p, p, p, p, p},
+				{p, p, p, p, p},
+			},
+		},
+		{
+			"[MarkEnemyMove: 盤面の外から入るマスは事前確率]",
+			func(board *PredictionBoard) error {
+				if err := board.MarkMiss(&Position{1, 1}); err != nil {
+					return err
+				}
+				return board.MarkEnemyMove(shared.South, 1)
+			},
+			[boardSize][boardSize]float64{
+				{p, p, p, p, p},
+				{0, 0, p, p, p},
+				{0, 0, p, p, p},
+				{p, p, p, p, p},
+				{p, p, p, p, p},
+			},
+		},
+		{
+			"[MarkEnemyMove: 撃沈したマスはずれず, そこから入るマスは事前確率]",
+			func(board *PredictionBoard) error {
+				if err := board.MarkSunk(&Position{5, 5}); err != nil {
+					return err
+				}
+				return board.MarkEnemyMove(shared.North, 1)
+			},
+			[boardSize][boardSize]float64{
+				{p, p, p, p, p},
+				{p, p, p, p, p},
+				{p, p, p, p, p},
+				{p, p, p, p, p},
+				{p, p, p, p, 0},
+			},
+		},
+	}
+	for _, tl := range testList {
+		t.Run(tl.name, func(t *testing.T) {
+			board := NewPredictionBoard()
+			assert.NoError(t, tl.mark(board))
+			grid := board.Grid()
+			for y := range tl.expected {
+				assert.InDeltaSlice(t, tl.expected[y][:], grid[y], 1e-9, "y=%d", y+shared.MinPosition)
+			}
+		})
+	}
+
+	t.Run("[MarkEnemyMove: 不正な移動]", func(t *testing.T) {
+		board := NewPredictionBoard()
+		assert.ErrorIs(t, board.MarkEnemyMove(shared.East, 3), shared.ErrInvalidMoveDistance)
+		assert.ErrorIs(t, board.MarkEnemyMove(shared.DirectionUnknown, 1), shared.ErrInvalidAction)
+		assert.ErrorIs(t, board.MarkEnemyAttack(&Position{0, 1}), shared.ErrOutOfBoard)
+	})
+}
+
+func TestPredictionBoardApplyTurnLog(t *testing.T) {
+	newLog := func(t *testing.T, turn int, playerId shared.PlayerId, actionType shared.ActionType, target *Position, direction shared.Direction, distance int, attackReport shared.AttackReportType, errorCode shared.ErrorCode) *TurnLog {
+		t.Helper()
+		log, err := NewTurnLog("g1", turn, playerId, "", actionType, target, direction, distance, attackReport, shared.MoveReportUnknown, errorCode, time.Time{})
+		assert.NoError(t, err)
+		return log
+	}
+	center := &Position{3, 3}
+	neighbor := &Position{2, 2}
+
+	testList := []struct {
+		name     string
+		log      func(t *testing.T) *TurnLog
+		position *Position
+		expected float64
+	}{
+		{
+			"[ApplyTurnLog: 自分の攻撃が異常なし]",
+			func(t *testing.T) *TurnLog {
+				return newLog(t, 1, "p1", shared.Attack, center, shared.DirectionUnknown, 0, shared.Miss, shared.ErrorNone)
+			},
+			neighbor,
+			0,
+		},
+		{
+			"[ApplyTurnLog: 自分の攻撃が波高し]",
+			func(t *testing.T) *TurnLog {
+				return newLog(t, 1, "p1", shared.Attack, center, shared.DirectionUnknown, 0, shared.WaveHigh, shared.ErrorNone)
+			},
+			neighbor,
+			p + 1.0/8,
+		},
+		{
+			"[ApplyTurnLog: 自分の攻撃が命中]",
+			func(t *testing.T) *TurnLog {
+				return newLog(t, 1, "p1", shared.Attack, center, shared.DirectionUnknown, 0, shared.Hit, shared.ErrorNone)
+			},
+			center,
+			1,
+		},
+		{
+			"[ApplyTurnLog: 自分の攻撃で撃沈]",
+			func(t *testing.T) *TurnLog {
+				return newLog(t, 1, "p1", shared.Attack, center, shared.DirectionUnknown, 0, shared.HitAndSunk, shared.ErrorNone)
+			},
+			center,
+			0,
+		},
+		{
+			"[ApplyTurnLog: 相手の攻撃]",
+			func(t *testing.T) *TurnLog {
+				return newLog(t, 1, "p2", shared.Attack, center, shared.DirectionUnknown, 0, shared.Miss, shared.ErrorNone)
+			},
+			neighbor,
+			p + 1.0/8,
+		},
+		{
+			"[ApplyTurnLog: 拒否された相手の攻撃は無視]",
+			func(t *testing.T) *TurnLog {
+				return newLog(t, 1, "p2", shared.Attack, center, shared.DirectionUnknown, 0, shared.InvalidAttack, shared.InvalidTarget)
+			},
+			center,
+			p,
+		},
+		{
+			"[ApplyTurnLog: 自分の移動は無視]",
+			func(t *testing.T) *TurnLog {
+				return newLog(t, 1, "p1", shared.Move, nil, shared.East, 1, shared.AttackReportUnknown, shared.ErrorNone)
+			},
+			center,
+			p,
+		},
+	}
+	for _, tl := range testList {
+		t.Run(tl.name, func(t *testing.T) {
+			board := NewPredictionBoard()
+			assert.NoError(t, board.ApplyTurnLog("p1", tl.log(t)))
+			assert.Equal(t, 1, board.GetCurrentTurn())
+			possibility, err := board.Possibility(tl.position)
+			assert.NoError(t, err)
+			assert.InDelta(t, tl.expected, possibility, 1e-9)
+		})
+	}
+
+	t.Run("[ApplyTurnLog: 相手の移動で自分の攻撃結果がずれる]", func(t *testing.T) {
+		board := NewPredictionBoard()
+		assert.NoError(t, board.ApplyTurnLog("p1", newLog(t, 1, "p1", shared.Attack, center, shared.DirectionUnknown, 0, shared.Hit, shared.ErrorNone)))
+		assert.NoError(t, board.ApplyTurnLog("p1", newLog(t, 2, "p2", shared.Move, nil, shared.West, 1, shared.AttackReportUnknown, shared.ErrorNone)))
+		possibility, err := board.Possibility(&Position{2, 3})
+		assert.NoError(t, err)
+		assert.InDelta(t, 1, possibility, 1e-9)
+	})
+
+	t.Run("[ApplyTurnLog: 古いターンの記録]", func(t *testing.T) {
+		board := NewPredictionBoard()
+		assert.NoError(t, board.AdvanceTurn(5))
+		err := board.ApplyTurnLog("p1", newLog(t, 4, "p2", shared.Attack, center, shared.DirectionUnknown, 0, shared.Miss, shared.ErrorNone))
+		assert.ErrorIs(t, err, shared.ErrInvalidTurn)
+		assert.ErrorIs(t, board.ApplyTurnLog("p1", nil), shared.ErrTurnLogIsNil)
+	})
+}
+
+func TestRestorePredictionBoard(t *testing.T) {
+	board, err := NewPredictionBoardWithDiscountRate(0.7)
+	assert.NoError(t, err)
+	assert.NoError(t, board.AdvanceTurn(1))
+	assert.NoError(t, board.MarkHighWave(&Position{3, 3}))
+	assert.NoError(t, board.MarkMiss(&Position{1, 1}))
+	assert.NoError(t, board.AdvanceTurn(2))
+	assert.NoError(t, board.MarkEnemyMove(shared.South, 1))
+	assert.NoError(t, board.AdvanceTurn(4))
+	assert.NoError(t, board.MarkHit(&Position{4, 2}))
+	assert.NoError(t, board.MarkEnemyAttack(&Position{2, 5}))
+	assert.NoError(t, board.AdvanceTurn(9))
+
+	evidences := board.Evidences()
+	assert.Len(t, evidences, 5)
+	assert.Equal(t, PredictionEvidence{Kind: "enemyMove", Direction: shared.South, Distance: 1, Turn: 2}, evidences[2])
+	assert.Equal(t, PredictionEvidence{Kind: "hit", Position: &Position{4, 2}, Direction: shared.DirectionUnknown, Turn: 4}, evidences[3])
+
+	restored, err := RestorePredictionBoard(board.GetDiscountRate(), board.GetCurrentTurn(), evidences)
+	assert.NoError(t, err)
+	assert.Equal(t, board.GetCurrentTurn(), restored.GetCurrentTurn())
+	assert.Equal(t, board.Grid(), restored.Grid())
+
+	t.Run("[RestorePredictionBoard: 不明な情報]", func(t *testing.T) {
+		_, err := RestorePredictionBoard(0.7, 1, []PredictionEvidence{{Kind: "rumor", Position: &Position{1, 1}}})
+		assert.ErrorIs(t, err, shared.ErrInvalidPredictionEvidence)
+	})
+
+	t.Run("[RestorePredictionBoard: ターンが戻る]", func(t *testing.T) {
+		_, err := RestorePredictionBoard(0.7, 1, []PredictionEvidence{
+			{Kind: "miss", Position: &Position{1, 1}, Turn: 3},
+			{Kind: "miss", Position: &Position{2, 1}, Turn: 2},
+		})
+		assert.ErrorIs(t, err, shared.ErrInvalidPredictionEvidence)
+	})
+
+	t.Run("[RestorePredictionBoard: 現在のターンが情報より前]", func(t *testing.T) {
+		_, err := RestorePredictionBoard(0.7, 1, []PredictionEvidence{{Kind: "miss", Position: &Position{1, 1}, Turn: 3}})
+		assert.ErrorIs(t, err, shared.ErrInvalidTurn)
+	})
+}
+
+// 確率は情報を加えたときとターンを進めたときに計算するため, 参照だけなら複数の goroutine で同じ存在確率マップを共有できる.
+func TestPredictionBoardConcurrentReads(t *testing.T) {
+	board := NewPredictionBoard()
+	assert.NoError(t, board.MarkHighWave(&Position{3, 3}))
+	assert.NoError(t, board.AdvanceTurn(4))
+	expected := board.Grid()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			possibility, err := board.Possibility(&Position{2, 2})
+			assert.NoError(t, err)
+			assert.Equal(t, expected[2-shared.MinPosition][2-shared.MinPosition], possibility)
+			assert.Equal(t, expected, board.Grid())
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,86 @@
+package domain
+
+import (
+	shared "backend/domain/shared"
+)
+
+// PredictionEvidence は存在確率マップに加えた1件の情報. 保存先へ書き出し, RestorePredictionBoard で戻すために使う.
+// Kind は "miss", "highWave", "hit", "sunk", "enemyAttack", "enemyMove" のいずれかで,
+// "enemyMove" の場合は Position の代わりに Direction と Distance を使う.
+type PredictionEvidence struct {
+	Kind      string
+	Position  *Position
+	Direction shared.Direction
+	Distance  int
+	Turn      int
+}
+
+var evidenceKindNames = map[evidenceKind]string{
+	evidenceMiss:        "miss",
+	evidenceHighWave:    "highWave",
+	evidenceHit:         "hit",
+	evidenceSunk:        "sunk",
+	evidenceEnemyAttack: "enemyAttack",
+	evidenceEnemyMove:   "enemyMove",
+}
+
+// Evidences は加えた情報を古い順に返す.
+func (board *PredictionBoard) Evidences() []PredictionEvidence {
+	if board == nil {
+		return nil
+	}
+	evidences := make([]PredictionEvidence, 0, len(board.evidences))
+	for _, evidence := range board.evidences {
+		exported := PredictionEvidence{
+			Kind:      evidenceKindNames[evidence.kind],
+			Direction: shared.DirectionUnknown,
+			Turn:      evidence.turn,
+		}
+		if evidence.kind == evidenceEnemyMove {
+			exported.Direction = evidence.direction
+			exported.Distance = evidence.distance
+		} else {
+			position := evidence.position
+			exported.Position = &position
+		}
+		evidences = append(evidences, exported)
+	}
+	return evidences
+}
+
+// RestorePredictionBoard は割引率と現在のターン, 加えた情報から存在確率マップを復元する.
+// 情報は得たターンの順に並んでいる必要があり, 同じ順で加え直すため確率も元のマップと一致する.
+func RestorePredictionBoard(discountRate float64, currentTurn int, evidences []PredictionEvidence) (*PredictionBoard, error) {
+	board, err := NewPredictionBoardWithDiscountRate(discountRate)
+	if err != nil {
+		return nil, err
+	}
+	for _, evidence := range evidences {
+		if err := board.AdvanceTurn(evidence.Turn); err != nil {
+			return nil, shared.ErrInvalidPredictionEvidence
+		}
+		switch evidence.Kind {
+		case "miss":
+			err = board.MarkMiss(evidence.Position)
+		case "highWave":
+			err = board.MarkHighWave(evidence.Position)
+		case "hit":
+			err = board.MarkHit(evidence.Position)
+		case "sunk":
+			err = board.MarkSunk(evidence.Position)
+		case "enemyAttack":
+			err = board.MarkEnemyAttack(evidence.Position)
+		case "enemyMove":
+			err = board.MarkEnemyMove(evidence.Direction, evidence.Distance)
+		default:
+			err = shared.ErrInvalidPredictionEvidence
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := board.AdvanceTurn(currentTurn); err != nil {
+		return nil, err
+	}
+	return board, nil
+}
@@ -8,4 +8,22 @@ const (
 	Hit
 	HitAndSunk
 	WaveHigh
+	AttackReportUnknown
 )
+
+func (a AttackReportType) String() string {
+	switch a {
+	case InvalidAttack:
+		return "invalidAttack"
+	case Miss:
+		return "miss"
+	case Hit:
+		return "hit"
+	case HitAndSunk:
+		return "hitAndSunk"
+	case WaveHigh:
+		return "waveHigh"
+	default:
+		return "unknown"
+	}
+}
@@ -1,6 +1,11 @@
 package shared
 
+import "time"
+
 const MinPosition = 1
 const MaxPosition = 5
 const MinDistance = 1
 const MaxDistance = 2
+const SubmarineCount = 4
+const InitialHp = 3
+const MatchDuration = 20 * time.Minute
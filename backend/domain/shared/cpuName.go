@@ -0,0 +1,14 @@
+package shared
+
+type CpuName string
+
+const (
+	CpuRandom        CpuName = "random"
+	CpuHeuristic     CpuName = "heuristic"
+	CpuProbabilistic CpuName = "probabilistic"
+	CpuMcts          CpuName = "mcts"
+)
+
+func (name CpuName) String() string {
+	return string(name)
+}
@@ -0,0 +1,16 @@
+package shared
+
+// CpuRuleTier は CPU が行動を決めた規則.
+type CpuRuleTier string
+
+const (
+	CpuTierAllyAtRisk   CpuRuleTier = "allyAtRisk"
+	CpuTierBestAttack   CpuRuleTier = "bestAttack"
+	CpuTierFallbackMove CpuRuleTier = "fallbackMove"
+	CpuTierRandom       CpuRuleTier = "random"
+	CpuTierSearch       CpuRuleTier = "search"
+)
+
+func (tier CpuRuleTier) String() string {
+	return string(tier)
+}
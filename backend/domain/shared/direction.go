@@ -24,3 +24,19 @@ func (d Direction) String() string {
 		return "unknown"
 	}
 }
+
+// Delta は方角ごとの1マスあたりの移動量を返す. y軸は南向きを正とする.
+func (d Direction) Delta() (int, int) {
+	switch d {
+	case North:
+		return 0, -1
+	case East:
+		return 1, 0
+	case South:
+		return 0, 1
+	case West:
+		return -1, 0
+	default:
+		return 0, 0
+	}
+}
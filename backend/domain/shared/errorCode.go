@@ -1,5 +1,7 @@
 package shared
 
+import "errors"
+
 type ErrorCode int
 
 const (
@@ -8,4 +10,40 @@ const (
 	InvalidTarget
 	InvalidMoveDistance
 	OutOfBoard
+	ErrorNone
 )
+
+func (e ErrorCode) String() string {
+	switch e {
+	case InvalidTurn:
+		return "invalidTurn"
+	case InvalidAction:
+		return "invalidAction"
+	case InvalidTarget:
+		return "invalidTarget"
+	case InvalidMoveDistance:
+		return "invalidMoveDistance"
+	case OutOfBoard:
+		return "outOfBoard"
+	default:
+		return "none"
+	}
+}
+
+// ToErrorCode はドメインのエラーをAPIで返すErrorCodeに変換する.
+func ToErrorCode(err error) ErrorCode {
+	switch {
+	case err == nil:
+		return ErrorNone
+	case errors.Is(err, ErrInvalidTurn):
+		return InvalidTurn
+	case errors.Is(err, ErrInvalidTarget), errors.Is(err, ErrTargetOutOfRange), errors.Is(err, ErrTargetIsAllySubmarine):
+		return InvalidTarget
+	case errors.Is(err, ErrInvalidMoveDistance):
+		return InvalidMoveDistance
+	case errors.Is(err, ErrOutOfBoard):
+		return OutOfBoard
+	default:
+		return InvalidAction
+	}
+}
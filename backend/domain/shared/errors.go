@@ -21,4 +21,44 @@ var (
 	ErrActionCommandInvalidParamCombination = errors.New("Error[ActionCommand.go]: actionTypeと他のパラメータ間で矛盾が発生しています．")
 	ErrActionCommandIsNil                   = errors.New("Error[ActionCommand.go]: ActionCommandがnilです．")
 	ErrInvalidActionType                    = errors.New("Error[ActionType.go]: ActionTypeが不正です．")
+	ErrBoardIsNil                           = errors.New("Error[Board.go]: Boardがnilです．")
+	ErrSubmarineIsNil                       = errors.New("Error[Board.go]: Submarineがnilです．")
+	ErrSubmarineNotFound                    = errors.New("Error[Board.go]: 潜水艦が見つかりません．")
+	ErrDuplicateSubmarineId                 = errors.New("Error[Board.go]: 同じidの潜水艦がすでに配置されています．")
+	ErrPositionAlreadyOccupied              = errors.New("Error[Board.go]: その座標にはすでに潜水艦が配置されています．")
+	ErrSubmarineLimitExceeded               = errors.New("Error[Board.go]: 配置できる潜水艦の数を超えています．")
+	ErrMoveBlocked                          = errors.New("Error[Board.go]: 移動先または移動経路が塞がれています．")
+	ErrGameIsNil                            = errors.New("Error[Game.go]: Gameがnilです．")
+	ErrInvalidGameId                        = errors.New("Error[Game.go]: gameIdが不正です．")
+	ErrGameAlreadyStarted                   = errors.New("Error[Game.go]: ゲームはすでに開始されています．")
+	ErrGameNotInProgress                    = errors.New("Error[Game.go]: ゲームが進行中ではありません．")
+	ErrClockIsNil                           = errors.New("Error[Game.go]: Clockがnilです．")
+	ErrInvalidDuration                      = errors.New("Error[Game.go]: 時間の設定が不正です．")
+	ErrFleetIncomplete                      = errors.New("Error[Game.go]: 潜水艦の配置が完了していません．")
+	ErrSubmarineNotAvailable                = errors.New("Error[ActionCommand.go]: 指定された潜水艦が存在しないか撃沈されています．")
+	ErrTurnLogIsNil                         = errors.New("Error[TurnLog.go]: TurnLogがnilです．")
+	ErrInvalidPageRange                     = errors.New("Error[TurnLogRepository.go]: 取得範囲の指定が不正です．")
+	ErrPredictionBoardIsNil                 = errors.New("Error[PredictionBoard.go]: PredictionBoardがnilです．")
+	ErrInvalidDiscountRate                  = errors.New("Error[PredictionBoard.go]: 割引率が不正です．")
+	ErrNoCandidateCell                      = errors.New("Error[PredictionBoard.go]: 候補となるマスがありません．")
+	ErrNoConsistentFleet                    = errors.New("Error[ExactPredictionBoard.go]: 行動記録と矛盾しない敵艦隊の配置がありません．")
+	ErrPredictionBoardNotFound              = errors.New("Error[PredictionRepository.go]: 存在確率マップが見つかりません．")
+	ErrInvalidCpuName                       = errors.New("Error[CpuProfile.go]: CPUの名前が不正です．")
+	ErrInvalidCpuProfile                    = errors.New("Error[CpuProfile.go]: CPUのパラメータが不正です．")
+	ErrCpuProfileIsNil                      = errors.New("Error[CpuProfile.go]: CpuProfileがnilです．")
+	ErrInvalidPlacement                     = errors.New("Error[GameService.go]: 潜水艦の配置が不正です．")
+	ErrStaleTurn                            = errors.New("Error[GameService.go]: 対戦は指定したターンから進んでいます．")
+	ErrGameNotFound                         = errors.New("Error[GameRepository.go]: ゲームが見つかりません．")
+	ErrConcurrentModification               = errors.New("Error[GameRepository.go]: ゲームが他の操作によって更新されています．")
+	ErrTransactionClosed                    = errors.New("Error[UnitOfWork.go]: トランザクションは既にコミットされています．")
+	ErrInvalidGameVersion                   = errors.New("Error[Game.go]: ゲームのバージョンが不正です．")
+	ErrInvalidGameStatus                    = errors.New("Error[Game.go]: ゲームの状態が不正です．")
+	ErrInvalidPredictionEvidence            = errors.New("Error[PredictionBoard.go]: 存在確率マップに加えた情報が不正です．")
+	ErrInvalidUpstashConfig                 = errors.New("Error[UpstashClient.go]: Upstashの接続設定が不正です．")
+	ErrUpstashRequestFailed                 = errors.New("Error[UpstashClient.go]: Upstashへのリクエストに失敗しました．")
+	ErrUpstashCommandFailed                 = errors.New("Error[UpstashClient.go]: Upstashがコマンドの実行に失敗しました．")
+	ErrUnexpectedUpstashReply               = errors.New("Error[UpstashClient.go]: Upstashの応答の形式が不正です．")
+	ErrInvalidStorageBackend                = errors.New("Error[Storage.go]: 保存先の指定が不正です．")
+	ErrTargetOutOfRange                     = errors.New("Error[AttackResolver.go]: 攻撃可能な範囲の外です．")
+	ErrTargetIsAllySubmarine                = errors.New("Error[AttackResolver.go]: 自軍の潜水艦へは攻撃できません．")
 )
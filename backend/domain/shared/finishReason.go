@@ -0,0 +1,23 @@
+package shared
+
+type FinishReason int
+
+const (
+	FleetDestroyed = iota
+	TimeUp
+	TurnTimeout
+	FinishReasonUnknown
+)
+
+func (f FinishReason) String() string {
+	switch f {
+	case FleetDestroyed:
+		return "fleetDestroyed"
+	case TimeUp:
+		return "timeUp"
+	case TurnTimeout:
+		return "turnTimeout"
+	default:
+		return "unknown"
+	}
+}
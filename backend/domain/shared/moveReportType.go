@@ -5,4 +5,16 @@ type MoveReportType int
 const (
 	MoveSuccess = iota
 	MoveBlocked
+	MoveReportUnknown
 )
+
+func (m MoveReportType) String() string {
+	switch m {
+	case MoveSuccess:
+		return "moveSuccess"
+	case MoveBlocked:
+		return "moveBlocked"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,19 @@
+package shared
+
+type TurnTimeoutPolicy int
+
+const (
+	TurnTimeoutPass = iota
+	TurnTimeoutForfeit
+)
+
+func (p TurnTimeoutPolicy) String() string {
+	switch p {
+	case TurnTimeoutPass:
+		return "pass"
+	case TurnTimeoutForfeit:
+		return "forfeit"
+	default:
+		return "unknown"
+	}
+}
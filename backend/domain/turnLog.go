@@ -0,0 +1,137 @@
+package domain
+
+import (
+	shared "backend/domain/shared"
+	"strings"
+	"time"
+)
+
+// TurnLog は1回の行動の記録. 不正として拒否された行動も errorCode 付きで記録する.
+type TurnLog struct {
+	gameId       shared.GameId
+	turn         int
+	playerId     shared.PlayerId
+	submarineId  shared.SubmarineId
+	actionType   shared.ActionType
+	target       *Position
+	direction    shared.Direction
+	distance     int
+	attackReport shared.AttackReportType
+	moveReport   shared.MoveReportType
+	errorCode    shared.ErrorCode
+	createdAt    time.Time
+}
+
+func NewTurnLog(
+	gameId shared.GameId,
+	turn int,
+	playerId shared.PlayerId,
+	submarineId shared.SubmarineId,
+	actionType shared.ActionType,
+	target *Position,
+	direction shared.Direction,
+	distance int,
+	attackReport shared.AttackReportType,
+	moveReport shared.MoveReportType,
+	errorCode shared.ErrorCode,
+	createdAt time.Time,
+) (*TurnLog, error) {
+	if strings.TrimSpace(gameId.String()) == "" {
+		return nil, shared.ErrInvalidGameId
+	}
+	if strings.TrimSpace(playerId.String()) == "" {
+		return nil, shared.ErrInvalidPlayerID
+	}
+	if turn < 0 {
+		return nil, shared.ErrInvalidTurn
+	}
+	return &TurnLog{
+		gameId:       gameId,
+		turn:         turn,
+		playerId:     playerId,
+		submarineId:  submarineId,
+		actionType:   actionType,
+		target:       target,
+		direction:    direction,
+		distance:     distance,
+		attackReport: attackReport,
+		moveReport:   moveReport,
+		errorCode:    errorCode,
+		createdAt:    createdAt,
+	}, nil
+}
+
+// newTurnLogFromResult は command と result から記録を作る. 移動では command が潜水艦を指定していなくても, 実際に動いた潜水艦を記録する.
+func newTurnLogFromResult(gameId shared.GameId, turn int, command *ActionCommand, result *TurnResult, createdAt time.Time) *TurnLog {
+	submarineId := command.submarineId
+	if result.moverId != "" {
+		submarineId = result.moverId
+	}
+	return &TurnLog{
+		gameId:       gameId,
+		turn:         turn,
+		playerId:     command.playerId,
+		submarineId:  submarineId,
+		actionType:   command.actionType,
+		target:       command.target,
+		direction:    command.direction,
+		distance:     command.distance,
+		attackReport: result.AttackReport,
+		moveReport:   result.MoveReport,
+		errorCode:    result.errorCode,
+		createdAt:    createdAt,
+	}
+}
+
+// IsRejected は行動が不正として拒否された記録かを返す.
+func (log *TurnLog) IsRejected() bool {
+	return log.errorCode != shared.ErrorNone
+}
+
+func (log *TurnLog) GetGameId() shared.GameId {
+	return log.gameId
+}
+
+func (log *TurnLog) GetTurn() int {
+	return log.turn
+}
+
+func (log *TurnLog) GetPlayerId() shared.PlayerId {
+	return log.playerId
+}
+
+func (log *TurnLog) GetSubmarineId() shared.SubmarineId {
+	return log.submarineId
+}
+
+func (log *TurnLog) GetActionType() shared.ActionType {
+	return log.actionType
+}
+
+func (log *TurnLog) GetTarget() *Position {
+	return log.target
+}
+
+func (log *TurnLog) GetDirection() shared.Direction {
+	return log.direction
+}
+
+func (log *TurnLog) GetDistance() int {
+	return log.distance
+}
+
+func (log *TurnLog) GetAttackReport() shared.AttackReportType {
+	return log.attackReport
+}
+
+func (log *TurnLog) GetMoveReport() shared.MoveReportType {
+	return log.moveReport
+}
+
+func (log *TurnLog) GetErrorCode() shared.ErrorCode {
+	return log.errorCode
+}
+
+func (log *TurnLog) GetCreatedAt() time.Time {
+	return log.createdAt
+}
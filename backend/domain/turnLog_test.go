@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	shared "backend/domain/shared"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTurnLogSuccess(t *testing.T) {
+	createdAt := time.Date(2026, 4, 1, 10, 0, 0, 0, time.UTC)
+	target := &Position{2, 3}
+	log, err := NewTurnLog("g1", 3, "p1", "", shared.Attack, target, shared.DirectionUnknown, 0, shared.WaveHigh, shared.MoveReportUnknown, shared.ErrorNone, createdAt)
+	assert.NoError(t, err)
+	assert.Equal(t, shared.GameId("g1"), log.GetGameId())
+	assert.Equal(t, 3, log.GetTurn())
+	assert.Equal(t, shared.PlayerId("p1"), log.GetPlayerId())
+	assert.Equal(t, shared.ActionType(shared.Attack), log.GetActionType())
+	assert.Equal(t, target, log.GetTarget())
+	assert.Equal(t, shared.AttackReportType(shared.WaveHigh), log.GetAttackReport())
+	assert.Equal(t, createdAt, log.GetCreatedAt())
+	assert.False(t, log.IsRejected())
+}
+
+func TestNewTurnLogFail(t *testing.T) {
+	testList := []struct {
+		name        string
+		gameId      shared.GameId
+		turn        int
+		playerId    shared.PlayerId
+		expectedErr error
+	}{
+		{"[NewTurnLog: gameIdが空]", "", 1, "p1", shared.ErrInvalidGameId},
+		{"[NewTurnLog: playerIdが空]", "g1", 1, "", shared.ErrInvalidPlayerID},
+		{"[NewTurnLog: turnが負]", "g1", -1, "p1", shared.ErrInvalidTurn},
+	}
+	for _, tl := range testList {
+		t.Run(tl.name, func(t *testing.T) {
+			log, err := NewTurnLog(tl.gameId, tl.turn, tl.playerId, "", shared.Move, nil, shared.North, 1, shared.AttackReportUnknown, shared.MoveSuccess, shared.ErrorNone, time.Time{})
+			assert.Nil(t, log)
+			assert.ErrorIs(t, err, tl.expectedErr)
+		})
+	}
+}
+
+func TestGameEmitsTurnLogs(t *testing.T) {
+	game, clock := newTimedTestGame(t, 0, shared.TurnTimeoutPass)
+
+	_, err := game.Apply(newTestAttack(t, "p1", 4, 4))
+	assert.NoError(t, err)
+	clock.Advance(time.Second)
+	_, err = game.Apply(newTestAttack(t, "p1", 4, 4))
+	assert.ErrorIs(t, err, shared.ErrInvalidTurn)
+	clock.Advance(time.Second)
+	result, err := game.Apply(newTestMove(t, "p2", shared.North, 1))
+	assert.NoError(t, err)
+
+	logs := game.PullTurnLogs()
+	assert.Len(t, logs, 3)
+	assert.Empty(t, game.PullTurnLogs())
+	assert.Same(t, result.GetTurnLog(), logs[2])
+	// 移動する潜水艦を指定していなくても, 実際に動いた潜水艦が記録される.
+	mover, err := game.GetBoard().GetSubmarine(result.GetMoverId())
+	if assert.NoError(t, err) {
+		assert.Equal(t, shared.PlayerId("p2"), mover.GetOwnerId())
+	}
+
+	testList := []struct {
+		name         string
+		log          *TurnLog
+		turn         int
+		playerId     shared.PlayerId
+		submarineId  shared.SubmarineId
+		actionType   shared.ActionType
+		attackReport shared.AttackReportType
+		moveReport   shared.MoveReportType
+		errorCode    shared.ErrorCode
+		createdAfter time.Duration
+	}{
+		{"[TurnLog: 命中した攻撃]", logs[0], 1, "p1", "", shared.Attack, shared.Hit, shared.MoveReportUnknown, shared.ErrorNone, 0},
+		{"[TurnLog: 手番ではない攻撃]", logs[1], 2, "p1", "", shared.Attack, shared.InvalidAttack, shared.MoveReportUnknown, shared.InvalidTurn, time.Second},
+		{"[TurnLog: 移動]", logs[2], 2, "p2", result.GetMoverId(), shared.Move, shared.AttackReportUnknown, shared.MoveSuccess, shared.ErrorNone, 2 * time.Second},
+	}
+	for _, tl := range testList {
+		t.Run(tl.name, func(t *testing.T) {
+			assert.Equal(t, shared.GameId("g1"), tl.log.GetGameId())
+			assert.Equal(t, tl.turn, tl.log.GetTurn())
+			assert.Equal(t, tl.playerId, tl.log.GetPlayerId())
+			assert.Equal(t, tl.submarineId, tl.log.GetSubmarineId())
+			assert.Equal(t, tl.actionType, tl.log.GetActionType())
+			assert.Equal(t, tl.attackReport, tl.log.GetAttackReport())
+			assert.Equal(t, tl.moveReport, tl.log.GetMoveReport())
+			assert.Equal(t, tl.errorCode, tl.log.GetErrorCode())
+			assert.Equal(t, tl.errorCode != shared.ErrorNone, tl.log.IsRejected())
+			assert.Equal(t, game.GetStartedAt().Add(tl.createdAfter), tl.log.GetCreatedAt())
+		})
+	}
+}
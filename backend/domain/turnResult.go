@@ -11,6 +11,8 @@ type TurnResult struct {
 	HitCount     int
 	sunkCount    int
 	nextPlayerId shared.PlayerId
+	moverId      shared.SubmarineId
+	turnLog      *TurnLog
 }
 
 func (tr *TurnResult) GetErrorCode() shared.ErrorCode {
@@ -24,3 +26,12 @@ func (tr *TurnResult) GetSunkCount() int {
 func (tr *TurnResult) GetNextPlayerId() shared.PlayerId {
 	return tr.nextPlayerId
 }
+
+// GetMoverId は移動した潜水艦の id を返す. 移動以外の行動では空.
+func (tr *TurnResult) GetMoverId() shared.SubmarineId {
+	return tr.moverId
+}
+
+func (tr *TurnResult) GetTurnLog() *TurnLog {
+	return tr.turnLog
+}
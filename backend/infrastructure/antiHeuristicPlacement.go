@@ -0,0 +1,70 @@
+package infrastructure
+
+import (
+	"backend/domain"
+	"context"
+	"math/rand"
+	"sort"
+	"sync"
+)
+
+// AntiHeuristicPlacement は HeuristicCpuPlayer に見つかりにくい配置を返す.
+// HeuristicCpuPlayer は波高しや命中の周囲8マスの存在確率を上げるため, どの2隻も隣り合わない配置に限る.
+// また存在確率が同じマスでは上の行から攻撃するため, 下の行に寄った上位1/4の配置から乱数で1つ選ぶ.
+type AntiHeuristicPlacement struct {
+	mu         sync.Mutex
+	random     *rand.Rand
+	candidates [][]int
+}
+
+func NewAntiHeuristicPlacement(source rand.Source) *AntiHeuristicPlacement {
+	isolated := make([][]int, 0)
+	for _, cells := range placementCandidates() {
+		if !hasAdjacentCells(cells) {
+			isolated = append(isolated, cells)
+		}
+	}
+	sort.SliceStable(isolated, func(i, j int) bool {
+		return cellIndexSum(isolated[i]) > cellIndexSum(isolated[j])
+	})
+	threshold := cellIndexSum(isolated[(len(isolated)-1)/4])
+	candidates := make([][]int, 0)
+	for _, cells := range isolated {
+		if cellIndexSum(cells) >= threshold {
+			candidates = append(candidates, cells)
+		}
+	}
+	return &AntiHeuristicPlacement{
+		random:     rand.New(source),
+		candidates: candidates,
+	}
+}
+
+func (placement *AntiHeuristicPlacement) Place(ctx context.Context) ([]*domain.Position, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	placement.mu.Lock()
+	cells := placement.candidates[placement.random.Intn(len(placement.candidates))]
+	placement.mu.Unlock()
+	return cellsToPositions(cells)
+}
+
+func hasAdjacentCells(cells []int) bool {
+	for i := range cells {
+		for j := i + 1; j < len(cells); j++ {
+			if squaredCellDistance(cells[i], cells[j]) <= 2 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func cellIndexSum(cells []int) int {
+	sum := 0
+	for _, cell := range cells {
+		sum += cell
+	}
+	return sum
+}
@@ -0,0 +1,82 @@
+package infrastructure
+
+import (
+	"backend/domain"
+	"backend/domain/interfaces"
+	"backend/domain/shared"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// CpuFactory は profile のパラメータで CPU を作る.
+type CpuFactory func(profile *domain.CpuProfile) (interfaces.CPUPlayer, error)
+
+// CpuRegistry は CPU の名前と作り方の対応表. 複数のgoroutineから同時に利用できる.
+type CpuRegistry struct {
+	mu        sync.RWMutex
+	factories map[shared.CpuName]CpuFactory
+}
+
+func NewCpuRegistry() *CpuRegistry {
+	return &CpuRegistry{
+		factories: make(map[shared.CpuName]CpuFactory),
+	}
+}
+
+// NewDefaultCpuRegistry は random, heuristic, probabilistic, mcts を登録した CpuRegistry を作る.
+// newSource は乱数を使う CPU を作るたびに呼ばれる.
+func NewDefaultCpuRegistry(newSource func() rand.Source) *CpuRegistry {
+	registry := NewCpuRegistry()
+	registry.factories[shared.CpuRandom] = func(profile *domain.CpuProfile) (interfaces.CPUPlayer, error) {
+		return NewRandomCpuPlayer(newSource()), nil
+	}
+	registry.factories[shared.CpuHeuristic] = func(profile *domain.CpuProfile) (interfaces.CPUPlayer, error) {
+		return NewHeuristicCpuPlayerWithProfile(profile)
+	}
+	registry.factories[shared.CpuProbabilistic] = func(profile *domain.CpuProfile) (interfaces.CPUPlayer, error) {
+		return NewProbabilisticCpuPlayerWithProfile(profile)
+	}
+	registry.factories[shared.CpuMcts] = func(profile *domain.CpuProfile) (interfaces.CPUPlayer, error) {
+		return NewMctsCpuPlayerWithProfile(newSource(), profile)
+	}
+	return registry
+}
+
+// Register は name の CPU の作り方を登録する. 同じ名前がすでにあれば置き換える.
+func (registry *CpuRegistry) Register(name shared.CpuName, factory CpuFactory) error {
+	if strings.TrimSpace(name.String()) == "" || factory == nil {
+		return shared.ErrInvalidCpuName
+	}
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.factories[name] = factory
+	return nil
+}
+
+// New は profile の名前で登録された CPU を作る.
+func (registry *CpuRegistry) New(profile *domain.CpuProfile) (interfaces.CPUPlayer, error) {
+	if profile == nil {
+		return nil, shared.ErrCpuProfileIsNil
+	}
+	registry.mu.RLock()
+	factory, ok := registry.factories[profile.GetName()]
+	registry.mu.RUnlock()
+	if !ok {
+		return nil, shared.ErrInvalidCpuName
+	}
+	return factory(profile)
+}
+
+// Names は登録されている CPU の名前を辞書順に返す.
+func (registry *CpuRegistry) Names() []shared.CpuName {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	names := make([]shared.CpuName, 0, len(registry.factories))
+	for name := range registry.factories {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	return names
+}
@@ -0,0 +1,99 @@
+package infrastructure
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	"backend/domain"
+	"backend/domain/interfaces"
+	"backend/domain/shared"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestSource() rand.Source {
+	return rand.NewSource(1)
+}
+
+func TestDefaultCpuRegistry(t *testing.T) {
+	registry := NewDefaultCpuRegistry(newTestSource)
+	assert.Equal(t, []shared.CpuName{shared.CpuHeuristic, shared.CpuMcts, shared.CpuProbabilistic, shared.CpuRandom}, registry.Names())
+
+	testList := []struct {
+		name     string
+		cpuName  shared.CpuName
+		expected interfaces.CPUPlayer
+	}{
+		{name: "[CpuRegistry: random]", cpuName: shared.CpuRandom, expected: &RandomCpuPlayer{}},
+		{name: "[CpuRegistry: heuristic]", cpuName: shared.CpuHeuristic, expected: &HeuristicCpuPlayer{}},
+		{name: "[CpuRegistry: probabilistic]", cpuName: shared.CpuProbabilistic, expected: &ProbabilisticCpuPlayer{}},
+		{name: "[CpuRegistry: mcts]", cpuName: shared.CpuMcts, expected: &MctsCpuPlayer{}},
+	}
+	for _, tl := range testList {
+		t.Run(tl.name, func(t *testing.T) {
+			profile, err := domain.NewCpuProfile(tl.cpuName)
+			assert.NoError(t, err)
+			cpu, err := registry.New(profile)
+			assert.NoError(t, err)
+			assert.IsType(t, tl.expected, cpu)
+		})
+	}
+
+	t.Run("[CpuRegistry: パラメータを渡す]", func(t *testing.T) {
+		profile, err := domain.NewCpuProfileWithParams(shared.CpuHeuristic, 0.5, 0.6, 0.9)
+		assert.NoError(t, err)
+		cpu, err := registry.New(profile)
+		assert.NoError(t, err)
+		heuristic := cpu.(*HeuristicCpuPlayer)
+		assert.Equal(t, 0.5, heuristic.aggression)
+		assert.Equal(t, 0.6, heuristic.moveThreshold)
+		assert.Equal(t, 0.9, heuristic.discountRate)
+	})
+
+	t.Run("[CpuRegistry: mctsは思考時間に上限がある]", func(t *testing.T) {
+		profile, err := domain.NewCpuProfile(shared.CpuMcts)
+		assert.NoError(t, err)
+		cpu, err := registry.New(profile)
+		assert.NoError(t, err)
+		mcts := cpu.(*MctsCpuPlayer)
+		assert.Equal(t, defaultMctsIterations, mcts.config.Iterations)
+		assert.Equal(t, defaultMctsTimeBudget, mcts.config.TimeBudget)
+	})
+
+	t.Run("[CpuRegistry: mctsに探索量を渡す]", func(t *testing.T) {
+		profile, err := domain.NewCpuProfile(shared.CpuMcts)
+		assert.NoError(t, err)
+		profile, err = profile.WithSearchBudget(50, 200*time.Millisecond)
+		assert.NoError(t, err)
+		cpu, err := registry.New(profile)
+		assert.NoError(t, err)
+		mcts := cpu.(*MctsCpuPlayer)
+		assert.Equal(t, 50, mcts.config.Iterations)
+		assert.Equal(t, 200*time.Millisecond, mcts.config.TimeBudget)
+	})
+}
+
+func TestCpuRegistryRegister(t *testing.T) {
+	registry := NewCpuRegistry()
+	profile, err := domain.NewCpuProfile("cautious")
+	assert.NoError(t, err)
+	_, err = registry.New(profile)
+	assert.ErrorIs(t, err, shared.ErrInvalidCpuName)
+	_, err = registry.New(nil)
+	assert.ErrorIs(t, err, shared.ErrCpuProfileIsNil)
+
+	assert.ErrorIs(t, registry.Register("", func(*domain.CpuProfile) (interfaces.CPUPlayer, error) { return nil, nil }), shared.ErrInvalidCpuName)
+	assert.ErrorIs(t, registry.Register("cautious", nil), shared.ErrInvalidCpuName)
+	assert.NoError(t, registry.Register("cautious", func(profile *domain.CpuProfile) (interfaces.CPUPlayer, error) {
+		return NewHeuristicCpuPlayerWithProfile(profile)
+	}))
+	cpu, err := registry.New(profile)
+	assert.NoError(t, err)
+	game := newCpuTestGame(t, cornerPositions, centerPositions)
+	view, err := domain.NewGameView(game, "p1", nil)
+	assert.NoError(t, err)
+	_, err = cpu.Decide(context.Background(), view)
+	assert.NoError(t, err)
+}
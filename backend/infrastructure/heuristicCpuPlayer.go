@@ -0,0 +1,288 @@
+package infrastructure
+
+import (
+	"backend/domain"
+	"backend/domain/shared"
+	"context"
+	"fmt"
+	"sort"
+)
+
+// HeuristicCpuPlayer は 06_移動・行動アルゴリズム.md の 3 に従って行動を決める.
+// 相手から見た自艦の存在確率が moveThreshold 以上の潜水艦があれば移動し, なければ最も期待値の高いマスを攻撃する.
+type HeuristicCpuPlayer struct {
+	aggression    float64
+	moveThreshold float64
+	discountRate  float64
+}
+
+func NewHeuristicCpuPlayer() *HeuristicCpuPlayer {
+	return &HeuristicCpuPlayer{
+		aggression:    domain.DefaultAggression,
+		moveThreshold: domain.DefaultMoveThreshold,
+		discountRate:  domain.DefaultDiscountRate,
+	}
+}
+
+// NewHeuristicCpuPlayerWithProfile は profile のパラメータで HeuristicCpuPlayer を作る. 名前は確認しない.
+func NewHeuristicCpuPlayerWithProfile(profile *domain.CpuProfile) (*HeuristicCpuPlayer, error) {
+	if profile == nil {
+		return nil, shared.ErrCpuProfileIsNil
+	}
+	return &HeuristicCpuPlayer{
+		aggression:    profile.GetAggression(),
+		moveThreshold: profile.GetMoveThreshold(),
+		discountRate:  profile.GetDiscountRate(),
+	}, nil
+}
+
+func (cpu *HeuristicCpuPlayer) Decide(ctx context.Context, view *domain.GameView) (*domain.ActionCommand, error) {
+	command, _, err := cpu.DecideWithRationale(ctx, view)
+	return command, err
+}
+
+func (cpu *HeuristicCpuPlayer) DecideWithRationale(ctx context.Context, view *domain.GameView) (*domain.ActionCommand, *domain.CpuRationale, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	if view == nil {
+		return nil, nil, shared.ErrGameIsNil
+	}
+	if view.GetCurrentPlayerId() != view.GetViewerId() {
+		return nil, nil, shared.ErrInvalidTurn
+	}
+	prediction, err := view.Prediction(cpu.discountRate)
+	if err != nil {
+		return nil, nil, err
+	}
+	return decideByRules(view, prediction, prediction, cpu.aggression, cpu.moveThreshold, cpu.discountRate)
+}
+
+// decideByRules は 3.1~3.3 の規則で行動を決め, その理由を返す. 攻撃先は targeting の存在確率が最も高いマスとする.
+// 危険な潜水艦がいても, 攻撃先の存在確率が 1-aggression 以上であれば逃がさずに攻撃する.
+// 理由には targeting の存在確率マップを含める.
+func decideByRules(view *domain.GameView, prediction *domain.PredictionBoard, targeting domain.PossibilityMap, aggression float64, moveThreshold float64, discountRate float64) (*domain.ActionCommand, *domain.CpuRationale, error) {
+	threat, err := view.Threat(discountRate)
+	if err != nil {
+		return nil, nil, err
+	}
+	snapshot := targeting.Grid()
+	moves := view.LegalMoves()
+	targets := view.LegalAttackTargets()
+	target, possibility, err := bestTarget(targeting, targets)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 3.1 相手の推定度が閾値以上のマスにいる潜水艦を, 推定度の高い順に逃がす.
+	if target == nil || aggression <= 0 || possibility < 1-aggression {
+		for _, submarine := range submarinesAtRisk(view, threat, moveThreshold) {
+			escapes := filterMovesOf(moves, submarine.GetId())
+			if len(escapes) == 0 {
+				continue
+			}
+			move, err := chooseMove(view, prediction, escapes)
+			if err != nil {
+				return nil, nil, err
+			}
+			risk, err := threat.Possibility(submarine.GetPosition())
+			if err != nil {
+				return nil, nil, err
+			}
+			detail := fmt.Sprintf("ally %s at risk %.2f ≥ %.2f → move", submarine.GetId(), risk, moveThreshold)
+			return explainMove(view, prediction, move, escapes, shared.CpuTierAllyAtRisk, detail, snapshot)
+		}
+	}
+
+	// 3.3 攻撃可能箇所で最も期待値が高い箇所を攻撃する.
+	if target != nil {
+		candidates := make([]*domain.CpuCandidate, 0, len(targets))
+		var chosen *domain.ActionCommand
+		for _, candidate := range targets {
+			command, err := domain.NewActionCommand(view.GetViewerId(), shared.Attack, candidate, shared.DirectionUnknown, 0)
+			if err != nil {
+				return nil, nil, err
+			}
+			score, err := targeting.Possibility(candidate)
+			if err != nil {
+				return nil, nil, err
+			}
+			if candidate == target {
+				chosen = command
+			}
+			explained, err := domain.NewCpuCandidate(command, candidate, score)
+			if err != nil {
+				return nil, nil, err
+			}
+			candidates = append(candidates, explained)
+		}
+		detail := fmt.Sprintf("best attack possibility %.2f → attack", possibility)
+		return chosen, domain.NewCpuRationale(shared.CpuTierBestAttack, detail, candidates, snapshot), nil
+	}
+	if len(moves) == 0 {
+		return nil, nil, shared.ErrNoCandidateCell
+	}
+	move, err := chooseMove(view, prediction, moves)
+	if err != nil {
+		return nil, nil, err
+	}
+	return explainMove(view, prediction, move, moves, shared.CpuTierFallbackMove, "no attack target → move", snapshot)
+}
+
+// explainMove は moves の移動先を, 移動先での敵艦の存在確率を評価値として候補に並べる.
+func explainMove(view *domain.GameView, prediction *domain.PredictionBoard, move *domain.MoveOutcome, moves []*domain.MoveOutcome, tier shared.CpuRuleTier, detail string, snapshot [][]float64) (*domain.ActionCommand, *domain.CpuRationale, error) {
+	candidates := make([]*domain.CpuCandidate, 0, len(moves))
+	var chosen *domain.ActionCommand
+	for _, candidate := range moves {
+		command, err := newMoveCommand(view.GetViewerId(), candidate)
+		if err != nil {
+			return nil, nil, err
+		}
+		score, err := prediction.Possibility(candidate.GetDestination())
+		if err != nil {
+			return nil, nil, err
+		}
+		if candidate == move {
+			chosen = command
+		}
+		explained, err := domain.NewCpuCandidate(command, candidate.GetDestination(), score)
+		if err != nil {
+			return nil, nil, err
+		}
+		candidates = append(candidates, explained)
+	}
+	return chosen, domain.NewCpuRationale(tier, detail, candidates, snapshot), nil
+}
+
+// bestTarget は targets のうち存在確率が最も高いマスとその確率を返す. 同じ値の場合は先に並んでいるマスを優先する.
+// targets が空の場合は nil を返す.
+func bestTarget(targeting domain.PossibilityMap, targets []*domain.Position) (*domain.Position, float64, error) {
+	var best *domain.Position
+	bestPossibility := -1.0
+	for _, target := range targets {
+		possibility, err := targeting.Possibility(target)
+		if err != nil {
+			return nil, 0, err
+		}
+		if possibility > bestPossibility {
+			best = target
+			bestPossibility = possibility
+		}
+	}
+	return best, bestPossibility, nil
+}
+
+// submarinesAtRisk は相手から見た存在確率が threshold 以上のマスにいる潜水艦を, 確率の高い順に返す.
+func submarinesAtRisk(view *domain.GameView, threat *domain.PredictionBoard, threshold float64) []*domain.Submarine {
+	atRisk := make([]*domain.Submarine, 0)
+	risks := make(map[shared.SubmarineId]float64)
+	for _, submarine := range view.GetAllySubmarines() {
+		if submarine.IsSunk() {
+			continue
+		}
+		risk, err := threat.Possibility(submarine.GetPosition())
+		if err != nil || risk < threshold {
+			continue
+		}
+		risks[submarine.GetId()] = risk
+		atRisk = append(atRisk, submarine)
+	}
+	sort.SliceStable(atRisk, func(i, j int) bool {
+		return risks[atRisk[i].GetId()] > risks[atRisk[j].GetId()]
+	})
+	return atRisk
+}
+
+// chooseMove は 3.2 の優先順位で移動先を決める.
+// 1. 敵艦の存在確率が 0.5~0.75 のマス 2. 攻撃可能箇所が増えるマス 3. 2マス移動 4. 1マス移動
+func chooseMove(view *domain.GameView, prediction *domain.PredictionBoard, moves []*domain.MoveOutcome) (*domain.MoveOutcome, error) {
+	destinations := make([]*domain.Position, 0, len(moves))
+	for _, move := range moves {
+		destinations = append(destinations, move.GetDestination())
+	}
+	best, err := prediction.BestMoveCell(destinations)
+	if err != nil {
+		return nil, err
+	}
+	if best != nil {
+		for i, destination := range destinations {
+			if destination == best {
+				return moves[i], nil
+			}
+		}
+	}
+
+	allies := view.GetAllySubmarines()
+	current := attackableCellCount(allies, "", nil)
+	var widest *domain.MoveOutcome
+	widestCount := current
+	for _, move := range moves {
+		count := attackableCellCount(allies, move.GetSubmarine().GetId(), move.GetDestination())
+		if count > widestCount {
+			widest = move
+			widestCount = count
+		}
+	}
+	if widest != nil {
+		return widest, nil
+	}
+
+	for distance := shared.MaxDistance; distance >= shared.MinDistance; distance-- {
+		for _, move := range moves {
+			if move.GetDistance() == distance {
+				return move, nil
+			}
+		}
+	}
+	return nil, shared.ErrNoCandidateCell
+}
+
+// attackableCellCount は movedId の潜水艦を destination へ動かしたとして, 攻撃できるマスの数を返す.
+func attackableCellCount(allies []*domain.Submarine, movedId shared.SubmarineId, destination *domain.Position) int {
+	occupied := make(map[[2]int]bool, len(allies))
+	live := make([][2]int, 0, len(allies))
+	for _, submarine := range allies {
+		position := submarine.GetPosition()
+		if submarine.GetId() == movedId {
+			position = destination
+		}
+		x, y, err := position.GetPosition()
+		if err != nil {
+			continue
+		}
+		occupied[[2]int{x, y}] = true
+		if !submarine.IsSunk() {
+			live = append(live, [2]int{x, y})
+		}
+	}
+	attackable := make(map[[2]int]bool)
+	for _, cell := range live {
+		for dy := -1; dy <= 1; dy++ {
+			for dx := -1; dx <= 1; dx++ {
+				neighbor := [2]int{cell[0] + dx, cell[1] + dy}
+				if (dx == 0 && dy == 0) || occupied[neighbor] {
+					continue
+				}
+				if neighbor[0] < shared.MinPosition || neighbor[0] > shared.MaxPosition || neighbor[1] < shared.MinPosition || neighbor[1] > shared.MaxPosition {
+					continue
+				}
+				attackable[neighbor] = true
+			}
+		}
+	}
+	return len(attackable)
+}
+
+func filterMovesOf(moves []*domain.MoveOutcome, submarineId shared.SubmarineId) []*domain.MoveOutcome {
+	filtered := make([]*domain.MoveOutcome, 0, len(moves))
+	for _, move := range moves {
+		if move.GetSubmarine().GetId() == submarineId {
+			filtered = append(filtered, move)
+		}
+	}
+	return filtered
+}
+
+func newMoveCommand(playerId shared.PlayerId, move *domain.MoveOutcome) (*domain.ActionCommand, error) {
+	return domain.NewActionCommandWithSubmarine(playerId, move.GetSubmarine().GetId(), shared.Move, nil, move.GetDirection(), move.GetDistance())
+}
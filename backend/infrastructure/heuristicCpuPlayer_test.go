@@ -0,0 +1,232 @@
+package infrastructure
+
+import (
+	"context"
+	"testing"
+
+	"backend/domain"
+	"backend/domain/shared"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newCpuTestGame(t *testing.T, p1Positions [][2]int, p2Positions [][2]int) *domain.Game {
+	t.Helper()
+	board := domain.NewBoard()
+	for playerId, positions := range map[shared.PlayerId][][2]int{"p1": p1Positions, "p2": p2Positions} {
+		for _, xy := range positions {
+			position, err := domain.NewPosition(xy[0], xy[1])
+			assert.NoError(t, err)
+			_, err = board.PlaceSubmarine(playerId, position)
+			assert.NoError(t, err)
+		}
+	}
+	game, err := domain.NewGame("g1", "p1", "p2", board)
+	assert.NoError(t, err)
+	assert.NoError(t, game.Start())
+	return game
+}
+
+func applyCpuTestAttack(t *testing.T, game *domain.Game, playerId shared.PlayerId, x int, y int) {
+	t.Helper()
+	target, err := domain.NewPosition(x, y)
+	assert.NoError(t, err)
+	command, err := domain.NewActionCommand(playerId, shared.Attack, target, shared.DirectionUnknown, 0)
+	assert.NoError(t, err)
+	_, err = game.Apply(command)
+	assert.NoError(t, err)
+}
+
+var (
+	cornerPositions = [][2]int{{1, 1}, {2, 1}, {1, 2}, {2, 2}}
+	centerPositions = [][2]int{{3, 3}, {4, 4}, {5, 5}, {5, 4}}
+)
+
+func TestHeuristicCpuPlayerDecide(t *testing.T) {
+	ctx := context.Background()
+	cpu := NewHeuristicCpuPlayer()
+
+	t.Run("[HeuristicCpuPlayer: 命中された潜水艦を移動させる]", func(t *testing.T) {
+		game := newCpuTestGame(t, cornerPositions, centerPositions)
+		applyCpuTestAttack(t, game, "p1", 3, 3)
+		logs := game.PullTurnLogs()
+		view, err := domain.NewGameView(game, "p2", logs)
+		assert.NoError(t, err)
+
+		command, err := cpu.Decide(ctx, view)
+		assert.NoError(t, err)
+		actionType, err := command.GetActionType()
+		assert.NoError(t, err)
+		assert.Equal(t, shared.ActionType(shared.Move), actionType)
+		submarineId, err := command.GetSubmarineId()
+		assert.NoError(t, err)
+		assert.Equal(t, shared.SubmarineId("p2-sub-1"), submarineId)
+		_, err = game.Apply(command)
+		assert.NoError(t, err)
+	})
+
+	t.Run("[HeuristicCpuPlayer: aggressionが高ければ命中されても攻撃する]", func(t *testing.T) {
+		game := newCpuTestGame(t, cornerPositions, centerPositions)
+		applyCpuTestAttack(t, game, "p1", 3, 3)
+		view, err := domain.NewGameView(game, "p2", game.PullTurnLogs())
+		assert.NoError(t, err)
+		profile, err := domain.NewCpuProfileWithParams(shared.CpuHeuristic, 1, domain.DefaultMoveThreshold, domain.DefaultDiscountRate)
+		assert.NoError(t, err)
+		aggressive, err := NewHeuristicCpuPlayerWithProfile(profile)
+		assert.NoError(t, err)
+
+		command, err := aggressive.Decide(ctx, view)
+		assert.NoError(t, err)
+		actionType, err := command.GetActionType()
+		assert.NoError(t, err)
+		assert.Equal(t, shared.ActionType(shared.Attack), actionType)
+	})
+
+	t.Run("[HeuristicCpuPlayer: 敵の攻撃の周囲で最も期待値の高いマスを攻撃する]", func(t *testing.T) {
+		game := newCpuTestGame(t, cornerPositions, centerPositions)
+		applyCpuTestAttack(t, game, "p1", 3, 2)
+		view, err := domain.NewGameView(game, "p2", game.PullTurnLogs())
+		assert.NoError(t, err)
+
+		command, err := cpu.Decide(ctx, view)
+		assert.NoError(t, err)
+		actionType, err := command.GetActionType()
+		assert.NoError(t, err)
+		assert.Equal(t, shared.ActionType(shared.Attack), actionType)
+		target, err := command.GetTarget()
+		assert.NoError(t, err)
+		x, y, err := target.GetPosition()
+		assert.NoError(t, err)
+		assert.Equal(t, [2]int{2, 2}, [2]int{x, y})
+	})
+
+	t.Run("[HeuristicCpuPlayer: 手番でなければ行動しない]", func(t *testing.T) {
+		game := newCpuTestGame(t, cornerPositions, centerPositions)
+		view, err := domain.NewGameView(game, "p2", nil)
+		assert.NoError(t, err)
+		_, err = cpu.Decide(ctx, view)
+		assert.ErrorIs(t, err, shared.ErrInvalidTurn)
+	})
+
+	t.Run("[HeuristicCpuPlayer: キャンセルされたcontext]", func(t *testing.T) {
+		game := newCpuTestGame(t, cornerPositions, centerPositions)
+		view, err := domain.NewGameView(game, "p1", nil)
+		assert.NoError(t, err)
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err = cpu.Decide(canceled, view)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestHeuristicCpuPlayerChooseMove(t *testing.T) {
+	game := newCpuTestGame(t, cornerPositions, centerPositions)
+	view, err := domain.NewGameView(game, "p1", nil)
+	assert.NoError(t, err)
+	moves := view.LegalMoves()
+
+	t.Run("[chooseMove: 存在確率が0.5~0.75のマスを優先]", func(t *testing.T) {
+		prediction := domain.NewPredictionBoard()
+		destination, err := domain.NewPosition(4, 2)
+		assert.NoError(t, err)
+		assert.NoError(t, prediction.MarkHit(destination))
+		assert.NoError(t, prediction.AdvanceTurn(3))
+
+		move, err := chooseMove(view, prediction, moves)
+		assert.NoError(t, err)
+		assert.Equal(t, destination, move.GetDestination())
+	})
+
+	t.Run("[chooseMove: 攻撃可能箇所が最も増えるマス]", func(t *testing.T) {
+		move, err := chooseMove(view, domain.NewPredictionBoard(), moves)
+		assert.NoError(t, err)
+		allies := view.GetAllySubmarines()
+		before := attackableCellCount(allies, "", nil)
+		after := attackableCellCount(allies, move.GetSubmarine().GetId(), move.GetDestination())
+		assert.Greater(t, after, before)
+		for _, other := range moves {
+			assert.LessOrEqual(t, attackableCellCount(allies, other.GetSubmarine().GetId(), other.GetDestination()), after)
+		}
+	})
+
+	t.Run("[chooseMove: 候補がなければエラー]", func(t *testing.T) {
+		_, err := chooseMove(view, domain.NewPredictionBoard(), nil)
+		assert.ErrorIs(t, err, shared.ErrNoCandidateCell)
+	})
+}
+
+// 同じ CPU 同士で対戦させ, 決めた行動が一度も拒否されないことを確認する.
+func TestHeuristicCpuPlayerPlaysLegalGame(t *testing.T) {
+	ctx := context.Background()
+	game := newCpuTestGame(t, cornerPositions, centerPositions)
+	cpu := NewHeuristicCpuPlayer()
+	logs := make([]*domain.TurnLog, 0)
+	for i := 0; i < 200 && !game.IsFinished(); i++ {
+		view, err := domain.NewGameView(game, game.GetCurrentPlayerId(), logs)
+		assert.NoError(t, err)
+		command, err := cpu.Decide(ctx, view)
+		if !assert.NoError(t, err) {
+			return
+		}
+		_, err = game.Apply(command)
+		if !assert.NoError(t, err, "turn=%d", game.GetTurn()) {
+			return
+		}
+		logs = append(logs, game.PullTurnLogs()...)
+	}
+}
+
+func TestHeuristicCpuPlayerRationale(t *testing.T) {
+	ctx := context.Background()
+	cpu := NewHeuristicCpuPlayer()
+
+	t.Run("[DecideWithRationale: 危険な潜水艦を逃がす理由]", func(t *testing.T) {
+		game := newCpuTestGame(t, cornerPositions, centerPositions)
+		applyCpuTestAttack(t, game, "p1", 3, 3)
+		view, err := domain.NewGameView(game, "p2", game.PullTurnLogs())
+		assert.NoError(t, err)
+
+		command, rationale, err := cpu.DecideWithRationale(ctx, view)
+		assert.NoError(t, err)
+		assert.Equal(t, shared.CpuTierAllyAtRisk, rationale.GetTier())
+		assert.Contains(t, rationale.GetDetail(), "p2-sub-1")
+		chosen := 0
+		for _, candidate := range rationale.GetCandidates() {
+			submarineId, err := candidate.GetCommand().GetSubmarineId()
+			assert.NoError(t, err)
+			assert.Equal(t, shared.SubmarineId("p2-sub-1"), submarineId)
+			if candidate.GetCommand() == command {
+				chosen++
+			}
+		}
+		assert.Equal(t, 1, chosen)
+	})
+
+	t.Run("[DecideWithRationale: 攻撃先の候補と存在確率]", func(t *testing.T) {
+		game := newCpuTestGame(t, cornerPositions, centerPositions)
+		applyCpuTestAttack(t, game, "p1", 3, 2)
+		view, err := domain.NewGameView(game, "p2", game.PullTurnLogs())
+		assert.NoError(t, err)
+		prediction, err := view.Prediction(domain.DefaultDiscountRate)
+		assert.NoError(t, err)
+
+		command, rationale, err := cpu.DecideWithRationale(ctx, view)
+		assert.NoError(t, err)
+		assert.Equal(t, shared.CpuTierBestAttack, rationale.GetTier())
+		assert.Equal(t, prediction.Grid(), rationale.GetPrediction())
+		candidates := rationale.GetCandidates()
+		assert.Len(t, candidates, len(view.LegalAttackTargets()))
+		for _, candidate := range candidates {
+			possibility, err := prediction.Possibility(candidate.GetPosition())
+			assert.NoError(t, err)
+			assert.Equal(t, possibility, candidate.GetScore())
+		}
+		target, err := command.GetTarget()
+		assert.NoError(t, err)
+		best, err := prediction.Possibility(target)
+		assert.NoError(t, err)
+		for _, candidate := range candidates {
+			assert.LessOrEqual(t, candidate.GetScore(), best)
+		}
+	})
+}
@@ -0,0 +1,92 @@
+package infrastructure
+
+import (
+	"backend/domain"
+	"backend/domain/shared"
+	"context"
+	"sync"
+)
+
+// InMemoryGameRepository はプロセス内のメモリに対戦を保持する.
+// 保存した後や取得した後に呼び出し側が変更しても影響しないよう, 保存時と取得時に複製する.
+// 保存時のバージョンの確認と書き込みは同じロックの中で行う.
+// 削除した対戦は playerGames の両プレイヤーの参加中の対戦からも外す.
+type InMemoryGameRepository struct {
+	mu          sync.RWMutex
+	games       map[shared.GameId]*domain.Game
+	playerGames *InMemoryPlayerGamesIndexRepository
+}
+
+func NewInMemoryGameRepository(playerGames *InMemoryPlayerGamesIndexRepository) *InMemoryGameRepository {
+	return &InMemoryGameRepository{
+		games:       make(map[shared.GameId]*domain.Game),
+		playerGames: playerGames,
+	}
+}
+
+func (repository *InMemoryGameRepository) Save(ctx context.Context, game *domain.Game) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if game == nil {
+		return shared.ErrGameIsNil
+	}
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+	saved := game.Clone()
+	if err := repository.checkVersionLocked(saved); err != nil {
+		return err
+	}
+	repository.storeLocked(saved)
+	return game.SetVersion(saved.GetVersion())
+}
+
+func (repository *InMemoryGameRepository) FindByID(ctx context.Context, gameId shared.GameId) (*domain.Game, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+	game, ok := repository.games[gameId]
+	if !ok {
+		return nil, shared.ErrGameNotFound
+	}
+	return game.Clone(), nil
+}
+
+func (repository *InMemoryGameRepository) Delete(ctx context.Context, gameId shared.GameId) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+	game, ok := repository.games[gameId]
+	if !ok {
+		return shared.ErrGameNotFound
+	}
+	// InMemoryUnitOfWork と同じく対戦, 参加中の対戦の順にロックを取る.
+	repository.playerGames.mu.Lock()
+	defer repository.playerGames.mu.Unlock()
+	repository.playerGames.removeLocked(game.GetPlayerAId(), gameId)
+	repository.playerGames.removeLocked(game.GetPlayerBId(), gameId)
+	delete(repository.games, gameId)
+	return nil
+}
+
+// checkVersionLocked は game のバージョンが保存されている対戦のものと一致するかを確かめる. mu を取得してから呼ぶ.
+func (repository *InMemoryGameRepository) checkVersionLocked(game *domain.Game) error {
+	storedVersion := 0
+	if stored, ok := repository.games[game.GetId()]; ok {
+		storedVersion = stored.GetVersion()
+	}
+	if storedVersion != game.GetVersion() {
+		return shared.ErrConcurrentModification
+	}
+	return nil
+}
+
+// storeLocked はバージョンを1つ進めて game をそのまま保持する. 呼び出し側で複製し, mu を取得してから呼ぶ.
+func (repository *InMemoryGameRepository) storeLocked(game *domain.Game) {
+	_ = game.SetVersion(game.GetVersion() + 1)
+	repository.games[game.GetId()] = game
+}
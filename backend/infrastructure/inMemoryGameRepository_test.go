@@ -0,0 +1,14 @@
+package infrastructure
+
+import (
+	"testing"
+
+	"backend/domain/interfaces"
+	"backend/infrastructure/repositorytest"
+)
+
+func TestInMemoryGameRepository(t *testing.T) {
+	repositorytest.RunGameRepositoryContract(t, func(t *testing.T) interfaces.GameRepository {
+		return NewInMemoryGameRepository(NewInMemoryPlayerGamesIndexRepository())
+	})
+}
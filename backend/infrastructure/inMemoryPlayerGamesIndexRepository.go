@@ -0,0 +1,92 @@
+package infrastructure
+
+import (
+	"backend/domain/shared"
+	"context"
+	"sort"
+	"sync"
+)
+
+// InMemoryPlayerGamesIndexRepository はプロセス内のメモリにプレイヤーごとの参加中の対戦を保持する.
+type InMemoryPlayerGamesIndexRepository struct {
+	mu    sync.RWMutex
+	games map[shared.PlayerId]map[shared.GameId]struct{}
+}
+
+func NewInMemoryPlayerGamesIndexRepository() *InMemoryPlayerGamesIndexRepository {
+	return &InMemoryPlayerGamesIndexRepository{
+		games: make(map[shared.PlayerId]map[shared.GameId]struct{}),
+	}
+}
+
+func (repository *InMemoryPlayerGamesIndexRepository) AddGame(ctx context.Context, playerId shared.PlayerId, gameId shared.GameId) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := validatePlayerGame(playerId, gameId); err != nil {
+		return err
+	}
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+	repository.addLocked(playerId, gameId)
+	return nil
+}
+
+// addLocked は playerId の対戦に gameId を加える. mu を取得してから呼ぶ.
+func (repository *InMemoryPlayerGamesIndexRepository) addLocked(playerId shared.PlayerId, gameId shared.GameId) {
+	if _, ok := repository.games[playerId]; !ok {
+		repository.games[playerId] = make(map[shared.GameId]struct{})
+	}
+	repository.games[playerId][gameId] = struct{}{}
+}
+
+func (repository *InMemoryPlayerGamesIndexRepository) RemoveGame(ctx context.Context, playerId shared.PlayerId, gameId shared.GameId) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := validatePlayerGame(playerId, gameId); err != nil {
+		return err
+	}
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+	repository.removeLocked(playerId, gameId)
+	return nil
+}
+
+// removeLocked は playerId の対戦から gameId を外す. mu を取得してから呼ぶ.
+func (repository *InMemoryPlayerGamesIndexRepository) removeLocked(playerId shared.PlayerId, gameId shared.GameId) {
+	delete(repository.games[playerId], gameId)
+	if len(repository.games[playerId]) == 0 {
+		delete(repository.games, playerId)
+	}
+}
+
+func (repository *InMemoryPlayerGamesIndexRepository) ListGames(ctx context.Context, playerId shared.PlayerId) ([]shared.GameId, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+	gameIds := make([]shared.GameId, 0, len(repository.games[playerId]))
+	for gameId := range repository.games[playerId] {
+		gameIds = append(gameIds, gameId)
+	}
+	sortGameIds(gameIds)
+	return gameIds, nil
+}
+
+func validatePlayerGame(playerId shared.PlayerId, gameId shared.GameId) error {
+	if playerId == "" {
+		return shared.ErrInvalidPlayerID
+	}
+	if gameId == "" {
+		return shared.ErrInvalidGameId
+	}
+	return nil
+}
+
+func sortGameIds(gameIds []shared.GameId) {
+	sort.Slice(gameIds, func(i, j int) bool {
+		return gameIds[i] < gameIds[j]
+	})
+}
@@ -0,0 +1,14 @@
+package infrastructure
+
+import (
+	"testing"
+
+	"backend/domain/interfaces"
+	"backend/infrastructure/repositorytest"
+)
+
+func TestInMemoryPlayerGamesIndexRepository(t *testing.T) {
+	repositorytest.RunPlayerGamesIndexRepositoryContract(t, func(t *testing.T) interfaces.PlayerGamesIndexRepository {
+		return NewInMemoryPlayerGamesIndexRepository()
+	})
+}
@@ -0,0 +1,65 @@
+package infrastructure
+
+import (
+	"backend/domain"
+	"backend/domain/shared"
+	"context"
+	"sync"
+)
+
+type predictionKey struct {
+	gameId   shared.GameId
+	playerId shared.PlayerId
+}
+
+// InMemoryPredictionRepository はプロセス内のメモリにプレイヤーごとの存在確率マップを保持する.
+// 保存した後に呼び出し側が変更しても影響しないよう, 複製して保持する.
+type InMemoryPredictionRepository struct {
+	mu     sync.RWMutex
+	boards map[predictionKey]*domain.PredictionBoard
+}
+
+func NewInMemoryPredictionRepository() *InMemoryPredictionRepository {
+	return &InMemoryPredictionRepository{
+		boards: make(map[predictionKey]*domain.PredictionBoard),
+	}
+}
+
+func (repository *InMemoryPredictionRepository) Save(ctx context.Context, gameId shared.GameId, playerId shared.PlayerId, board *domain.PredictionBoard) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := validatePredictionKey(gameId, playerId); err != nil {
+		return err
+	}
+	if board == nil {
+		return shared.ErrPredictionBoardIsNil
+	}
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+	repository.boards[predictionKey{gameId, playerId}] = board.Clone()
+	return nil
+}
+
+func (repository *InMemoryPredictionRepository) Find(ctx context.Context, gameId shared.GameId, playerId shared.PlayerId) (*domain.PredictionBoard, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+	board, ok := repository.boards[predictionKey{gameId, playerId}]
+	if !ok {
+		return nil, shared.ErrPredictionBoardNotFound
+	}
+	return board.Clone(), nil
+}
+
+func validatePredictionKey(gameId shared.GameId, playerId shared.PlayerId) error {
+	if gameId == "" {
+		return shared.ErrInvalidGameId
+	}
+	if playerId == "" {
+		return shared.ErrInvalidPlayerID
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+package infrastructure
+
+import (
+	"testing"
+
+	"backend/domain/interfaces"
+	"backend/infrastructure/repositorytest"
+)
+
+func TestInMemoryPredictionRepository(t *testing.T) {
+	repositorytest.RunPredictionRepositoryContract(t, func(t *testing.T) interfaces.PredictionRepository {
+		return NewInMemoryPredictionRepository()
+	})
+}
@@ -0,0 +1,80 @@
+package infrastructure
+
+import (
+	"backend/domain"
+	"backend/domain/shared"
+	"context"
+	"sync"
+)
+
+// InMemoryTurnLogRepository はプロセス内のメモリにTurnLogを保持する. 複数のgoroutineから同時に利用できる.
+type InMemoryTurnLogRepository struct {
+	mu   sync.RWMutex
+	logs map[shared.GameId][]*domain.TurnLog
+}
+
+func NewInMemoryTurnLogRepository() *InMemoryTurnLogRepository {
+	return &InMemoryTurnLogRepository{
+		logs: make(map[shared.GameId][]*domain.TurnLog),
+	}
+}
+
+func (repository *InMemoryTurnLogRepository) Append(ctx context.Context, gameId shared.GameId, log *domain.TurnLog) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := validateTurnLog(gameId, log); err != nil {
+		return err
+	}
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+	repository.logs[gameId] = append(repository.logs[gameId], log)
+	return nil
+}
+
+func (repository *InMemoryTurnLogRepository) FindByGameId(ctx context.Context, gameId shared.GameId) ([]*domain.TurnLog, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+	logs := make([]*domain.TurnLog, len(repository.logs[gameId]))
+	copy(logs, repository.logs[gameId])
+	return logs, nil
+}
+
+func (repository *InMemoryTurnLogRepository) FindRange(ctx context.Context, gameId shared.GameId, fromTurn int, limit int) ([]*domain.TurnLog, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if fromTurn < 0 || limit <= 0 {
+		return nil, shared.ErrInvalidPageRange
+	}
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+	return filterTurnLogRange(repository.logs[gameId], fromTurn, limit), nil
+}
+
+func validateTurnLog(gameId shared.GameId, log *domain.TurnLog) error {
+	if log == nil {
+		return shared.ErrTurnLogIsNil
+	}
+	if gameId == "" || log.GetGameId() != gameId {
+		return shared.ErrInvalidGameId
+	}
+	return nil
+}
+
+// filterTurnLogRange は logs のうち fromTurn 以降のものを先頭から最大 limit 件返す.
+func filterTurnLogRange(logs []*domain.TurnLog, fromTurn int, limit int) []*domain.TurnLog {
+	page := make([]*domain.TurnLog, 0, limit)
+	for _, log := range logs {
+		if len(page) >= limit {
+			break
+		}
+		if log.GetTurn() >= fromTurn {
+			page = append(page, log)
+		}
+	}
+	return page
+}
@@ -0,0 +1,14 @@
+package infrastructure
+
+import (
+	"testing"
+
+	"backend/domain/interfaces"
+	"backend/infrastructure/repositorytest"
+)
+
+func TestInMemoryTurnLogRepository(t *testing.T) {
+	repositorytest.RunTurnLogRepositoryContract(t, func(t *testing.T) interfaces.TurnLogRepository {
+		return NewInMemoryTurnLogRepository()
+	})
+}
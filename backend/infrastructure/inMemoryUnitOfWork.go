@@ -0,0 +1,75 @@
+package infrastructure
+
+import (
+	"backend/domain/interfaces"
+	"context"
+)
+
+// InMemoryUnitOfWork は InMemoryGameRepository, InMemoryTurnLogRepository, InMemoryPredictionRepository,
+// InMemoryPlayerGamesIndexRepository への書き込みをまとめてコミットする.
+// コミットでは4つのリポジトリのロックをこの順に取得し, 全ての対戦のバージョンを確かめてから書き込む.
+// 書き込みは検証を済ませた値をマップに入れるだけで失敗しないため, 途中まで書き込まれた状態は読まれない.
+type InMemoryUnitOfWork struct {
+	games       *InMemoryGameRepository
+	turnLogs    *InMemoryTurnLogRepository
+	predictions *InMemoryPredictionRepository
+	playerGames *InMemoryPlayerGamesIndexRepository
+}
+
+func NewInMemoryUnitOfWork(games *InMemoryGameRepository, turnLogs *InMemoryTurnLogRepository, predictions *InMemoryPredictionRepository, playerGames *InMemoryPlayerGamesIndexRepository) *InMemoryUnitOfWork {
+	return &InMemoryUnitOfWork{
+		games:       games,
+		turnLogs:    turnLogs,
+		predictions: predictions,
+		playerGames: playerGames,
+	}
+}
+
+func (unitOfWork *InMemoryUnitOfWork) Begin() interfaces.TurnTransaction {
+	return &inMemoryTurnTransaction{unitOfWork: unitOfWork}
+}
+
+type inMemoryTurnTransaction struct {
+	turnChanges
+	unitOfWork *InMemoryUnitOfWork
+}
+
+func (transaction *inMemoryTurnTransaction) Commit(ctx context.Context) error {
+	if err := transaction.close(); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	games := transaction.unitOfWork.games
+	turnLogs := transaction.unitOfWork.turnLogs
+	predictions := transaction.unitOfWork.predictions
+	playerGames := transaction.unitOfWork.playerGames
+	games.mu.Lock()
+	defer games.mu.Unlock()
+	turnLogs.mu.Lock()
+	defer turnLogs.mu.Unlock()
+	predictions.mu.Lock()
+	defer predictions.mu.Unlock()
+	playerGames.mu.Lock()
+	defer playerGames.mu.Unlock()
+
+	for _, staged := range transaction.games {
+		if err := games.checkVersionLocked(staged.saved); err != nil {
+			return err
+		}
+	}
+	for _, staged := range transaction.games {
+		games.storeLocked(staged.saved)
+	}
+	for _, staged := range transaction.logs {
+		turnLogs.logs[staged.gameId] = append(turnLogs.logs[staged.gameId], staged.log)
+	}
+	for _, staged := range transaction.predictions {
+		predictions.boards[predictionKey{staged.gameId, staged.playerId}] = staged.board
+	}
+	for _, staged := range transaction.playerGames {
+		playerGames.addLocked(staged.playerId, staged.gameId)
+	}
+	return transaction.advanceVersions()
+}
@@ -0,0 +1,23 @@
+package infrastructure
+
+import (
+	"testing"
+
+	"backend/infrastructure/repositorytest"
+)
+
+func TestInMemoryUnitOfWork(t *testing.T) {
+	repositorytest.RunUnitOfWorkContract(t, func(t *testing.T) repositorytest.UnitOfWorkStore {
+		playerGames := NewInMemoryPlayerGamesIndexRepository()
+		games := NewInMemoryGameRepository(playerGames)
+		turnLogs := NewInMemoryTurnLogRepository()
+		predictions := NewInMemoryPredictionRepository()
+		return repositorytest.UnitOfWorkStore{
+			UnitOfWork:  NewInMemoryUnitOfWork(games, turnLogs, predictions, playerGames),
+			Games:       games,
+			TurnLogs:    turnLogs,
+			Predictions: predictions,
+			PlayerGames: playerGames,
+		}
+	})
+}
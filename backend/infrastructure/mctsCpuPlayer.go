@@ -0,0 +1,330 @@
+package infrastructure
+
+import (
+	"backend/domain"
+	"backend/domain/shared"
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	defaultMctsIterations   = 400
+	defaultMctsRolloutDepth = 10
+	defaultMctsExploration  = 0.1
+	// defaultMctsTimeBudget は profile から作る MctsCpuPlayer の1手あたりの思考時間の上限.
+	defaultMctsTimeBudget = time.Second
+)
+
+// MctsConfig は MctsCpuPlayer の探索量を決める. 0 の項目には既定値を使う.
+// Iterations と TimeBudget を両方指定した場合は, 先にどちらかへ達した時点で打ち切る.
+type MctsConfig struct {
+	Iterations   int
+	TimeBudget   time.Duration
+	RolloutDepth int
+	Exploration  float64
+}
+
+// MctsCpuPlayer は Information Set MCTS で行動を決める.
+// 反復ごとに行動記録と矛盾しない敵艦隊の配置を1つ選び, その配置の対戦を Game.Apply で進めて評価する.
+// 木は自分から見た情報集合ごとに共有し, その配置で選べる行動だけを UCB で比較する.
+type MctsCpuPlayer struct {
+	mu     sync.Mutex
+	random *rand.Rand
+	config MctsConfig
+}
+
+func NewMctsCpuPlayer(source rand.Source, config MctsConfig) *MctsCpuPlayer {
+	if config.Iterations <= 0 && config.TimeBudget <= 0 {
+		config.Iterations = defaultMctsIterations
+	}
+	if config.RolloutDepth <= 0 {
+		config.RolloutDepth = defaultMctsRolloutDepth
+	}
+	if config.Exploration <= 0 {
+		config.Exploration = defaultMctsExploration
+	}
+	return &MctsCpuPlayer{
+		random: rand.New(source),
+		config: config,
+	}
+}
+
+// NewMctsCpuPlayerWithProfile は profile の探索量で MctsCpuPlayer を作る. 名前は確認しない.
+// 指定のない反復回数と思考時間には既定値を使い, 1手の探索がいつまでも終わらないことはない.
+func NewMctsCpuPlayerWithProfile(source rand.Source, profile *domain.CpuProfile) (*MctsCpuPlayer, error) {
+	if profile == nil {
+		return nil, shared.ErrCpuProfileIsNil
+	}
+	config := MctsConfig{Iterations: profile.GetIterations(), TimeBudget: profile.GetTimeBudget()}
+	if config.Iterations <= 0 {
+		config.Iterations = defaultMctsIterations
+	}
+	if config.TimeBudget <= 0 {
+		config.TimeBudget = defaultMctsTimeBudget
+	}
+	return NewMctsCpuPlayer(source, config), nil
+}
+
+// mctsNode は木の1つの節点. reward と visits は playerId がこの行動を選んだ場合の評価.
+// position は攻撃先か移動先.
+type mctsNode struct {
+	playerId     shared.PlayerId
+	command      *domain.ActionCommand
+	position     *domain.Position
+	visits       int
+	availability int
+	reward       float64
+	children     map[string]*mctsNode
+}
+
+func newMctsNode(playerId shared.PlayerId, command *domain.ActionCommand, position *domain.Position) *mctsNode {
+	return &mctsNode{
+		playerId: playerId,
+		command:  command,
+		position: position,
+		children: make(map[string]*mctsNode),
+	}
+}
+
+// mctsAction は1つの局面で選べる行動と, 木の中でそれを区別するための名前.
+type mctsAction struct {
+	key      string
+	command  *domain.ActionCommand
+	position *domain.Position
+}
+
+// Decide は予算を使い切るまで探索し, 最も多く試した行動を返す.
+// ctx がキャンセルされた場合は探索を打ち切ってエラーを返す.
+func (cpu *MctsCpuPlayer) Decide(ctx context.Context, view *domain.GameView) (*domain.ActionCommand, error) {
+	command, _, err := cpu.DecideWithRationale(ctx, view)
+	return command, err
+}
+
+// DecideWithRationale は Decide と同じ行動を, 根の子ごとの平均評価を候補とした理由とともに返す.
+func (cpu *MctsCpuPlayer) DecideWithRationale(ctx context.Context, view *domain.GameView) (*domain.ActionCommand, *domain.CpuRationale, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	if view == nil {
+		return nil, nil, shared.ErrGameIsNil
+	}
+	if view.GetCurrentPlayerId() != view.GetViewerId() {
+		return nil, nil, shared.ErrInvalidTurn
+	}
+	belief, err := view.ExactPrediction(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	cpu.mu.Lock()
+	defer cpu.mu.Unlock()
+
+	root := newMctsNode("", nil, nil)
+	iterations := 0
+	var deadline time.Time
+	if cpu.config.TimeBudget > 0 {
+		deadline = time.Now().Add(cpu.config.TimeBudget)
+	}
+	for iteration := 0; cpu.config.Iterations <= 0 || iteration < cpu.config.Iterations; iteration++ {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+		if !deadline.IsZero() && iteration > 0 && !time.Now().Before(deadline) {
+			break
+		}
+		enemies, err := belief.SampleFleet(cpu.random, view.GetOpponentId())
+		if err != nil {
+			return nil, nil, err
+		}
+		game, err := view.Determinize(enemies)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := cpu.iterate(root, game, view.GetViewerId()); err != nil {
+			return nil, nil, err
+		}
+		iterations++
+	}
+
+	var best *mctsNode
+	bestKey := ""
+	candidates := make([]*domain.CpuCandidate, 0, len(root.children))
+	keys := make([]string, 0, len(root.children))
+	for key := range root.children {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		child := root.children[key]
+		if best == nil || child.visits > best.visits || (child.visits == best.visits && child.reward > best.reward) {
+			best = child
+			bestKey = key
+		}
+		candidate, err := domain.NewCpuCandidate(child.command, child.position, child.reward/float64(child.visits))
+		if err != nil {
+			return nil, nil, err
+		}
+		candidates = append(candidates, candidate)
+	}
+	if best == nil {
+		return nil, nil, shared.ErrNoCandidateCell
+	}
+	detail := fmt.Sprintf("most visited %d of %d iterations → %s", best.visits, iterations, bestKey)
+	return best.command, domain.NewCpuRationale(shared.CpuTierSearch, detail, candidates, belief.Grid()), nil
+}
+
+// iterate は1つの配置について選択, 展開, プレイアウト, 逆伝播を1回ずつ行う.
+// 子の節点は配置をまたいで共有するため, 反映するのは節点に残した行動ではなくこの配置で選べる同じ名前の行動とする.
+func (cpu *MctsCpuPlayer) iterate(root *mctsNode, game *domain.Game, viewerId shared.PlayerId) error {
+	path := []*mctsNode{root}
+	node := root
+	for !game.IsFinished() {
+		actions, err := mctsActions(game, viewerId)
+		if err != nil {
+			return err
+		}
+		if len(actions) == 0 {
+			break
+		}
+		untried := make([]mctsAction, 0, len(actions))
+		for _, action := range actions {
+			if _, ok := node.children[action.key]; !ok {
+				untried = append(untried, action)
+			}
+		}
+		var action mctsAction
+		if len(untried) > 0 {
+			action = untried[cpu.random.Intn(len(untried))]
+			node.children[action.key] = newMctsNode(game.GetCurrentPlayerId(), action.command, action.position)
+		} else {
+			action = cpu.selectAction(node, actions)
+		}
+		next := node.children[action.key]
+		for _, available := range actions {
+			if child, ok := node.children[available.key]; ok {
+				child.availability++
+			}
+		}
+		if _, err := game.Apply(action.command); err != nil {
+			return err
+		}
+		path = append(path, next)
+		node = next
+		if len(untried) > 0 {
+			break
+		}
+	}
+
+	if err := cpu.rollout(game); err != nil {
+		return err
+	}
+	for _, visited := range path[1:] {
+		visited.visits++
+		visited.reward += evaluate(game, visited.playerId)
+	}
+	return nil
+}
+
+// selectAction は actions のうち子の UCB の値が最も大きい行動を選ぶ.
+func (cpu *MctsCpuPlayer) selectAction(node *mctsNode, actions []mctsAction) mctsAction {
+	var best mctsAction
+	bestScore := math.Inf(-1)
+	for _, action := range actions {
+		child := node.children[action.key]
+		score := child.reward/float64(child.visits) + cpu.config.Exploration*math.Sqrt(math.Log(float64(child.availability+1))/float64(child.visits))
+		if score > bestScore {
+			best = action
+			bestScore = score
+		}
+	}
+	return best
+}
+
+// rollout は終局か RolloutDepth 手に達するまで両者に一様ランダムな行動を取らせる.
+func (cpu *MctsCpuPlayer) rollout(game *domain.Game) error {
+	for depth := 0; depth < cpu.config.RolloutDepth && !game.IsFinished(); depth++ {
+		playerId := game.GetCurrentPlayerId()
+		targets := game.GetBoard().LegalAttackTargets(playerId)
+		moves := game.GetBoard().LegalMoves(playerId)
+		if len(targets)+len(moves) == 0 {
+			return nil
+		}
+		var command *domain.ActionCommand
+		var err error
+		if index := cpu.random.Intn(len(targets) + len(moves)); index < len(targets) {
+			command, err = domain.NewActionCommand(playerId, shared.Attack, targets[index], shared.DirectionUnknown, 0)
+		} else {
+			command, err = newMoveCommand(playerId, moves[index-len(targets)])
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := game.Apply(command); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mctsActions は手番プレイヤーが選べる行動を返す.
+// viewerId の潜水艦は区別できるため移動はidを指定し, 相手の移動は方向と距離だけで区別する.
+// 相手の潜水艦のidは配置ごとに振り直すため, 同じ方向と距離の移動は最初に見つけた1つで代表させる.
+func mctsActions(game *domain.Game, viewerId shared.PlayerId) ([]mctsAction, error) {
+	playerId := game.GetCurrentPlayerId()
+	board := game.GetBoard()
+	actions := make([]mctsAction, 0)
+	for _, target := range board.LegalAttackTargets(playerId) {
+		command, err := domain.NewActionCommand(playerId, shared.Attack, target, shared.DirectionUnknown, 0)
+		if err != nil {
+			return nil, err
+		}
+		x, y, err := target.GetPosition()
+		if err != nil {
+			return nil, err
+		}
+		actions = append(actions, mctsAction{fmt.Sprintf("attack %d %d", x, y), command, target})
+	}
+	seen := make(map[string]bool)
+	for _, move := range board.LegalMoves(playerId) {
+		key := fmt.Sprintf("move %s %d", move.GetDirection(), move.GetDistance())
+		if playerId == viewerId {
+			key = fmt.Sprintf("move %s %s %d", move.GetSubmarine().GetId(), move.GetDirection(), move.GetDistance())
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		command, err := newMoveCommand(playerId, move)
+		if err != nil {
+			return nil, err
+		}
+		actions = append(actions, mctsAction{key, command, move.GetDestination()})
+	}
+	return actions, nil
+}
+
+// evaluate は playerId から見た対戦の評価を 0~1 で返す.
+// 終局していれば勝ち1, 負け0, 引き分け0.5 とし, 途中であれば残りHPの割合とする.
+func evaluate(game *domain.Game, playerId shared.PlayerId) float64 {
+	if game.IsFinished() {
+		switch game.GetWinnerId() {
+		case playerId:
+			return 1
+		case "":
+			return 0.5
+		default:
+			return 0
+		}
+	}
+	board := game.GetBoard()
+	ally := board.RemainingHp(playerId)
+	opponent := board.RemainingHp(game.GetOpponentId(playerId))
+	if ally+opponent == 0 {
+		return 0.5
+	}
+	return float64(ally) / float64(ally+opponent)
+}
@@ -0,0 +1,139 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"backend/domain"
+	"backend/domain/shared"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMctsCpuPlayerDecide(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("[MctsCpuPlayer: 撃沈できる敵艦を攻撃する]", func(t *testing.T) {
+		game := newCpuTestGame(t, cornerPositions, centerPositions)
+		for i := 0; i < 2; i++ {
+			applyCpuTestAttack(t, game, "p1", 3, 3)
+			applyCpuTestAttack(t, game, "p2", 4, 3)
+		}
+		view, err := domain.NewGameView(game, "p1", game.PullTurnLogs())
+		assert.NoError(t, err)
+
+		cpu := NewMctsCpuPlayer(rand.NewSource(1), MctsConfig{Iterations: 300})
+		command, err := cpu.Decide(ctx, view)
+		assert.NoError(t, err)
+		assert.Equal(t, "attack (3,3)", describeCommand(t, command))
+	})
+
+	t.Run("[MctsCpuPlayer: 根の子ごとの平均評価を理由にする]", func(t *testing.T) {
+		game := newCpuTestGame(t, cornerPositions, centerPositions)
+		view, err := domain.NewGameView(game, "p1", nil)
+		assert.NoError(t, err)
+		cpu := NewMctsCpuPlayer(rand.NewSource(1), MctsConfig{Iterations: 50})
+		command, rationale, err := cpu.DecideWithRationale(ctx, view)
+		assert.NoError(t, err)
+		assert.Equal(t, shared.CpuTierSearch, rationale.GetTier())
+		assert.Contains(t, rationale.GetDetail(), "of 50 iterations")
+		assert.Len(t, rationale.GetCandidates(), len(view.LegalAttackTargets())+len(view.LegalMoves()))
+		found := false
+		for _, candidate := range rationale.GetCandidates() {
+			assert.NotNil(t, candidate.GetPosition())
+			assert.GreaterOrEqual(t, candidate.GetScore(), 0.0)
+			assert.LessOrEqual(t, candidate.GetScore(), 1.0)
+			found = found || candidate.GetCommand() == command
+		}
+		assert.True(t, found)
+		assert.Len(t, rationale.GetPrediction(), 5)
+	})
+
+	t.Run("[MctsCpuPlayer: 同じ乱数なら同じ行動]", func(t *testing.T) {
+		game := newCpuTestGame(t, cornerPositions, centerPositions)
+		view, err := domain.NewGameView(game, "p1", nil)
+		assert.NoError(t, err)
+		first, err := NewMctsCpuPlayer(rand.NewSource(3), MctsConfig{Iterations: 100}).Decide(ctx, view)
+		assert.NoError(t, err)
+		second, err := NewMctsCpuPlayer(rand.NewSource(3), MctsConfig{Iterations: 100}).Decide(ctx, view)
+		assert.NoError(t, err)
+		assert.Equal(t, describeCommand(t, first), describeCommand(t, second))
+	})
+
+	t.Run("[MctsCpuPlayer: 時間の予算で打ち切る]", func(t *testing.T) {
+		game := newCpuTestGame(t, cornerPositions, centerPositions)
+		view, err := domain.NewGameView(game, "p1", nil)
+		assert.NoError(t, err)
+		cpu := NewMctsCpuPlayer(rand.NewSource(1), MctsConfig{TimeBudget: 50 * time.Millisecond})
+		started := time.Now()
+		command, err := cpu.Decide(ctx, view)
+		assert.NoError(t, err)
+		assert.NotNil(t, command)
+		assert.Less(t, time.Since(started), 500*time.Millisecond)
+	})
+
+	t.Run("[MctsCpuPlayer: キャンセルされたcontext]", func(t *testing.T) {
+		game := newCpuTestGame(t, cornerPositions, centerPositions)
+		view, err := domain.NewGameView(game, "p1", nil)
+		assert.NoError(t, err)
+		cpu := NewMctsCpuPlayer(rand.NewSource(1), MctsConfig{TimeBudget: time.Minute})
+		canceled, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		defer cancel()
+		_, err = cpu.Decide(canceled, view)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("[MctsCpuPlayer: 手番でなければ行動しない]", func(t *testing.T) {
+		game := newCpuTestGame(t, cornerPositions, centerPositions)
+		view, err := domain.NewGameView(game, "p2", nil)
+		assert.NoError(t, err)
+		_, err = NewMctsCpuPlayer(rand.NewSource(1), MctsConfig{}).Decide(ctx, view)
+		assert.ErrorIs(t, err, shared.ErrInvalidTurn)
+	})
+}
+
+// 同じ CPU 同士で対戦させ, 決めた行動が一度も拒否されないことを確認する.
+func TestMctsActions(t *testing.T) {
+	game := newCpuTestGame(t, cornerPositions, centerPositions)
+	moves := game.GetBoard().LegalMoves("p1")
+	moveKeys := func(actions []mctsAction) []string {
+		keys := make([]string, 0, len(actions))
+		for _, action := range actions {
+			if actionType, err := action.command.GetActionType(); err == nil && actionType == shared.Move {
+				keys = append(keys, action.key)
+			}
+		}
+		return keys
+	}
+
+	t.Run("[mctsActions: 自分の移動は潜水艦ごとに区別する]", func(t *testing.T) {
+		actions, err := mctsActions(game, "p1")
+		assert.NoError(t, err)
+		keys := moveKeys(actions)
+		assert.Len(t, keys, len(moves))
+		assert.Contains(t, keys, fmt.Sprintf("move %s %s %d", moves[0].GetSubmarine().GetId(), moves[0].GetDirection(), moves[0].GetDistance()))
+	})
+
+	t.Run("[mctsActions: 相手の移動は方向と距離だけで区別する]", func(t *testing.T) {
+		actions, err := mctsActions(game, "p2")
+		assert.NoError(t, err)
+		expected := make([]string, 0)
+		seen := make(map[string]bool)
+		for _, move := range moves {
+			key := fmt.Sprintf("move %s %d", move.GetDirection(), move.GetDistance())
+			if !seen[key] {
+				seen[key] = true
+				expected = append(expected, key)
+			}
+		}
+		assert.Less(t, len(expected), len(moves))
+		assert.Equal(t, expected, moveKeys(actions))
+	})
+}
+
+func TestMctsCpuPlayerPlaysLegalGame(t *testing.T) {
+	assert.NotEmpty(t, playCpuTestGame(t, NewMctsCpuPlayer(rand.NewSource(1), MctsConfig{Iterations: 30}), 60))
+}
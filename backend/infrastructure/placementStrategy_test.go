@@ -0,0 +1,108 @@
+package infrastructure
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"backend/domain"
+	"backend/domain/interfaces"
+	"backend/domain/shared"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func positionsToCells(t *testing.T, positions []*domain.Position) []int {
+	t.Helper()
+	cells := make([]int, 0, len(positions))
+	for _, position := range positions {
+		x, y, err := position.GetPosition()
+		assert.NoError(t, err)
+		cells = append(cells, (y-shared.MinPosition)*placementBoardSize+x-shared.MinPosition)
+	}
+	return cells
+}
+
+func TestPlacementStrategies(t *testing.T) {
+	ctx := context.Background()
+	testList := []struct {
+		name     string
+		newFunc  func(source rand.Source) interfaces.PlacementStrategy
+		validate func(t *testing.T, cells []int)
+	}{
+		{
+			name:    "[RandomPlacement]",
+			newFunc: func(source rand.Source) interfaces.PlacementStrategy { return NewRandomPlacement(source) },
+		},
+		{
+			name:    "[SpreadOutPlacement: 四隅に置く]",
+			newFunc: func(source rand.Source) interfaces.PlacementStrategy { return NewSpreadOutPlacement(source) },
+			validate: func(t *testing.T, cells []int) {
+				assert.ElementsMatch(t, []int{0, 4, 20, 24}, cells)
+			},
+		},
+		{
+			name:    "[AntiHeuristicPlacement: 隣り合わず下の行に寄せる]",
+			newFunc: func(source rand.Source) interfaces.PlacementStrategy { return NewAntiHeuristicPlacement(source) },
+			validate: func(t *testing.T, cells []int) {
+				assert.False(t, hasAdjacentCells(cells), "%v", cells)
+				assert.Greater(t, cellIndexSum(cells), 4*12)
+			},
+		},
+	}
+	for _, tl := range testList {
+		t.Run(tl.name, func(t *testing.T) {
+			placement := tl.newFunc(rand.NewSource(1))
+			for i := 0; i < 50; i++ {
+				positions, err := placement.Place(ctx)
+				assert.NoError(t, err)
+				cells := positionsToCells(t, positions)
+				assert.Len(t, cells, shared.SubmarineCount)
+				board := domain.NewBoard()
+				for _, position := range positions {
+					_, err := board.PlaceSubmarine("p1", position)
+					assert.NoError(t, err)
+				}
+				if tl.validate != nil {
+					tl.validate(t, cells)
+				}
+			}
+
+			first, err := tl.newFunc(rand.NewSource(7)).Place(ctx)
+			assert.NoError(t, err)
+			second, err := tl.newFunc(rand.NewSource(7)).Place(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, first, second)
+
+			canceled, cancel := context.WithCancel(ctx)
+			cancel()
+			_, err = placement.Place(canceled)
+			assert.ErrorIs(t, err, context.Canceled)
+		})
+	}
+}
+
+func TestPlacementVariety(t *testing.T) {
+	ctx := context.Background()
+	for name, placement := range map[string]interfaces.PlacementStrategy{
+		"random":        NewRandomPlacement(rand.NewSource(1)),
+		"antiHeuristic": NewAntiHeuristicPlacement(rand.NewSource(1)),
+	} {
+		placed := make(map[string]bool)
+		for i := 0; i < 20; i++ {
+			positions, err := placement.Place(ctx)
+			assert.NoError(t, err)
+			placed[describePositions(t, positions)] = true
+		}
+		assert.Greater(t, len(placed), 1, name)
+	}
+}
+
+func describePositions(t *testing.T, positions []*domain.Position) string {
+	t.Helper()
+	described := ""
+	for _, cell := range positionsToCells(t, positions) {
+		described += string(rune('a' + cell))
+	}
+	return described
+}
@@ -0,0 +1,61 @@
+package infrastructure
+
+import (
+	"backend/domain"
+	"backend/domain/shared"
+	"context"
+)
+
+// ProbabilisticCpuPlayer は HeuristicCpuPlayer と同じ規則で行動するが, 攻撃先は行動記録と矛盾しない
+// 敵艦隊の配置を全て数え上げた ExactPredictionBoard の存在確率で選ぶ.
+type ProbabilisticCpuPlayer struct {
+	aggression    float64
+	moveThreshold float64
+	discountRate  float64
+}
+
+func NewProbabilisticCpuPlayer() *ProbabilisticCpuPlayer {
+	return &ProbabilisticCpuPlayer{
+		aggression:    domain.DefaultAggression,
+		moveThreshold: domain.DefaultMoveThreshold,
+		discountRate:  domain.DefaultDiscountRate,
+	}
+}
+
+// NewProbabilisticCpuPlayerWithProfile は profile のパラメータで ProbabilisticCpuPlayer を作る. 名前は確認しない.
+func NewProbabilisticCpuPlayerWithProfile(profile *domain.CpuProfile) (*ProbabilisticCpuPlayer, error) {
+	if profile == nil {
+		return nil, shared.ErrCpuProfileIsNil
+	}
+	return &ProbabilisticCpuPlayer{
+		aggression:    profile.GetAggression(),
+		moveThreshold: profile.GetMoveThreshold(),
+		discountRate:  profile.GetDiscountRate(),
+	}, nil
+}
+
+func (cpu *ProbabilisticCpuPlayer) Decide(ctx context.Context, view *domain.GameView) (*domain.ActionCommand, error) {
+	command, _, err := cpu.DecideWithRationale(ctx, view)
+	return command, err
+}
+
+func (cpu *ProbabilisticCpuPlayer) DecideWithRationale(ctx context.Context, view *domain.GameView) (*domain.ActionCommand, *domain.CpuRationale, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	if view == nil {
+		return nil, nil, shared.ErrGameIsNil
+	}
+	if view.GetCurrentPlayerId() != view.GetViewerId() {
+		return nil, nil, shared.ErrInvalidTurn
+	}
+	prediction, err := view.Prediction(cpu.discountRate)
+	if err != nil {
+		return nil, nil, err
+	}
+	exact, err := view.ExactPrediction(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return decideByRules(view, prediction, exact, cpu.aggression, cpu.moveThreshold, cpu.discountRate)
+}
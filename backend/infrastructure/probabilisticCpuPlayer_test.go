@@ -0,0 +1,41 @@
+package infrastructure
+
+import (
+	"context"
+	"testing"
+
+	"backend/domain"
+	"backend/domain/shared"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProbabilisticCpuPlayerDecide(t *testing.T) {
+	ctx := context.Background()
+	cpu := NewProbabilisticCpuPlayer()
+
+	t.Run("[ProbabilisticCpuPlayer: 命中したマスの敵艦を攻撃し続ける]", func(t *testing.T) {
+		game := newCpuTestGame(t, cornerPositions, centerPositions)
+		applyCpuTestAttack(t, game, "p1", 3, 3)
+		applyCpuTestAttack(t, game, "p2", 4, 3)
+		view, err := domain.NewGameView(game, "p1", game.PullTurnLogs())
+		assert.NoError(t, err)
+
+		command, err := cpu.Decide(ctx, view)
+		assert.NoError(t, err)
+		assert.Equal(t, "attack (3,3)", describeCommand(t, command))
+	})
+
+	t.Run("[ProbabilisticCpuPlayer: 手番でなければ行動しない]", func(t *testing.T) {
+		game := newCpuTestGame(t, cornerPositions, centerPositions)
+		view, err := domain.NewGameView(game, "p2", nil)
+		assert.NoError(t, err)
+		_, err = cpu.Decide(ctx, view)
+		assert.ErrorIs(t, err, shared.ErrInvalidTurn)
+	})
+}
+
+// 同じ CPU 同士で対戦させ, 決めた行動が一度も拒否されないことを確認する.
+func TestProbabilisticCpuPlayerPlaysLegalGame(t *testing.T) {
+	assert.NotEmpty(t, playCpuTestGame(t, NewProbabilisticCpuPlayer(), 200))
+}
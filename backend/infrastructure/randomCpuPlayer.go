@@ -0,0 +1,96 @@
+package infrastructure
+
+import (
+	"backend/domain"
+	"backend/domain/shared"
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+)
+
+// RandomCpuPlayer は正当な攻撃と移動のコマンドから一様に1つを選ぶ.
+// 同じ seed の source を渡せば同じ対戦を再現できる. 複数のgoroutineから同時に利用できる.
+type RandomCpuPlayer struct {
+	mu     sync.Mutex
+	random *rand.Rand
+}
+
+func NewRandomCpuPlayer(source rand.Source) *RandomCpuPlayer {
+	return &RandomCpuPlayer{
+		random: rand.New(source),
+	}
+}
+
+func (cpu *RandomCpuPlayer) Decide(ctx context.Context, view *domain.GameView) (*domain.ActionCommand, error) {
+	command, _, err := cpu.DecideWithRationale(ctx, view)
+	return command, err
+}
+
+func (cpu *RandomCpuPlayer) DecideWithRationale(ctx context.Context, view *domain.GameView) (*domain.ActionCommand, *domain.CpuRationale, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	if view == nil {
+		return nil, nil, shared.ErrGameIsNil
+	}
+	if view.GetCurrentPlayerId() != view.GetViewerId() {
+		return nil, nil, shared.ErrInvalidTurn
+	}
+	commands, err := legalCommands(view)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(commands) == 0 {
+		return nil, nil, shared.ErrNoCandidateCell
+	}
+	cpu.mu.Lock()
+	chosen := commands[cpu.random.Intn(len(commands))]
+	cpu.mu.Unlock()
+
+	candidates := make([]*domain.CpuCandidate, 0, len(commands))
+	for _, command := range commands {
+		target, err := command.GetTarget()
+		if err != nil {
+			return nil, nil, err
+		}
+		candidate, err := domain.NewCpuCandidate(command, target, 1/float64(len(commands)))
+		if err != nil {
+			return nil, nil, err
+		}
+		candidates = append(candidates, candidate)
+	}
+	detail := fmt.Sprintf("uniform over %d legal commands", len(commands))
+	return chosen, domain.NewCpuRationale(shared.CpuTierRandom, detail, candidates, nil), nil
+}
+
+// legalCommands は潜水艦を指定しない正当なコマンドを全て返す.
+// 移動は方向と距離の組み合わせごとに1つとし, いずれかの潜水艦が動けるものだけを含める.
+func legalCommands(view *domain.GameView) ([]*domain.ActionCommand, error) {
+	commands := make([]*domain.ActionCommand, 0)
+	for _, target := range view.LegalAttackTargets() {
+		command, err := domain.NewActionCommand(view.GetViewerId(), shared.Attack, target, shared.DirectionUnknown, 0)
+		if err != nil {
+			return nil, err
+		}
+		commands = append(commands, command)
+	}
+	type moveKey struct {
+		direction shared.Direction
+		distance  int
+	}
+	seen := make(map[moveKey]bool)
+	for _, move := range view.LegalMoves() {
+		key := moveKey{move.GetDirection(), move.GetDistance()}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		command, err := domain.NewActionCommand(view.GetViewerId(), shared.Move, nil, key.direction, key.distance)
+		if err != nil {
+			return nil, err
+		}
+		commands = append(commands, command)
+	}
+	return commands, nil
+}
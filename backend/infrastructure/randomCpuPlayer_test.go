@@ -0,0 +1,125 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"backend/domain"
+	"backend/domain/interfaces"
+	"backend/domain/shared"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// playCpuTestGame は両プレイヤーを cpu に任せて対戦させ, 全ての行動記録を返す.
+func playCpuTestGame(t *testing.T, cpu interfaces.CPUPlayer, maxActions int) []*domain.TurnLog {
+	t.Helper()
+	ctx := context.Background()
+	game := newCpuTestGame(t, cornerPositions, centerPositions)
+	logs := make([]*domain.TurnLog, 0)
+	for i := 0; i < maxActions && !game.IsFinished(); i++ {
+		view, err := domain.NewGameView(game, game.GetCurrentPlayerId(), logs)
+		assert.NoError(t, err)
+		command, err := cpu.Decide(ctx, view)
+		if !assert.NoError(t, err) {
+			break
+		}
+		_, err = game.Apply(command)
+		if !assert.NoError(t, err, "turn=%d", game.GetTurn()) {
+			break
+		}
+		logs = append(logs, game.PullTurnLogs()...)
+	}
+	return logs
+}
+
+func describeTurnLogs(logs []*domain.TurnLog) []string {
+	described := make([]string, 0, len(logs))
+	for _, log := range logs {
+		target := ""
+		if log.GetTarget() != nil {
+			x, y, _ := log.GetTarget().GetPosition()
+			target = fmt.Sprintf("(%d,%d)", x, y)
+		}
+		described = append(described, fmt.Sprintf("%d %s %s%s %s %d %s", log.GetTurn(), log.GetPlayerId(), log.GetActionType(), target, log.GetDirection(), log.GetDistance(), log.GetAttackReport()))
+	}
+	return described
+}
+
+func describeCommand(t *testing.T, command *domain.ActionCommand) string {
+	t.Helper()
+	target, err := command.GetTarget()
+	assert.NoError(t, err)
+	direction, err := command.GetDirection()
+	assert.NoError(t, err)
+	distance, err := command.GetDistance()
+	assert.NoError(t, err)
+	if target == nil {
+		return fmt.Sprintf("move %s %d", direction, distance)
+	}
+	x, y, err := target.GetPosition()
+	assert.NoError(t, err)
+	return fmt.Sprintf("attack (%d,%d)", x, y)
+}
+
+func TestRandomCpuPlayerReproducible(t *testing.T) {
+	first := playCpuTestGame(t, NewRandomCpuPlayer(rand.NewSource(42)), 300)
+	second := playCpuTestGame(t, NewRandomCpuPlayer(rand.NewSource(42)), 300)
+	other := playCpuTestGame(t, NewRandomCpuPlayer(rand.NewSource(7)), 300)
+
+	assert.NotEmpty(t, first)
+	assert.Equal(t, describeTurnLogs(first), describeTurnLogs(second))
+	assert.NotEqual(t, describeTurnLogs(first), describeTurnLogs(other))
+}
+
+func TestRandomCpuPlayerUniform(t *testing.T) {
+	game := newCpuTestGame(t, cornerPositions, centerPositions)
+	view, err := domain.NewGameView(game, "p1", nil)
+	assert.NoError(t, err)
+	commands, err := legalCommands(view)
+	assert.NoError(t, err)
+	// 攻撃5マスと, 東・南へ1マスか2マスの移動4通り.
+	assert.Len(t, commands, 9)
+
+	cpu := NewRandomCpuPlayer(rand.NewSource(1))
+	counts := make(map[string]int)
+	const samples = 9000
+	for i := 0; i < samples; i++ {
+		command, err := cpu.Decide(context.Background(), view)
+		assert.NoError(t, err)
+		counts[describeCommand(t, command)]++
+	}
+	assert.Len(t, counts, len(commands))
+	expected := float64(samples) / float64(len(commands))
+	for key, count := range counts {
+		assert.InDelta(t, expected, float64(count), expected*0.2, key)
+	}
+}
+
+func TestRandomCpuPlayerFail(t *testing.T) {
+	cpu := NewRandomCpuPlayer(rand.NewSource(1))
+	game := newCpuTestGame(t, cornerPositions, centerPositions)
+	view, err := domain.NewGameView(game, "p2", nil)
+	assert.NoError(t, err)
+	_, err = cpu.Decide(context.Background(), view)
+	assert.ErrorIs(t, err, shared.ErrInvalidTurn)
+
+	_, err = cpu.Decide(context.Background(), nil)
+	assert.ErrorIs(t, err, shared.ErrGameIsNil)
+}
+
+func TestRandomCpuPlayerRationale(t *testing.T) {
+	game := newCpuTestGame(t, cornerPositions, centerPositions)
+	view, err := domain.NewGameView(game, "p1", nil)
+	assert.NoError(t, err)
+	_, rationale, err := NewRandomCpuPlayer(rand.NewSource(1)).DecideWithRationale(context.Background(), view)
+	assert.NoError(t, err)
+	assert.Equal(t, shared.CpuTierRandom, rationale.GetTier())
+	assert.Len(t, rationale.GetCandidates(), 9)
+	for _, candidate := range rationale.GetCandidates() {
+		assert.InDelta(t, 1.0/9, candidate.GetScore(), 1e-9)
+	}
+	assert.Empty(t, rationale.GetPrediction())
+}
@@ -0,0 +1,66 @@
+package infrastructure
+
+import (
+	"backend/domain"
+	"backend/domain/shared"
+	"context"
+	"math/rand"
+	"sync"
+)
+
+const placementBoardSize = shared.MaxPosition - shared.MinPosition + 1
+
+// RandomPlacement は盤面の全マスから一様に選んだ配置を返す. 複数のgoroutineから同時に利用できる.
+type RandomPlacement struct {
+	mu     sync.Mutex
+	random *rand.Rand
+}
+
+func NewRandomPlacement(source rand.Source) *RandomPlacement {
+	return &RandomPlacement{
+		random: rand.New(source),
+	}
+}
+
+func (placement *RandomPlacement) Place(ctx context.Context) ([]*domain.Position, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	placement.mu.Lock()
+	cells := placement.random.Perm(placementBoardSize * placementBoardSize)[:shared.SubmarineCount]
+	placement.mu.Unlock()
+	return cellsToPositions(cells)
+}
+
+// placementCandidates は潜水艦の数だけのマスの組み合わせを, マスの番号の昇順で全て返す.
+// マスの番号は左上を0として行ごとに数える.
+func placementCandidates() [][]int {
+	candidates := make([][]int, 0)
+	cells := make([]int, 0, shared.SubmarineCount)
+	var choose func(from int)
+	choose = func(from int) {
+		if len(cells) == shared.SubmarineCount {
+			candidates = append(candidates, append([]int(nil), cells...))
+			return
+		}
+		for cell := from; cell < placementBoardSize*placementBoardSize; cell++ {
+			cells = append(cells, cell)
+			choose(cell + 1)
+			cells = cells[:len(cells)-1]
+		}
+	}
+	choose(0)
+	return candidates
+}
+
+func cellsToPositions(cells []int) ([]*domain.Position, error) {
+	positions := make([]*domain.Position, 0, len(cells))
+	for _, cell := range cells {
+		position, err := domain.NewPosition(cell%placementBoardSize+shared.MinPosition, cell/placementBoardSize+shared.MinPosition)
+		if err != nil {
+			return nil, err
+		}
+		positions = append(positions, position)
+	}
+	return positions, nil
+}
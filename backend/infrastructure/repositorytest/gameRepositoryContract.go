@@ -0,0 +1,313 @@
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"backend/domain"
+	"backend/domain/interfaces"
+	"backend/domain/shared"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// RunGameRepositoryContract は GameRepository の共通テストを実行する.
+// newRepository はテストケースごとに空のリポジトリを返す.
+func RunGameRepositoryContract(t *testing.T, newRepository func(t *testing.T) interfaces.GameRepository) {
+	ctx := context.Background()
+
+	t.Run("[GameRepository: 保存されていなければErrGameNotFound]", func(t *testing.T) {
+		repository := newRepository(t)
+		game, err := repository.FindByID(ctx, "g1")
+		assert.Nil(t, game)
+		assert.ErrorIs(t, err, shared.ErrGameNotFound)
+	})
+
+	t.Run("[GameRepository: 保存した対戦を取得できる]", func(t *testing.T) {
+		repository := newRepository(t)
+		expected := NewPlayedGame(t, "g1")
+		assert.NoError(t, repository.Save(ctx, expected))
+
+		game, err := repository.FindByID(ctx, "g1")
+		assert.NoError(t, err)
+		AssertGamesEqual(t, expected, game)
+	})
+
+	t.Run("[GameRepository: 開始前の対戦を保存できる]", func(t *testing.T) {
+		repository := newRepository(t)
+		board := domain.NewBoard()
+		expected, err := domain.NewGame("g1", "p1", "p2", board)
+		assert.NoError(t, err)
+		assert.NoError(t, repository.Save(ctx, expected))
+
+		game, err := repository.FindByID(ctx, "g1")
+		assert.NoError(t, err)
+		AssertGamesEqual(t, expected, game)
+		assert.Nil(t, game.GetCpuProfile())
+	})
+
+	t.Run("[GameRepository: 上書き保存]", func(t *testing.T) {
+		repository := newRepository(t)
+		game := NewPlayedGame(t, "g1")
+		assert.NoError(t, repository.Save(ctx, game))
+		applyContractAttack(t, game, "p1", 3, 3)
+		assert.NoError(t, repository.Save(ctx, game))
+
+		found, err := repository.FindByID(ctx, "g1")
+		assert.NoError(t, err)
+		AssertGamesEqual(t, game, found)
+	})
+
+	t.Run("[GameRepository: 保存するたびにバージョンが進む]", func(t *testing.T) {
+		repository := newRepository(t)
+		game := NewPlayedGame(t, "g1")
+		assert.Equal(t, 0, game.GetVersion())
+		assert.NoError(t, repository.Save(ctx, game))
+		assert.Equal(t, 1, game.GetVersion())
+		assert.NoError(t, repository.Save(ctx, game))
+		assert.Equal(t, 2, game.GetVersion())
+
+		found, err := repository.FindByID(ctx, "g1")
+		assert.NoError(t, err)
+		assert.Equal(t, 2, found.GetVersion())
+	})
+
+	t.Run("[GameRepository: 読み込んだ後に更新されていればErrConcurrentModification]", func(t *testing.T) {
+		repository := newRepository(t)
+		assert.NoError(t, repository.Save(ctx, NewPlayedGame(t, "g1")))
+		first, err := repository.FindByID(ctx, "g1")
+		assert.NoError(t, err)
+		second, err := repository.FindByID(ctx, "g1")
+		assert.NoError(t, err)
+
+		_, err = first.GetBoard().MoveSubmarine("p1", "p1-sub-4", shared.East, 1)
+		assert.NoError(t, err)
+		assert.NoError(t, repository.Save(ctx, first))
+		assert.NoError(t, second.SetDebug(false))
+		assert.ErrorIs(t, repository.Save(ctx, second), shared.ErrConcurrentModification)
+		assert.Equal(t, 1, second.GetVersion())
+
+		found, err := repository.FindByID(ctx, "g1")
+		assert.NoError(t, err)
+		AssertGamesEqual(t, first, found)
+	})
+
+	t.Run("[GameRepository: 保存済みの対戦を新しい対戦で上書きできない]", func(t *testing.T) {
+		repository := newRepository(t)
+		assert.NoError(t, repository.Save(ctx, NewPlayedGame(t, "g1")))
+		assert.ErrorIs(t, repository.Save(ctx, NewPlayedGame(t, "g1")), shared.ErrConcurrentModification)
+	})
+
+	t.Run("[GameRepository: 同時に保存しても更新は失われない]", func(t *testing.T) {
+		repository := newRepository(t)
+		assert.NoError(t, repository.Save(ctx, NewPlayedGame(t, "g1")))
+
+		const workers, savesPerWorker = 8, 5
+		var (
+			wg       sync.WaitGroup
+			mu       sync.Mutex
+			versions []int
+		)
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for saved := 0; saved < savesPerWorker; {
+					game, err := repository.FindByID(ctx, "g1")
+					if !assert.NoError(t, err) {
+						return
+					}
+					assert.NoError(t, game.SetDebug(!game.IsDebug()))
+					err = repository.Save(ctx, game)
+					if errors.Is(err, shared.ErrConcurrentModification) {
+						continue
+					}
+					if !assert.NoError(t, err) {
+						return
+					}
+					mu.Lock()
+					versions = append(versions, game.GetVersion())
+					mu.Unlock()
+					saved++
+				}
+			}()
+		}
+		wg.Wait()
+
+		// 成功した保存はそれぞれ異なるバージョンを得る. 同じバージョンが2回現れれば, どちらかの更新が失われている.
+		sort.Ints(versions)
+		expected := make([]int, 0, workers*savesPerWorker)
+		for version := 2; version <= workers*savesPerWorker+1; version++ {
+			expected = append(expected, version)
+		}
+		assert.Equal(t, expected, versions)
+		found, err := repository.FindByID(ctx, "g1")
+		assert.NoError(t, err)
+		assert.Equal(t, workers*savesPerWorker+1, found.GetVersion())
+		assert.Equal(t, (workers*savesPerWorker)%2 == 0, found.IsDebug())
+	})
+
+	t.Run("[GameRepository: 保存後や取得後の変更は影響しない]", func(t *testing.T) {
+		repository := newRepository(t)
+		game := NewPlayedGame(t, "g1")
+		assert.NoError(t, repository.Save(ctx, game))
+		expected := game.Clone()
+		applyContractAttack(t, game, "p1", 3, 3)
+
+		found, err := repository.FindByID(ctx, "g1")
+		assert.NoError(t, err)
+		AssertGamesEqual(t, expected, found)
+
+		// 読み込んだ対戦の時計は保存先によって異なるため, Apply を使わずに変更する.
+		assert.NoError(t, found.SetDebug(false))
+		_, err = found.GetBoard().MoveSubmarine("p1", "p1-sub-4", shared.East, 1)
+		assert.NoError(t, err)
+		found, err = repository.FindByID(ctx, "g1")
+		assert.NoError(t, err)
+		AssertGamesEqual(t, expected, found)
+	})
+
+	t.Run("[GameRepository: 取り出していないTurnLogは保存しない]", func(t *testing.T) {
+		repository := newRepository(t)
+		game := NewPlayedGame(t, "g1")
+		applyContractAttack(t, game, "p1", 3, 3)
+		assert.NoError(t, repository.Save(ctx, game))
+
+		found, err := repository.FindByID(ctx, "g1")
+		assert.NoError(t, err)
+		assert.Empty(t, found.PullTurnLogs())
+		assert.Len(t, game.PullTurnLogs(), 1)
+	})
+
+	t.Run("[GameRepository: 対戦ごとに保存される]", func(t *testing.T) {
+		repository := newRepository(t)
+		assert.NoError(t, repository.Save(ctx, NewPlayedGame(t, "g1")))
+		assert.NoError(t, repository.Save(ctx, NewPlayedGame(t, "g2")))
+		assert.NoError(t, repository.Delete(ctx, "g1"))
+
+		_, err := repository.FindByID(ctx, "g1")
+		assert.ErrorIs(t, err, shared.ErrGameNotFound)
+		found, err := repository.FindByID(ctx, "g2")
+		assert.NoError(t, err)
+		assert.Equal(t, shared.GameId("g2"), found.GetId())
+	})
+
+	t.Run("[GameRepository: 保存されていない対戦は削除できない]", func(t *testing.T) {
+		repository := newRepository(t)
+		assert.ErrorIs(t, repository.Delete(ctx, "g1"), shared.ErrGameNotFound)
+	})
+
+	t.Run("[GameRepository: 不正な引数]", func(t *testing.T) {
+		repository := newRepository(t)
+		assert.ErrorIs(t, repository.Save(ctx, nil), shared.ErrGameIsNil)
+	})
+
+	t.Run("[GameRepository: キャンセルされたcontext]", func(t *testing.T) {
+		repository := newRepository(t)
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+		assert.ErrorIs(t, repository.Save(canceled, NewPlayedGame(t, "g1")), context.Canceled)
+		_, err := repository.FindByID(canceled, "g1")
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.ErrorIs(t, repository.Delete(canceled, "g1"), context.Canceled)
+	})
+}
+
+// NewPlayedGame は CPU の設定と制限時間を持ち, 攻撃と移動を1回ずつ終えた対戦を生成する.
+// 取り出していない TurnLog は残さない.
+func NewPlayedGame(t *testing.T, gameId shared.GameId) *domain.Game {
+	t.Helper()
+	board := domain.NewBoard()
+	for playerId, positions := range map[shared.PlayerId][][2]int{
+		"p1": {{1, 1}, {2, 1}, {1, 2}, {2, 2}},
+		"p2": {{3, 3}, {4, 4}, {5, 5}, {5, 4}},
+	} {
+		for _, xy := range positions {
+			position, err := domain.NewPosition(xy[0], xy[1])
+			assert.NoError(t, err)
+			_, err = board.PlaceSubmarine(playerId, position)
+			assert.NoError(t, err)
+		}
+	}
+	game, err := domain.NewGame(gameId, "p1", "p2", board)
+	assert.NoError(t, err)
+	profile, err := domain.NewCpuProfileWithParams(shared.CpuProbabilistic, 0.25, 0.6, 0.9)
+	assert.NoError(t, err)
+	assert.NoError(t, game.SetCpuProfile(profile))
+	assert.NoError(t, game.SetDebug(true))
+	assert.NoError(t, game.SetClock(contractClock{time.Date(2026, 4, 1, 10, 0, 0, 123456789, time.UTC)}))
+	assert.NoError(t, game.SetMatchDuration(15*time.Minute))
+	assert.NoError(t, game.SetTurnTimeLimit(30*time.Second, shared.TurnTimeoutForfeit))
+	assert.NoError(t, game.Start())
+
+	applyContractAttack(t, game, "p1", 3, 3)
+	command, err := domain.NewActionCommandWithSubmarine("p2", "p2-sub-2", shared.Move, nil, shared.West, 1)
+	assert.NoError(t, err)
+	_, err = game.Apply(command)
+	assert.NoError(t, err)
+	game.PullTurnLogs()
+	return game
+}
+
+// AssertGamesEqual は保存先による表現の違いを除いて対戦の状態とバージョンを比較する. 時計と TurnLog は比較しない.
+func AssertGamesEqual(t *testing.T, expected *domain.Game, actual *domain.Game) {
+	t.Helper()
+	if !assert.NotNil(t, actual) {
+		return
+	}
+	assert.Equal(t, expected.GetId(), actual.GetId())
+	assert.Equal(t, expected.GetStatus(), actual.GetStatus())
+	assert.Equal(t, expected.GetTurn(), actual.GetTurn())
+	assert.Equal(t, expected.GetPlayerAId(), actual.GetPlayerAId())
+	assert.Equal(t, expected.GetPlayerBId(), actual.GetPlayerBId())
+	assert.Equal(t, expected.GetCurrentPlayerId(), actual.GetCurrentPlayerId())
+	assert.Equal(t, expected.GetWinnerId(), actual.GetWinnerId())
+	assert.Equal(t, expected.GetFinishReason(), actual.GetFinishReason())
+	assert.Equal(t, expected.GetMatchDuration(), actual.GetMatchDuration())
+	assert.Equal(t, expected.GetTurnTimeLimit(), actual.GetTurnTimeLimit())
+	assert.Equal(t, expected.GetTurnTimeoutPolicy(), actual.GetTurnTimeoutPolicy())
+	assert.Equal(t, expected.GetCpuProfile(), actual.GetCpuProfile())
+	assert.Equal(t, expected.IsDebug(), actual.IsDebug())
+	assert.Equal(t, expected.GetVersion(), actual.GetVersion())
+	assert.True(t, expected.GetStartedAt().Equal(actual.GetStartedAt()), "startedAt")
+	assert.True(t, expected.GetTurnStartedAt().Equal(actual.GetTurnStartedAt()), "turnStartedAt")
+	assert.True(t, expected.GetCreatedAt().Equal(actual.GetCreatedAt()), "createdAt")
+	assert.True(t, expected.GetUpdatedAt().Equal(actual.GetUpdatedAt()), "updatedAt")
+
+	for _, playerId := range []shared.PlayerId{expected.GetPlayerAId(), expected.GetPlayerBId()} {
+		expectedSubmarines := expected.GetBoard().GetAllySubmarines(playerId)
+		actualSubmarines := actual.GetBoard().GetAllySubmarines(playerId)
+		if !assert.Len(t, actualSubmarines, len(expectedSubmarines)) {
+			continue
+		}
+		for i := range expectedSubmarines {
+			assert.Equal(t, expectedSubmarines[i].GetId(), actualSubmarines[i].GetId())
+			assert.Equal(t, expectedSubmarines[i].GetOwnerId(), actualSubmarines[i].GetOwnerId())
+			assert.Equal(t, expectedSubmarines[i].GetPosition(), actualSubmarines[i].GetPosition())
+			assert.Equal(t, expectedSubmarines[i].GetHp(), actualSubmarines[i].GetHp())
+		}
+	}
+}
+
+func applyContractAttack(t *testing.T, game *domain.Game, playerId shared.PlayerId, x int, y int) {
+	t.Helper()
+	target, err := domain.NewPosition(x, y)
+	assert.NoError(t, err)
+	command, err := domain.NewActionCommand(playerId, shared.Attack, target, shared.DirectionUnknown, 0)
+	assert.NoError(t, err)
+	_, err = game.Apply(command)
+	assert.NoError(t, err)
+}
+
+// contractClock は常に同じ時刻を返す時計. 保存先が時刻を秒未満まで保つことを確認するため, ナノ秒を含める.
+type contractClock struct {
+	now time.Time
+}
+
+func (clock contractClock) Now() time.Time {
+	return clock.now
+}
@@ -0,0 +1,73 @@
+package repositorytest
+
+import (
+	"context"
+	"testing"
+
+	"backend/domain/interfaces"
+	"backend/domain/shared"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// RunPlayerGamesIndexRepositoryContract は PlayerGamesIndexRepository の共通テストを実行する.
+// newRepository はテストケースごとに空のリポジトリを返す.
+func RunPlayerGamesIndexRepositoryContract(t *testing.T, newRepository func(t *testing.T) interfaces.PlayerGamesIndexRepository) {
+	ctx := context.Background()
+
+	t.Run("[PlayerGamesIndexRepository: 追加していなければ空]", func(t *testing.T) {
+		repository := newRepository(t)
+		gameIds, err := repository.ListGames(ctx, "p1")
+		assert.NoError(t, err)
+		assert.NotNil(t, gameIds)
+		assert.Empty(t, gameIds)
+	})
+
+	t.Run("[PlayerGamesIndexRepository: 追加した対戦をid順に取得できる]", func(t *testing.T) {
+		repository := newRepository(t)
+		for _, gameId := range []shared.GameId{"g3", "g1", "g2", "g1"} {
+			assert.NoError(t, repository.AddGame(ctx, "p1", gameId))
+		}
+		assert.NoError(t, repository.AddGame(ctx, "p2", "g9"))
+
+		gameIds, err := repository.ListGames(ctx, "p1")
+		assert.NoError(t, err)
+		assert.Equal(t, []shared.GameId{"g1", "g2", "g3"}, gameIds)
+	})
+
+	t.Run("[PlayerGamesIndexRepository: 取り除いた対戦は含まれない]", func(t *testing.T) {
+		repository := newRepository(t)
+		assert.NoError(t, repository.AddGame(ctx, "p1", "g1"))
+		assert.NoError(t, repository.AddGame(ctx, "p1", "g2"))
+		assert.NoError(t, repository.RemoveGame(ctx, "p1", "g1"))
+		assert.NoError(t, repository.RemoveGame(ctx, "p1", "g-missing"))
+		assert.NoError(t, repository.RemoveGame(ctx, "p-missing", "g2"))
+
+		gameIds, err := repository.ListGames(ctx, "p1")
+		assert.NoError(t, err)
+		assert.Equal(t, []shared.GameId{"g2"}, gameIds)
+
+		assert.NoError(t, repository.RemoveGame(ctx, "p1", "g2"))
+		gameIds, err = repository.ListGames(ctx, "p1")
+		assert.NoError(t, err)
+		assert.Empty(t, gameIds)
+	})
+
+	t.Run("[PlayerGamesIndexRepository: 不正な引数]", func(t *testing.T) {
+		repository := newRepository(t)
+		assert.ErrorIs(t, repository.AddGame(ctx, "", "g1"), shared.ErrInvalidPlayerID)
+		assert.ErrorIs(t, repository.AddGame(ctx, "p1", ""), shared.ErrInvalidGameId)
+		assert.ErrorIs(t, repository.RemoveGame(ctx, "", "g1"), shared.ErrInvalidPlayerID)
+		assert.ErrorIs(t, repository.RemoveGame(ctx, "p1", ""), shared.ErrInvalidGameId)
+	})
+
+	t.Run("[PlayerGamesIndexRepository: キャンセルされたcontext]", func(t *testing.T) {
+		repository := newRepository(t)
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+		assert.ErrorIs(t, repository.AddGame(canceled, "p1", "g1"), context.Canceled)
+		assert.ErrorIs(t, repository.RemoveGame(canceled, "p1", "g1"), context.Canceled)
+		_, err := repository.ListGames(canceled, "p1")
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
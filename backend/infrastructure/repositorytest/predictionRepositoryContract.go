@@ -0,0 +1,118 @@
+package repositorytest
+
+import (
+	"context"
+	"testing"
+
+	"backend/domain"
+	"backend/domain/interfaces"
+	"backend/domain/shared"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// RunPredictionRepositoryContract は PredictionRepository の共通テストを実行する.
+// newRepository はテストケースごとに空のリポジトリを返す.
+func RunPredictionRepositoryContract(t *testing.T, newRepository func(t *testing.T) interfaces.PredictionRepository) {
+	ctx := context.Background()
+
+	t.Run("[PredictionRepository: 保存されていなければErrPredictionBoardNotFound]", func(t *testing.T) {
+		repository := newRepository(t)
+		board, err := repository.Find(ctx, "g1", "p1")
+		assert.Nil(t, board)
+		assert.ErrorIs(t, err, shared.ErrPredictionBoardNotFound)
+	})
+
+	t.Run("[PredictionRepository: 保存した存在確率マップを取得できる]", func(t *testing.T) {
+		repository := newRepository(t)
+		expected := newMarkedPredictionBoard(t)
+		assert.NoError(t, repository.Save(ctx, "g1", "p1", expected))
+
+		board, err := repository.Find(ctx, "g1", "p1")
+		assert.NoError(t, err)
+		AssertPredictionBoardsEqual(t, expected, board)
+	})
+
+	t.Run("[PredictionRepository: プレイヤーごとに保存される]", func(t *testing.T) {
+		repository := newRepository(t)
+		marked := newMarkedPredictionBoard(t)
+		assert.NoError(t, repository.Save(ctx, "g1", "p1", marked))
+		assert.NoError(t, repository.Save(ctx, "g1", "p2", domain.NewPredictionBoard()))
+
+		board, err := repository.Find(ctx, "g1", "p2")
+		assert.NoError(t, err)
+		AssertPredictionBoardsEqual(t, domain.NewPredictionBoard(), board)
+		_, err = repository.Find(ctx, "g2", "p1")
+		assert.ErrorIs(t, err, shared.ErrPredictionBoardNotFound)
+	})
+
+	t.Run("[PredictionRepository: 上書き保存]", func(t *testing.T) {
+		repository := newRepository(t)
+		board := newMarkedPredictionBoard(t)
+		assert.NoError(t, repository.Save(ctx, "g1", "p1", board))
+		assert.NoError(t, board.AdvanceTurn(8))
+		target, err := domain.NewPosition(1, 1)
+		assert.NoError(t, err)
+		assert.NoError(t, board.MarkMiss(target))
+		assert.NoError(t, repository.Save(ctx, "g1", "p1", board))
+
+		found, err := repository.Find(ctx, "g1", "p1")
+		assert.NoError(t, err)
+		AssertPredictionBoardsEqual(t, board, found)
+	})
+
+	t.Run("[PredictionRepository: 保存後の変更は影響しない]", func(t *testing.T) {
+		repository := newRepository(t)
+		board := newMarkedPredictionBoard(t)
+		expected := board.Clone()
+		assert.NoError(t, repository.Save(ctx, "g1", "p1", board))
+		assert.NoError(t, board.AdvanceTurn(10))
+
+		found, err := repository.Find(ctx, "g1", "p1")
+		assert.NoError(t, err)
+		AssertPredictionBoardsEqual(t, expected, found)
+	})
+
+	t.Run("[PredictionRepository: 不正な引数]", func(t *testing.T) {
+		repository := newRepository(t)
+		assert.ErrorIs(t, repository.Save(ctx, "", "p1", domain.NewPredictionBoard()), shared.ErrInvalidGameId)
+		assert.ErrorIs(t, repository.Save(ctx, "g1", "", domain.NewPredictionBoard()), shared.ErrInvalidPlayerID)
+		assert.ErrorIs(t, repository.Save(ctx, "g1", "p1", nil), shared.ErrPredictionBoardIsNil)
+	})
+
+	t.Run("[PredictionRepository: キャンセルされたcontext]", func(t *testing.T) {
+		repository := newRepository(t)
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+		assert.ErrorIs(t, repository.Save(canceled, "g1", "p1", domain.NewPredictionBoard()), context.Canceled)
+		_, err := repository.Find(canceled, "g1", "p1")
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+// AssertPredictionBoardsEqual は2つの存在確率マップのターン, 割引率, 各マスの確率が等しいことを確認する.
+func AssertPredictionBoardsEqual(t *testing.T, expected *domain.PredictionBoard, actual *domain.PredictionBoard) {
+	t.Helper()
+	if !assert.NotNil(t, actual) {
+		return
+	}
+	assert.Equal(t, expected.GetCurrentTurn(), actual.GetCurrentTurn())
+	assert.Equal(t, expected.GetDiscountRate(), actual.GetDiscountRate())
+	expectedGrid := expected.Grid()
+	actualGrid := actual.Grid()
+	for y := range expectedGrid {
+		assert.InDeltaSlice(t, expectedGrid[y], actualGrid[y], 1e-9, "y=%d", y+shared.MinPosition)
+	}
+}
+
+func newMarkedPredictionBoard(t *testing.T) *domain.PredictionBoard {
+	t.Helper()
+	board := domain.NewPredictionBoard()
+	target, err := domain.NewPosition(3, 3)
+	assert.NoError(t, err)
+	assert.NoError(t, board.AdvanceTurn(1))
+	assert.NoError(t, board.MarkHighWave(target))
+	assert.NoError(t, board.AdvanceTurn(2))
+	assert.NoError(t, board.MarkEnemyMove(shared.East, 1))
+	return board
+}
@@ -0,0 +1,143 @@
+// Package repositorytest はリポジトリの実装が満たすべき振る舞いをまとめたテストスイートを提供する.
+// 新しい保存先を追加する場合は, その実装のテストからここの関数を呼び出す.
+package repositorytest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"backend/domain"
+	"backend/domain/interfaces"
+	"backend/domain/shared"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// RunTurnLogRepositoryContract は TurnLogRepository の共通テストを実行する.
+// newRepository はテストケースごとに空のリポジトリを返す.
+func RunTurnLogRepositoryContract(t *testing.T, newRepository func(t *testing.T) interfaces.TurnLogRepository) {
+	ctx := context.Background()
+
+	t.Run("[TurnLogRepository: 記録がなければ空]", func(t *testing.T) {
+		repository := newRepository(t)
+		logs, err := repository.FindByGameId(ctx, "g-empty")
+		assert.NoError(t, err)
+		assert.Empty(t, logs)
+	})
+
+	t.Run("[TurnLogRepository: 追加した順に全項目を取得できる]", func(t *testing.T) {
+		repository := newRepository(t)
+		expected := []*domain.TurnLog{
+			NewTurnLog(t, "g1", 1, "p1", shared.Attack),
+			NewTurnLog(t, "g1", 1, "p2", shared.Attack),
+			NewTurnLog(t, "g1", 2, "p2", shared.Move),
+		}
+		for _, log := range expected {
+			assert.NoError(t, repository.Append(ctx, "g1", log))
+		}
+		assert.NoError(t, repository.Append(ctx, "g2", NewTurnLog(t, "g2", 1, "p1", shared.Move)))
+
+		logs, err := repository.FindByGameId(ctx, "g1")
+		assert.NoError(t, err)
+		AssertTurnLogsEqual(t, expected, logs)
+	})
+
+	t.Run("[TurnLogRepository: FindRangeはfromTurn以降をlimit件まで返す]", func(t *testing.T) {
+		repository := newRepository(t)
+		all := make([]*domain.TurnLog, 0, 6)
+		for turn := 1; turn <= 6; turn++ {
+			log := NewTurnLog(t, "g1", turn, "p1", shared.Attack)
+			all = append(all, log)
+			assert.NoError(t, repository.Append(ctx, "g1", log))
+		}
+		testList := []struct {
+			name     string
+			fromTurn int
+			limit    int
+			expected []*domain.TurnLog
+		}{
+			{"[FindRange: 先頭から]", 0, 2, all[:2]},
+			{"[FindRange: 途中から]", 3, 2, all[2:4]},
+			{"[FindRange: 末尾を越える]", 5, 10, all[4:]},
+			{"[FindRange: 範囲外]", 7, 10, []*domain.TurnLog{}},
+		}
+		for _, tl := range testList {
+			t.Run(tl.name, func(t *testing.T) {
+				logs, err := repository.FindRange(ctx, "g1", tl.fromTurn, tl.limit)
+				assert.NoError(t, err)
+				AssertTurnLogsEqual(t, tl.expected, logs)
+			})
+		}
+	})
+
+	t.Run("[TurnLogRepository: 不正な入力]", func(t *testing.T) {
+		repository := newRepository(t)
+		assert.ErrorIs(t, repository.Append(ctx, "g1", nil), shared.ErrTurnLogIsNil)
+		assert.ErrorIs(t, repository.Append(ctx, "g2", NewTurnLog(t, "g1", 1, "p1", shared.Attack)), shared.ErrInvalidGameId)
+		_, err := repository.FindRange(ctx, "g1", 0, 0)
+		assert.ErrorIs(t, err, shared.ErrInvalidPageRange)
+		_, err = repository.FindRange(ctx, "g1", -1, 10)
+		assert.ErrorIs(t, err, shared.ErrInvalidPageRange)
+	})
+
+	t.Run("[TurnLogRepository: 同時に追加しても失われない]", func(t *testing.T) {
+		repository := newRepository(t)
+		const writers = 20
+		var wg sync.WaitGroup
+		for i := 0; i < writers; i++ {
+			wg.Add(1)
+			go func(turn int) {
+				defer wg.Done()
+				assert.NoError(t, repository.Append(ctx, "g1", NewTurnLog(t, "g1", turn, "p1", shared.Attack)))
+			}(i + 1)
+		}
+		wg.Wait()
+		logs, err := repository.FindByGameId(ctx, "g1")
+		assert.NoError(t, err)
+		assert.Len(t, logs, writers)
+	})
+}
+
+// NewTurnLog は actionType に応じて妥当な値を埋めたTurnLogを生成する.
+func NewTurnLog(t *testing.T, gameId shared.GameId, turn int, playerId shared.PlayerId, actionType shared.ActionType) *domain.TurnLog {
+	t.Helper()
+	createdAt := time.Date(2026, 4, 1, 10, 0, turn, 0, time.UTC)
+	var log *domain.TurnLog
+	var err error
+	switch actionType {
+	case shared.Attack:
+		target, positionErr := domain.NewPosition(turn%shared.MaxPosition+1, 3)
+		assert.NoError(t, positionErr)
+		log, err = domain.NewTurnLog(gameId, turn, playerId, "", shared.Attack, target, shared.DirectionUnknown, 0, shared.WaveHigh, shared.MoveReportUnknown, shared.ErrorNone, createdAt)
+	default:
+		submarineId := shared.SubmarineId(fmt.Sprintf("%s-sub-1", playerId))
+		log, err = domain.NewTurnLog(gameId, turn, playerId, submarineId, shared.Move, nil, shared.East, 2, shared.AttackReportUnknown, shared.MoveBlocked, shared.InvalidAction, createdAt)
+	}
+	assert.NoError(t, err)
+	return log
+}
+
+// AssertTurnLogsEqual は保存先による表現の違いを除いてTurnLogの全項目を比較する.
+func AssertTurnLogsEqual(t *testing.T, expected []*domain.TurnLog, actual []*domain.TurnLog) {
+	t.Helper()
+	if !assert.Len(t, actual, len(expected)) {
+		return
+	}
+	for i := range expected {
+		assert.Equal(t, expected[i].GetGameId(), actual[i].GetGameId())
+		assert.Equal(t, expected[i].GetTurn(), actual[i].GetTurn())
+		assert.Equal(t, expected[i].GetPlayerId(), actual[i].GetPlayerId())
+		assert.Equal(t, expected[i].GetSubmarineId(), actual[i].GetSubmarineId())
+		assert.Equal(t, expected[i].GetActionType(), actual[i].GetActionType())
+		assert.Equal(t, expected[i].GetTarget(), actual[i].GetTarget())
+		assert.Equal(t, expected[i].GetDirection(), actual[i].GetDirection())
+		assert.Equal(t, expected[i].GetDistance(), actual[i].GetDistance())
+		assert.Equal(t, expected[i].GetAttackReport(), actual[i].GetAttackReport())
+		assert.Equal(t, expected[i].GetMoveReport(), actual[i].GetMoveReport())
+		assert.Equal(t, expected[i].GetErrorCode(), actual[i].GetErrorCode())
+		assert.True(t, expected[i].GetCreatedAt().Equal(actual[i].GetCreatedAt()))
+	}
+}
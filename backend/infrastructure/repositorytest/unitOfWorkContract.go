@@ -0,0 +1,265 @@
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"backend/domain"
+	"backend/domain/interfaces"
+	"backend/domain/shared"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// UnitOfWorkStore は UnitOfWork と, コミットした結果を読むための同じ保存先のリポジトリの組.
+type UnitOfWorkStore struct {
+	UnitOfWork  interfaces.UnitOfWork
+	Games       interfaces.GameRepository
+	TurnLogs    interfaces.TurnLogRepository
+	Predictions interfaces.PredictionRepository
+	PlayerGames interfaces.PlayerGamesIndexRepository
+}
+
+// RunUnitOfWorkContract は UnitOfWork の共通テストを実行する.
+// newStore はテストケースごとに空の保存先を返す.
+func RunUnitOfWorkContract(t *testing.T, newStore func(t *testing.T) UnitOfWorkStore) {
+	ctx := context.Background()
+
+	t.Run("[UnitOfWork: コミットすると全ての書き込みが反映される]", func(t *testing.T) {
+		store := newStore(t)
+		game := NewPlayedGame(t, "g1")
+		logs := []*domain.TurnLog{NewTurnLog(t, "g1", 1, "p1", shared.Attack), NewTurnLog(t, "g1", 2, "p2", shared.Move)}
+		prediction := newMarkedPredictionBoard(t)
+
+		transaction := store.UnitOfWork.Begin()
+		assert.NoError(t, transaction.SaveGame(game))
+		for _, log := range logs {
+			assert.NoError(t, transaction.AppendTurnLog("g1", log))
+		}
+		assert.NoError(t, transaction.SavePrediction("g1", "p1", prediction))
+		assert.NoError(t, transaction.Commit(ctx))
+		assert.Equal(t, 1, game.GetVersion())
+
+		AssertTurnStored(t, store, game, logs, prediction)
+	})
+
+	t.Run("[UnitOfWork: 対戦と参加中の対戦の一覧を一緒に書き込む]", func(t *testing.T) {
+		store := newStore(t)
+		transaction := store.UnitOfWork.Begin()
+		assert.NoError(t, transaction.SaveGame(NewPlayedGame(t, "g1")))
+		assert.NoError(t, transaction.AddPlayerGame("p1", "g1"))
+		assert.NoError(t, transaction.AddPlayerGame("p2", "g1"))
+		assert.NoError(t, transaction.Commit(ctx))
+
+		for _, playerId := range []shared.PlayerId{"p1", "p2"} {
+			gameIds, err := store.PlayerGames.ListGames(ctx, playerId)
+			assert.NoError(t, err)
+			assert.Equal(t, []shared.GameId{"g1"}, gameIds)
+		}
+	})
+
+	t.Run("[UnitOfWork: 削除した対戦は両プレイヤーの参加中の対戦から外れる]", func(t *testing.T) {
+		store := newStore(t)
+		for _, gameId := range []shared.GameId{"g1", "g2"} {
+			transaction := store.UnitOfWork.Begin()
+			assert.NoError(t, transaction.SaveGame(NewPlayedGame(t, gameId)))
+			assert.NoError(t, transaction.AddPlayerGame("p1", gameId))
+			assert.NoError(t, transaction.AddPlayerGame("p2", gameId))
+			assert.NoError(t, transaction.Commit(ctx))
+		}
+		assert.NoError(t, store.Games.Delete(ctx, "g1"))
+
+		for _, playerId := range []shared.PlayerId{"p1", "p2"} {
+			gameIds, err := store.PlayerGames.ListGames(ctx, playerId)
+			assert.NoError(t, err)
+			assert.Equal(t, []shared.GameId{"g2"}, gameIds)
+		}
+	})
+
+	t.Run("[UnitOfWork: コミットするまでは何も書き込まれない]", func(t *testing.T) {
+		store := newStore(t)
+		transaction := store.UnitOfWork.Begin()
+		assert.NoError(t, transaction.SaveGame(NewPlayedGame(t, "g1")))
+		assert.NoError(t, transaction.AppendTurnLog("g1", NewTurnLog(t, "g1", 1, "p1", shared.Attack)))
+		assert.NoError(t, transaction.SavePrediction("g1", "p1", newMarkedPredictionBoard(t)))
+		assert.NoError(t, transaction.AddPlayerGame("p1", "g1"))
+
+		// 途中で失敗してコミットせずに捨てた場合と同じく, どのキーも書き込まれていない.
+		assert.ErrorIs(t, transaction.AppendTurnLog("g1", nil), shared.ErrTurnLogIsNil)
+		AssertTurnNotStored(t, store, "g1")
+	})
+
+	t.Run("[UnitOfWork: バージョンが進んでいればどれも書き込まない]", func(t *testing.T) {
+		store := newStore(t)
+		assert.NoError(t, store.Games.Save(ctx, NewPlayedGame(t, "g1")))
+		stale, err := store.Games.FindByID(ctx, "g1")
+		assert.NoError(t, err)
+		latest, err := store.Games.FindByID(ctx, "g1")
+		assert.NoError(t, err)
+		assert.NoError(t, store.Games.Save(ctx, latest))
+
+		transaction := store.UnitOfWork.Begin()
+		assert.NoError(t, transaction.SaveGame(stale))
+		assert.NoError(t, transaction.AppendTurnLog("g1", NewTurnLog(t, "g1", 3, "p1", shared.Attack)))
+		assert.NoError(t, transaction.SavePrediction("g1", "p1", newMarkedPredictionBoard(t)))
+		assert.NoError(t, transaction.AddPlayerGame("p1", "g1"))
+		assert.ErrorIs(t, transaction.Commit(ctx), shared.ErrConcurrentModification)
+		assert.Equal(t, 1, stale.GetVersion())
+
+		found, err := store.Games.FindByID(ctx, "g1")
+		assert.NoError(t, err)
+		assert.Equal(t, 2, found.GetVersion())
+		logs, err := store.TurnLogs.FindByGameId(ctx, "g1")
+		assert.NoError(t, err)
+		assert.Empty(t, logs)
+		_, err = store.Predictions.Find(ctx, "g1", "p1")
+		assert.ErrorIs(t, err, shared.ErrPredictionBoardNotFound)
+		gameIds, err := store.PlayerGames.ListGames(ctx, "p1")
+		assert.NoError(t, err)
+		assert.Empty(t, gameIds)
+	})
+
+	t.Run("[UnitOfWork: 積んだ時点の状態を書き込む]", func(t *testing.T) {
+		store := newStore(t)
+		game := NewPlayedGame(t, "g1")
+		expected := game.Clone()
+		prediction := newMarkedPredictionBoard(t)
+		expectedPrediction := prediction.Clone()
+
+		transaction := store.UnitOfWork.Begin()
+		assert.NoError(t, transaction.SaveGame(game))
+		assert.NoError(t, transaction.SavePrediction("g1", "p1", prediction))
+		assert.NoError(t, game.SetDebug(false))
+		assert.NoError(t, prediction.AdvanceTurn(5))
+		assert.NoError(t, transaction.Commit(ctx))
+
+		assert.NoError(t, expected.SetVersion(1))
+		AssertTurnStored(t, store, expected, []*domain.TurnLog{}, expectedPrediction)
+	})
+
+	t.Run("[UnitOfWork: 対戦を含まない書き込み]", func(t *testing.T) {
+		store := newStore(t)
+		log := NewTurnLog(t, "g1", 1, "p1", shared.Attack)
+		transaction := store.UnitOfWork.Begin()
+		assert.NoError(t, transaction.AppendTurnLog("g1", log))
+		assert.NoError(t, transaction.Commit(ctx))
+
+		logs, err := store.TurnLogs.FindByGameId(ctx, "g1")
+		assert.NoError(t, err)
+		AssertTurnLogsEqual(t, []*domain.TurnLog{log}, logs)
+		assert.NoError(t, store.UnitOfWork.Begin().Commit(ctx))
+	})
+
+	t.Run("[UnitOfWork: コミットは1回だけ]", func(t *testing.T) {
+		store := newStore(t)
+		transaction := store.UnitOfWork.Begin()
+		assert.NoError(t, transaction.SaveGame(NewPlayedGame(t, "g1")))
+		assert.NoError(t, transaction.Commit(ctx))
+		assert.ErrorIs(t, transaction.Commit(ctx), shared.ErrTransactionClosed)
+		assert.ErrorIs(t, transaction.SaveGame(NewPlayedGame(t, "g1")), shared.ErrTransactionClosed)
+		assert.ErrorIs(t, transaction.AppendTurnLog("g1", NewTurnLog(t, "g1", 1, "p1", shared.Attack)), shared.ErrTransactionClosed)
+		assert.ErrorIs(t, transaction.SavePrediction("g1", "p1", newMarkedPredictionBoard(t)), shared.ErrTransactionClosed)
+		assert.ErrorIs(t, transaction.AddPlayerGame("p1", "g1"), shared.ErrTransactionClosed)
+	})
+
+	t.Run("[UnitOfWork: 不正な引数]", func(t *testing.T) {
+		store := newStore(t)
+		transaction := store.UnitOfWork.Begin()
+		assert.ErrorIs(t, transaction.SaveGame(nil), shared.ErrGameIsNil)
+		assert.ErrorIs(t, transaction.AppendTurnLog("g2", NewTurnLog(t, "g1", 1, "p1", shared.Attack)), shared.ErrInvalidGameId)
+		assert.ErrorIs(t, transaction.SavePrediction("g1", "", newMarkedPredictionBoard(t)), shared.ErrInvalidPlayerID)
+		assert.ErrorIs(t, transaction.SavePrediction("g1", "p1", nil), shared.ErrPredictionBoardIsNil)
+		assert.ErrorIs(t, transaction.AddPlayerGame("", "g1"), shared.ErrInvalidPlayerID)
+		assert.ErrorIs(t, transaction.AddPlayerGame("p1", ""), shared.ErrInvalidGameId)
+	})
+
+	t.Run("[UnitOfWork: キャンセルされたcontext]", func(t *testing.T) {
+		store := newStore(t)
+		game := NewPlayedGame(t, "g1")
+		transaction := store.UnitOfWork.Begin()
+		assert.NoError(t, transaction.SaveGame(game))
+		assert.NoError(t, transaction.AppendTurnLog("g1", NewTurnLog(t, "g1", 1, "p1", shared.Attack)))
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+		assert.ErrorIs(t, transaction.Commit(canceled), context.Canceled)
+		assert.Equal(t, 0, game.GetVersion())
+		AssertTurnNotStored(t, store, "g1")
+	})
+
+	t.Run("[UnitOfWork: 同時にコミットしても対戦と行動記録は食い違わない]", func(t *testing.T) {
+		store := newStore(t)
+		assert.NoError(t, store.Games.Save(ctx, NewPlayedGame(t, "g1")))
+
+		const workers, commitsPerWorker = 8, 5
+		var wg sync.WaitGroup
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for committed := 0; committed < commitsPerWorker; {
+					game, err := store.Games.FindByID(ctx, "g1")
+					if !assert.NoError(t, err) {
+						return
+					}
+					// 読み込んだバージョンをターンとする行動記録を積む. 更新が失われなければターンは重複しない.
+					transaction := store.UnitOfWork.Begin()
+					assert.NoError(t, transaction.SaveGame(game))
+					assert.NoError(t, transaction.AppendTurnLog("g1", NewTurnLog(t, "g1", game.GetVersion(), "p1", shared.Attack)))
+					err = transaction.Commit(ctx)
+					if errors.Is(err, shared.ErrConcurrentModification) {
+						continue
+					}
+					if !assert.NoError(t, err) {
+						return
+					}
+					committed++
+				}
+			}()
+		}
+		wg.Wait()
+
+		found, err := store.Games.FindByID(ctx, "g1")
+		assert.NoError(t, err)
+		assert.Equal(t, workers*commitsPerWorker+1, found.GetVersion())
+		logs, err := store.TurnLogs.FindByGameId(ctx, "g1")
+		assert.NoError(t, err)
+		if assert.Len(t, logs, workers*commitsPerWorker) {
+			for i, log := range logs {
+				assert.Equal(t, i+1, log.GetTurn())
+			}
+		}
+	})
+}
+
+// AssertTurnStored は game, logs, prediction (p1 のもの) がそのまま保存されていることを確かめる.
+func AssertTurnStored(t *testing.T, store UnitOfWorkStore, game *domain.Game, logs []*domain.TurnLog, prediction *domain.PredictionBoard) {
+	t.Helper()
+	ctx := context.Background()
+	found, err := store.Games.FindByID(ctx, game.GetId())
+	assert.NoError(t, err)
+	AssertGamesEqual(t, game, found)
+	foundLogs, err := store.TurnLogs.FindByGameId(ctx, game.GetId())
+	assert.NoError(t, err)
+	AssertTurnLogsEqual(t, logs, foundLogs)
+	foundPrediction, err := store.Predictions.Find(ctx, game.GetId(), "p1")
+	assert.NoError(t, err)
+	AssertPredictionBoardsEqual(t, prediction, foundPrediction)
+}
+
+// AssertTurnNotStored は gameId の対戦, 行動記録, p1 の存在確率マップ, p1 の参加中の対戦のどれも保存されていないことを確かめる.
+func AssertTurnNotStored(t *testing.T, store UnitOfWorkStore, gameId shared.GameId) {
+	t.Helper()
+	ctx := context.Background()
+	_, err := store.Games.FindByID(ctx, gameId)
+	assert.ErrorIs(t, err, shared.ErrGameNotFound)
+	logs, err := store.TurnLogs.FindByGameId(ctx, gameId)
+	assert.NoError(t, err)
+	assert.Empty(t, logs)
+	_, err = store.Predictions.Find(ctx, gameId, "p1")
+	assert.ErrorIs(t, err, shared.ErrPredictionBoardNotFound)
+	gameIds, err := store.PlayerGames.ListGames(ctx, "p1")
+	assert.NoError(t, err)
+	assert.NotContains(t, gameIds, gameId)
+}
@@ -0,0 +1,61 @@
+package infrastructure
+
+import (
+	"backend/domain"
+	"context"
+	"math/rand"
+	"sync"
+)
+
+// SpreadOutPlacement は潜水艦同士の距離が最も離れる配置を返す.
+// 最も近い2隻の距離が最大になる配置のうち, 全ての組の距離の合計が最大のものから乱数で1つ選ぶ.
+type SpreadOutPlacement struct {
+	mu         sync.Mutex
+	random     *rand.Rand
+	candidates [][]int
+}
+
+func NewSpreadOutPlacement(source rand.Source) *SpreadOutPlacement {
+	best := make([][]int, 0)
+	bestMin, bestSum := -1, -1
+	for _, cells := range placementCandidates() {
+		minDistance, sumDistance := -1, 0
+		for i := range cells {
+			for j := i + 1; j < len(cells); j++ {
+				distance := squaredCellDistance(cells[i], cells[j])
+				if minDistance < 0 || distance < minDistance {
+					minDistance = distance
+				}
+				sumDistance += distance
+			}
+		}
+		if minDistance > bestMin || (minDistance == bestMin && sumDistance > bestSum) {
+			best = best[:0]
+			bestMin, bestSum = minDistance, sumDistance
+		}
+		if minDistance == bestMin && sumDistance == bestSum {
+			best = append(best, cells)
+		}
+	}
+	return &SpreadOutPlacement{
+		random:     rand.New(source),
+		candidates: best,
+	}
+}
+
+func (placement *SpreadOutPlacement) Place(ctx context.Context) ([]*domain.Position, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	placement.mu.Lock()
+	cells := placement.candidates[placement.random.Intn(len(placement.candidates))]
+	placement.mu.Unlock()
+	return cellsToPositions(cells)
+}
+
+// squaredCellDistance は2つのマスのユークリッド距離の2乗を返す.
+func squaredCellDistance(a int, b int) int {
+	dx := a%placementBoardSize - b%placementBoardSize
+	dy := a/placementBoardSize - b/placementBoardSize
+	return dx*dx + dy*dy
+}
@@ -0,0 +1,85 @@
+package infrastructure
+
+import (
+	"backend/domain/interfaces"
+	"backend/domain/shared"
+	"net/http"
+	"strings"
+)
+
+// StorageBackend はリポジトリの保存先.
+type StorageBackend string
+
+const (
+	MemoryStorage  StorageBackend = "memory"
+	UpstashStorage StorageBackend = "upstash"
+)
+
+// 保存先を選ぶ環境変数. URL とトークンは Upstash のコンソールに表示される名前に合わせる.
+const (
+	StorageBackendEnv = "STORAGE_BACKEND"
+	UpstashURLEnv     = "UPSTASH_REDIS_REST_URL"
+	UpstashTokenEnv   = "UPSTASH_REDIS_REST_TOKEN"
+)
+
+// StorageConfig はリポジトリの保存先の設定. HTTPClient は Upstash への接続に使い, nil なら既定のクライアントを使う.
+type StorageConfig struct {
+	Backend      StorageBackend
+	UpstashURL   string
+	UpstashToken string
+	HTTPClient   *http.Client
+}
+
+// LoadStorageConfig は getenv から設定を読む. STORAGE_BACKEND が空ならメモリに保存する.
+func LoadStorageConfig(getenv func(string) string) StorageConfig {
+	backend := StorageBackend(strings.ToLower(strings.TrimSpace(getenv(StorageBackendEnv))))
+	if backend == "" {
+		backend = MemoryStorage
+	}
+	return StorageConfig{
+		Backend:      backend,
+		UpstashURL:   strings.TrimSpace(getenv(UpstashURLEnv)),
+		UpstashToken: strings.TrimSpace(getenv(UpstashTokenEnv)),
+	}
+}
+
+// Repositories は同じ保存先を使うリポジトリの組. UnitOfWork は Games, TurnLogs, Predictions, PlayerGames への書き込みをまとめてコミットする.
+type Repositories struct {
+	Games       interfaces.GameRepository
+	TurnLogs    interfaces.TurnLogRepository
+	Predictions interfaces.PredictionRepository
+	PlayerGames interfaces.PlayerGamesIndexRepository
+	UnitOfWork  interfaces.UnitOfWork
+}
+
+// NewRepositories は config.Backend に応じたリポジトリの組を作る.
+func NewRepositories(config StorageConfig) (*Repositories, error) {
+	switch config.Backend {
+	case MemoryStorage:
+		playerGames := NewInMemoryPlayerGamesIndexRepository()
+		games := NewInMemoryGameRepository(playerGames)
+		turnLogs := NewInMemoryTurnLogRepository()
+		predictions := NewInMemoryPredictionRepository()
+		return &Repositories{
+			Games:       games,
+			TurnLogs:    turnLogs,
+			Predictions: predictions,
+			PlayerGames: playerGames,
+			UnitOfWork:  NewInMemoryUnitOfWork(games, turnLogs, predictions, playerGames),
+		}, nil
+	case UpstashStorage:
+		client, err := NewUpstashClient(config.UpstashURL, config.UpstashToken, config.HTTPClient)
+		if err != nil {
+			return nil, err
+		}
+		return &Repositories{
+			Games:       NewUpstashGameRepository(client),
+			TurnLogs:    NewUpstashTurnLogRepository(client),
+			Predictions: NewUpstashPredictionRepository(client),
+			PlayerGames: NewUpstashPlayerGamesIndexRepository(client),
+			UnitOfWork:  NewUpstashUnitOfWork(client),
+		}, nil
+	default:
+		return nil, shared.ErrInvalidStorageBackend
+	}
+}
@@ -0,0 +1,58 @@
+package infrastructure
+
+import (
+	"testing"
+
+	"backend/domain/shared"
+	"backend/infrastructure/upstashtest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadStorageConfig(t *testing.T) {
+	env := map[string]string{
+		StorageBackendEnv: " Upstash ",
+		UpstashURLEnv:     "https://example.upstash.io",
+		UpstashTokenEnv:   "token",
+	}
+	config := LoadStorageConfig(func(key string) string { return env[key] })
+	assert.Equal(t, UpstashStorage, config.Backend)
+	assert.Equal(t, "https://example.upstash.io", config.UpstashURL)
+	assert.Equal(t, "token", config.UpstashToken)
+
+	config = LoadStorageConfig(func(string) string { return "" })
+	assert.Equal(t, MemoryStorage, config.Backend)
+}
+
+func TestNewRepositories(t *testing.T) {
+	t.Run("[NewRepositories: メモリ]", func(t *testing.T) {
+		repositories, err := NewRepositories(StorageConfig{Backend: MemoryStorage})
+		assert.NoError(t, err)
+		assert.IsType(t, &InMemoryGameRepository{}, repositories.Games)
+		assert.IsType(t, &InMemoryTurnLogRepository{}, repositories.TurnLogs)
+		assert.IsType(t, &InMemoryPredictionRepository{}, repositories.Predictions)
+		assert.IsType(t, &InMemoryPlayerGamesIndexRepository{}, repositories.PlayerGames)
+		assert.IsType(t, &InMemoryUnitOfWork{}, repositories.UnitOfWork)
+	})
+
+	t.Run("[NewRepositories: Upstash]", func(t *testing.T) {
+		server := upstashtest.NewServer(t)
+		repositories, err := NewRepositories(StorageConfig{Backend: UpstashStorage, UpstashURL: server.URL, UpstashToken: server.Token})
+		assert.NoError(t, err)
+		assert.IsType(t, &UpstashGameRepository{}, repositories.Games)
+		assert.IsType(t, &UpstashTurnLogRepository{}, repositories.TurnLogs)
+		assert.IsType(t, &UpstashPredictionRepository{}, repositories.Predictions)
+		assert.IsType(t, &UpstashPlayerGamesIndexRepository{}, repositories.PlayerGames)
+		assert.IsType(t, &UpstashUnitOfWork{}, repositories.UnitOfWork)
+	})
+
+	t.Run("[NewRepositories: Upstashの接続先がない]", func(t *testing.T) {
+		_, err := NewRepositories(StorageConfig{Backend: UpstashStorage})
+		assert.ErrorIs(t, err, shared.ErrInvalidUpstashConfig)
+	})
+
+	t.Run("[NewRepositories: 不明な保存先]", func(t *testing.T) {
+		_, err := NewRepositories(StorageConfig{Backend: "postgres"})
+		assert.ErrorIs(t, err, shared.ErrInvalidStorageBackend)
+	})
+}
@@ -0,0 +1,112 @@
+package infrastructure
+
+import (
+	"backend/domain"
+	"backend/domain/shared"
+)
+
+// turnChanges はトランザクションに積んだ書き込み. 保存先ごとのトランザクションに埋め込み, Commit だけを保存先ごとに実装する.
+// 積んだ時点の状態を書き込むため, 対戦と存在確率マップは複製して持つ.
+type turnChanges struct {
+	games       []stagedGame
+	logs        []stagedTurnLog
+	predictions []stagedPrediction
+	playerGames []stagedPlayerGame
+	closed      bool
+}
+
+// stagedGame は保存する対戦の複製と, コミットに成功したときにバージョンを進める呼び出し側の対戦.
+type stagedGame struct {
+	original *domain.Game
+	saved    *domain.Game
+}
+
+type stagedTurnLog struct {
+	gameId shared.GameId
+	log    *domain.TurnLog
+}
+
+type stagedPrediction struct {
+	gameId   shared.GameId
+	playerId shared.PlayerId
+	board    *domain.PredictionBoard
+}
+
+type stagedPlayerGame struct {
+	playerId shared.PlayerId
+	gameId   shared.GameId
+}
+
+// SaveGame は game を積む. 同じ対戦を2回積んだ場合は後から積んだものを保存する.
+func (changes *turnChanges) SaveGame(game *domain.Game) error {
+	if changes.closed {
+		return shared.ErrTransactionClosed
+	}
+	if game == nil {
+		return shared.ErrGameIsNil
+	}
+	staged := stagedGame{original: game, saved: game.Clone()}
+	for i := range changes.games {
+		if changes.games[i].saved.GetId() == game.GetId() {
+			changes.games[i] = staged
+			return nil
+		}
+	}
+	changes.games = append(changes.games, staged)
+	return nil
+}
+
+func (changes *turnChanges) AppendTurnLog(gameId shared.GameId, log *domain.TurnLog) error {
+	if changes.closed {
+		return shared.ErrTransactionClosed
+	}
+	if err := validateTurnLog(gameId, log); err != nil {
+		return err
+	}
+	changes.logs = append(changes.logs, stagedTurnLog{gameId: gameId, log: log})
+	return nil
+}
+
+func (changes *turnChanges) SavePrediction(gameId shared.GameId, playerId shared.PlayerId, board *domain.PredictionBoard) error {
+	if changes.closed {
+		return shared.ErrTransactionClosed
+	}
+	if err := validatePredictionKey(gameId, playerId); err != nil {
+		return err
+	}
+	if board == nil {
+		return shared.ErrPredictionBoardIsNil
+	}
+	changes.predictions = append(changes.predictions, stagedPrediction{gameId: gameId, playerId: playerId, board: board.Clone()})
+	return nil
+}
+
+func (changes *turnChanges) AddPlayerGame(playerId shared.PlayerId, gameId shared.GameId) error {
+	if changes.closed {
+		return shared.ErrTransactionClosed
+	}
+	if err := validatePlayerGame(playerId, gameId); err != nil {
+		return err
+	}
+	changes.playerGames = append(changes.playerGames, stagedPlayerGame{playerId: playerId, gameId: gameId})
+	return nil
+}
+
+// close はコミットを始める前に呼び, 以後の書き込みとコミットを拒否する.
+func (changes *turnChanges) close() error {
+	if changes.closed {
+		return shared.ErrTransactionClosed
+	}
+	changes.closed = true
+	return nil
+}
+
+// advanceVersions はコミットに成功した後に, 呼び出し側の対戦のバージョンを保存したものに揃える.
+func (changes *turnChanges) advanceVersions() error {
+	for _, staged := range changes.games {
+		if err := staged.original.SetVersion(staged.saved.GetVersion()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
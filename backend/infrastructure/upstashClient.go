@@ -0,0 +1,400 @@
+package infrastructure
+
+import (
+	"backend/domain/shared"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultUpstashTimeout = 10 * time.Second
+
+// UpstashClient は Upstash の REST API を通して Redis のコマンドを実行する. 複数のgoroutineから同時に利用できる.
+// コマンドは ["HSET", "key", "field", "value"] のような JSON の配列として送り, 応答の result を解釈する.
+type UpstashClient struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+// NewUpstashClient は baseURL の Upstash に token で接続するクライアントを作る.
+// httpClient が nil の場合はタイムアウトを設定したクライアントを使う.
+func NewUpstashClient(baseURL string, token string, httpClient *http.Client) (*UpstashClient, error) {
+	parsed, err := url.Parse(baseURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, shared.ErrInvalidUpstashConfig
+	}
+	if strings.TrimSpace(token) == "" {
+		return nil, shared.ErrInvalidUpstashConfig
+	}
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: defaultUpstashTimeout}
+	}
+	return &UpstashClient{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		token:      token,
+		httpClient: httpClient,
+	}, nil
+}
+
+// UpstashReply は1つのコマンドの結果. 値の型はコマンドによって異なるため, 取り出す側で解釈する.
+type UpstashReply struct {
+	result json.RawMessage
+}
+
+// IsNil は結果が Redis の nil (存在しないキーなど) かどうかを返す.
+func (reply UpstashReply) IsNil() bool {
+	return len(reply.result) == 0 || string(reply.result) == "null"
+}
+
+// String は文字列の結果を返す. nil の場合は ok が false になる.
+func (reply UpstashReply) String() (string, bool, error) {
+	if reply.IsNil() {
+		return "", false, nil
+	}
+	var value string
+	if err := json.Unmarshal(reply.result, &value); err != nil {
+		return "", false, fmt.Errorf("%w: %s", shared.ErrUnexpectedUpstashReply, reply.result)
+	}
+	return value, true, nil
+}
+
+func (reply UpstashReply) Int() (int64, error) {
+	var value int64
+	if err := json.Unmarshal(reply.result, &value); err != nil {
+		return 0, fmt.Errorf("%w: %s", shared.ErrUnexpectedUpstashReply, reply.result)
+	}
+	return value, nil
+}
+
+// Strings は配列の結果を返す. nil の場合は空の配列を返す.
+func (reply UpstashReply) Strings() ([]string, error) {
+	if reply.IsNil() {
+		return []string{}, nil
+	}
+	var values []string
+	if err := json.Unmarshal(reply.result, &values); err != nil {
+		return nil, fmt.Errorf("%w: %s", shared.ErrUnexpectedUpstashReply, reply.result)
+	}
+	return values, nil
+}
+
+// StringMap は HGETALL のように field と value が交互に並ぶ配列の結果を map にして返す.
+func (reply UpstashReply) StringMap() (map[string]string, error) {
+	values, err := reply.Strings()
+	if err != nil {
+		return nil, err
+	}
+	if len(values)%2 != 0 {
+		return nil, fmt.Errorf("%w: %s", shared.ErrUnexpectedUpstashReply, reply.result)
+	}
+	fields := make(map[string]string, len(values)/2)
+	for i := 0; i < len(values); i += 2 {
+		fields[values[i]] = values[i+1]
+	}
+	return fields, nil
+}
+
+// Do は1つのコマンドを実行する. Redis がエラーを返した場合は shared.ErrUpstashCommandFailed を包んで返す.
+func (client *UpstashClient) Do(ctx context.Context, command ...string) (UpstashReply, error) {
+	if len(command) == 0 {
+		return UpstashReply{}, fmt.Errorf("%w: empty command", shared.ErrUpstashCommandFailed)
+	}
+	var response upstashResponse
+	if err := client.post(ctx, "", command, &response); err != nil {
+		return UpstashReply{}, err
+	}
+	if response.Error != "" {
+		return UpstashReply{}, fmt.Errorf("%w: %s", shared.ErrUpstashCommandFailed, response.Error)
+	}
+	return UpstashReply{result: response.Result}, nil
+}
+
+func (client *UpstashClient) Get(ctx context.Context, key string) (string, bool, error) {
+	reply, err := client.Do(ctx, "GET", key)
+	if err != nil {
+		return "", false, err
+	}
+	return reply.String()
+}
+
+func (client *UpstashClient) Set(ctx context.Context, key string, value string) error {
+	_, err := client.Do(ctx, "SET", key, value)
+	return err
+}
+
+// SetNX は key が存在しない場合に限り value を書き込み, ttl が経過したら削除されるようにする.
+// 書き込んだ場合は true, 既に key が存在した場合は false を返す.
+func (client *UpstashClient) SetNX(ctx context.Context, key string, value string, ttl time.Duration) (bool, error) {
+	if ttl < time.Millisecond {
+		return false, fmt.Errorf("%w: ttl must be at least 1ms", shared.ErrUpstashCommandFailed)
+	}
+	reply, err := client.Do(ctx, "SET", key, value, "NX", "PX", strconv.FormatInt(ttl.Milliseconds(), 10))
+	if err != nil {
+		return false, err
+	}
+	return !reply.IsNil(), nil
+}
+
+// Del は keys を削除し, 実際に削除したキーの数を返す.
+func (client *UpstashClient) Del(ctx context.Context, keys ...string) (int64, error) {
+	reply, err := client.Do(ctx, append([]string{"DEL"}, keys...)...)
+	if err != nil {
+		return 0, err
+	}
+	return reply.Int()
+}
+
+// upstashDelIfEqualScript は KEYS[1] の値が ARGV[1] と一致する場合だけ削除し, 削除したキーの数を返す.
+const upstashDelIfEqualScript = `if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("DEL", KEYS[1]) else return 0 end`
+
+// DelIfEqual は key の値が value と一致する場合だけ key を削除し, 削除したかどうかを返す.
+// 比較と削除は1つのスクリプトで実行するため, その間に他のクライアントが書き込んだ値を消すことはない.
+func (client *UpstashClient) DelIfEqual(ctx context.Context, key string, value string) (bool, error) {
+	reply, err := client.Do(ctx, "EVAL", upstashDelIfEqualScript, "1", key, value)
+	if err != nil {
+		return false, err
+	}
+	deleted, err := reply.Int()
+	if err != nil {
+		return false, err
+	}
+	return deleted == 1, nil
+}
+
+// HSet は fields をハッシュ key に書き込み, 新しく追加したフィールドの数を返す.
+func (client *UpstashClient) HSet(ctx context.Context, key string, fields map[string]string) (int64, error) {
+	reply, err := client.Do(ctx, hsetCommand(key, fields)...)
+	if err != nil {
+		return 0, err
+	}
+	return reply.Int()
+}
+
+// HGet はハッシュ key の field の値を返す. key か field が存在しなければ ok が false になる.
+func (client *UpstashClient) HGet(ctx context.Context, key string, field string) (string, bool, error) {
+	reply, err := client.Do(ctx, "HGET", key, field)
+	if err != nil {
+		return "", false, err
+	}
+	return reply.String()
+}
+
+// HGetAll はハッシュ key の全フィールドを返す. key が存在しなければ空の map を返す.
+func (client *UpstashClient) HGetAll(ctx context.Context, key string) (map[string]string, error) {
+	reply, err := client.Do(ctx, "HGETALL", key)
+	if err != nil {
+		return nil, err
+	}
+	return reply.StringMap()
+}
+
+// RPush は values をリスト key の末尾に追加し, 追加後の長さを返す.
+func (client *UpstashClient) RPush(ctx context.Context, key string, values ...string) (int64, error) {
+	reply, err := client.Do(ctx, append([]string{"RPUSH", key}, values...)...)
+	if err != nil {
+		return 0, err
+	}
+	return reply.Int()
+}
+
+// LRange はリスト key の start から stop まで (両端を含む) を返す. 負の添字は末尾から数える.
+func (client *UpstashClient) LRange(ctx context.Context, key string, start int64, stop int64) ([]string, error) {
+	reply, err := client.Do(ctx, "LRANGE", key, strconv.FormatInt(start, 10), strconv.FormatInt(stop, 10))
+	if err != nil {
+		return nil, err
+	}
+	return reply.Strings()
+}
+
+// SAdd は members を集合 key に追加し, 新しく追加した数を返す.
+func (client *UpstashClient) SAdd(ctx context.Context, key string, members ...string) (int64, error) {
+	reply, err := client.Do(ctx, append([]string{"SADD", key}, members...)...)
+	if err != nil {
+		return 0, err
+	}
+	return reply.Int()
+}
+
+// SMembers は集合 key の要素を返す. 順序は保証しない.
+func (client *UpstashClient) SMembers(ctx context.Context, key string) ([]string, error) {
+	reply, err := client.Do(ctx, "SMEMBERS", key)
+	if err != nil {
+		return nil, err
+	}
+	return reply.Strings()
+}
+
+// SRem は members を集合 key から取り除き, 実際に取り除いた数を返す.
+func (client *UpstashClient) SRem(ctx context.Context, key string, members ...string) (int64, error) {
+	reply, err := client.Do(ctx, append([]string{"SREM", key}, members...)...)
+	if err != nil {
+		return 0, err
+	}
+	return reply.Int()
+}
+
+// Pipeline は複数のコマンドを1回のリクエストで送るバッチを作る. コマンドの間に他のクライアントのコマンドが割り込むことがある.
+func (client *UpstashClient) Pipeline() *UpstashBatch {
+	return &UpstashBatch{client: client, path: "/pipeline"}
+}
+
+// Transaction は MULTI/EXEC で囲んだバッチを作る. コマンドは他のクライアントのコマンドに割り込まれずに続けて実行される.
+func (client *UpstashClient) Transaction() *UpstashBatch {
+	return &UpstashBatch{client: client, path: "/multi-exec"}
+}
+
+// UpstashBatch は Exec でまとめて送るコマンドの列. 1つのgoroutineから組み立てて使う.
+type UpstashBatch struct {
+	client   *UpstashClient
+	path     string
+	commands [][]string
+}
+
+// Command は任意のコマンドを追加する.
+func (batch *UpstashBatch) Command(command ...string) *UpstashBatch {
+	batch.commands = append(batch.commands, command)
+	return batch
+}
+
+func (batch *UpstashBatch) Set(key string, value string) *UpstashBatch {
+	return batch.Command("SET", key, value)
+}
+
+func (batch *UpstashBatch) Get(key string) *UpstashBatch {
+	return batch.Command("GET", key)
+}
+
+func (batch *UpstashBatch) Del(keys ...string) *UpstashBatch {
+	return batch.Command(append([]string{"DEL"}, keys...)...)
+}
+
+func (batch *UpstashBatch) HSet(key string, fields map[string]string) *UpstashBatch {
+	return batch.Command(hsetCommand(key, fields)...)
+}
+
+func (batch *UpstashBatch) HGet(key string, field string) *UpstashBatch {
+	return batch.Command("HGET", key, field)
+}
+
+func (batch *UpstashBatch) HGetAll(key string) *UpstashBatch {
+	return batch.Command("HGETALL", key)
+}
+
+func (batch *UpstashBatch) RPush(key string, values ...string) *UpstashBatch {
+	return batch.Command(append([]string{"RPUSH", key}, values...)...)
+}
+
+func (batch *UpstashBatch) LRange(key string, start int64, stop int64) *UpstashBatch {
+	return batch.Command("LRANGE", key, strconv.FormatInt(start, 10), strconv.FormatInt(stop, 10))
+}
+
+func (batch *UpstashBatch) SAdd(key string, members ...string) *UpstashBatch {
+	return batch.Command(append([]string{"SADD", key}, members...)...)
+}
+
+func (batch *UpstashBatch) SMembers(key string) *UpstashBatch {
+	return batch.Command("SMEMBERS", key)
+}
+
+func (batch *UpstashBatch) SRem(key string, members ...string) *UpstashBatch {
+	return batch.Command(append([]string{"SREM", key}, members...)...)
+}
+
+// Len は追加したコマンドの数を返す.
+func (batch *UpstashBatch) Len() int {
+	return len(batch.commands)
+}
+
+// Exec は追加したコマンドを送り, 追加した順に結果を返す.
+// Redis と同様に途中のコマンドが失敗しても残りは実行されるため, 失敗したコマンドがあれば結果とともに最初のエラーを返す.
+func (batch *UpstashBatch) Exec(ctx context.Context) ([]UpstashReply, error) {
+	if len(batch.commands) == 0 {
+		return []UpstashReply{}, nil
+	}
+	var responses []upstashResponse
+	if err := batch.client.post(ctx, batch.path, batch.commands, &responses); err != nil {
+		return nil, err
+	}
+	if len(responses) != len(batch.commands) {
+		return nil, fmt.Errorf("%w: %d replies for %d commands", shared.ErrUnexpectedUpstashReply, len(responses), len(batch.commands))
+	}
+	replies := make([]UpstashReply, len(responses))
+	var firstErr error
+	for i, response := range responses {
+		replies[i] = UpstashReply{result: response.Result}
+		if response.Error != "" && firstErr == nil {
+			firstErr = fmt.Errorf("%w: %s: %s", shared.ErrUpstashCommandFailed, batch.commands[i][0], response.Error)
+		}
+	}
+	return replies, firstErr
+}
+
+type upstashResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  string          `json:"error"`
+}
+
+// post は body を JSON にして baseURL+path へ送り, 応答を out に読み込む.
+// 単一のコマンドの失敗は 400 と本文の error で返されるため, shared.ErrUpstashCommandFailed として扱う.
+func (client *UpstashClient) post(ctx context.Context, path string, body any, out any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, client.baseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Authorization", "Bearer "+client.token)
+	request.Header.Set("Content-Type", "application/json")
+	response, err := client.httpClient.Do(request)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		return fmt.Errorf("%w: %v", shared.ErrUpstashRequestFailed, err)
+	}
+	defer response.Body.Close()
+	raw, err := io.ReadAll(response.Body)
+	if err != nil {
+		return fmt.Errorf("%w: %v", shared.ErrUpstashRequestFailed, err)
+	}
+	if response.StatusCode != http.StatusOK {
+		var failure upstashResponse
+		if json.Unmarshal(raw, &failure) == nil && failure.Error != "" {
+			if response.StatusCode == http.StatusBadRequest {
+				return fmt.Errorf("%w: %s", shared.ErrUpstashCommandFailed, failure.Error)
+			}
+			return fmt.Errorf("%w: %s: %s", shared.ErrUpstashRequestFailed, response.Status, failure.Error)
+		}
+		return fmt.Errorf("%w: %s", shared.ErrUpstashRequestFailed, response.Status)
+	}
+	if err := json.Unmarshal(raw, out); err != nil {
+		return fmt.Errorf("%w: %v", shared.ErrUnexpectedUpstashReply, err)
+	}
+	return nil
+}
+
+// hsetCommand はフィールド名の順に並べた HSET コマンドを作る. 同じ内容なら常に同じコマンドになる.
+func hsetCommand(key string, fields map[string]string) []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	command := make([]string, 0, 2+2*len(names))
+	command = append(command, "HSET", key)
+	for _, name := range names {
+		command = append(command, name, fields[name])
+	}
+	return command
+}
@@ -0,0 +1,285 @@
+package infrastructure
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"backend/domain/shared"
+	"backend/infrastructure/upstashtest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestUpstashClient(t *testing.T) (*UpstashClient, *upstashtest.Server) {
+	t.Helper()
+	server := upstashtest.NewServer(t)
+	client, err := NewUpstashClient(server.URL, server.Token, nil)
+	assert.NoError(t, err)
+	return client, server
+}
+
+func TestNewUpstashClientFail(t *testing.T) {
+	testList := []struct {
+		name    string
+		baseURL string
+		token   string
+	}{
+		{"[NewUpstashClient: URLが空]", "", "token"},
+		{"[NewUpstashClient: スキームがhttpでない]", "redis://example.upstash.io", "token"},
+		{"[NewUpstashClient: トークンが空]", "https://example.upstash.io", " "},
+	}
+	for _, tl := range testList {
+		t.Run(tl.name, func(t *testing.T) {
+			_, err := NewUpstashClient(tl.baseURL, tl.token, nil)
+			assert.ErrorIs(t, err, shared.ErrInvalidUpstashConfig)
+		})
+	}
+}
+
+func TestUpstashClientCommands(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("[UpstashClient: SETとGET]", func(t *testing.T) {
+		client, server := newTestUpstashClient(t)
+		_, found, err := client.Get(ctx, "game:g1:board")
+		assert.NoError(t, err)
+		assert.False(t, found)
+
+		assert.NoError(t, client.Set(ctx, "game:g1:board", `{"cells":[]}`))
+		value, found, err := client.Get(ctx, "game:g1:board")
+		assert.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, `{"cells":[]}`, value)
+		assert.Equal(t, "string", server.Type("game:g1:board"))
+	})
+
+	t.Run("[UpstashClient: HSETとHGETALL]", func(t *testing.T) {
+		client, server := newTestUpstashClient(t)
+		fields, err := client.HGetAll(ctx, "game:g1:meta")
+		assert.NoError(t, err)
+		assert.Empty(t, fields)
+
+		added, err := client.HSet(ctx, "game:g1:meta", map[string]string{"status": "waiting", "turn": "0"})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), added)
+		added, err = client.HSet(ctx, "game:g1:meta", map[string]string{"status": "inProgress", "current_player_id": "p1"})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), added)
+
+		fields, err = client.HGetAll(ctx, "game:g1:meta")
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"status": "inProgress", "turn": "0", "current_player_id": "p1"}, fields)
+		assert.Equal(t, "hash", server.Type("game:g1:meta"))
+	})
+
+	t.Run("[UpstashClient: HGET]", func(t *testing.T) {
+		client, _ := newTestUpstashClient(t)
+		_, found, err := client.HGet(ctx, "game:g1:meta", "version")
+		assert.NoError(t, err)
+		assert.False(t, found)
+
+		_, err = client.HSet(ctx, "game:g1:meta", map[string]string{"version": "3"})
+		assert.NoError(t, err)
+		value, found, err := client.HGet(ctx, "game:g1:meta", "version")
+		assert.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, "3", value)
+		_, found, err = client.HGet(ctx, "game:g1:meta", "turn")
+		assert.NoError(t, err)
+		assert.False(t, found)
+	})
+
+	t.Run("[UpstashClient: SETNXは存在しないキーにだけ書き込み, 期限が過ぎると消える]", func(t *testing.T) {
+		client, server := newTestUpstashClient(t)
+		ok, err := client.SetNX(ctx, "game:g1:lock", "a", 50*time.Millisecond)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		ok, err = client.SetNX(ctx, "game:g1:lock", "b", time.Second)
+		assert.NoError(t, err)
+		assert.False(t, ok)
+		value, _, err := client.Get(ctx, "game:g1:lock")
+		assert.NoError(t, err)
+		assert.Equal(t, "a", value)
+
+		assert.Eventually(t, func() bool {
+			return server.Type("game:g1:lock") == "none"
+		}, time.Second, 10*time.Millisecond)
+		ok, err = client.SetNX(ctx, "game:g1:lock", "b", time.Second)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		_, err = client.SetNX(ctx, "game:g1:lock", "c", 0)
+		assert.ErrorIs(t, err, shared.ErrUpstashCommandFailed)
+	})
+
+	t.Run("[UpstashClient: RPUSHとLRANGE]", func(t *testing.T) {
+		client, _ := newTestUpstashClient(t)
+		length, err := client.RPush(ctx, "game:g1:logs", "a", "b")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), length)
+		length, err = client.RPush(ctx, "game:g1:logs", "c")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), length)
+
+		values, err := client.LRange(ctx, "game:g1:logs", 0, -1)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"a", "b", "c"}, values)
+		values, err = client.LRange(ctx, "game:g1:logs", 1, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"b"}, values)
+		values, err = client.LRange(ctx, "game:g2:logs", 0, -1)
+		assert.NoError(t, err)
+		assert.Empty(t, values)
+	})
+
+	t.Run("[UpstashClient: SADD, SMEMBERS, SREM]", func(t *testing.T) {
+		client, server := newTestUpstashClient(t)
+		added, err := client.SAdd(ctx, "player:p1:games", "g1", "g2", "g1")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), added)
+
+		members, err := client.SMembers(ctx, "player:p1:games")
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []string{"g1", "g2"}, members)
+
+		removed, err := client.SRem(ctx, "player:p1:games", "g1", "g3")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), removed)
+		members, err = client.SMembers(ctx, "player:p1:games")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"g2"}, members)
+
+		_, err = client.SRem(ctx, "player:p1:games", "g2")
+		assert.NoError(t, err)
+		assert.Empty(t, server.Keys())
+	})
+
+	t.Run("[UpstashClient: DEL]", func(t *testing.T) {
+		client, server := newTestUpstashClient(t)
+		assert.NoError(t, client.Set(ctx, "a", "1"))
+		assert.NoError(t, client.Set(ctx, "b", "2"))
+		deleted, err := client.Del(ctx, "a", "b", "c")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), deleted)
+		assert.Empty(t, server.Keys())
+	})
+
+	t.Run("[UpstashClient: DelIfEqualは値が一致するときだけ消す]", func(t *testing.T) {
+		client, server := newTestUpstashClient(t)
+		assert.NoError(t, client.Set(ctx, "game:g1:lock", "other"))
+		deleted, err := client.DelIfEqual(ctx, "game:g1:lock", "mine")
+		assert.NoError(t, err)
+		assert.False(t, deleted)
+		assert.Equal(t, []string{"game:g1:lock"}, server.Keys())
+
+		deleted, err = client.DelIfEqual(ctx, "game:g1:lock", "other")
+		assert.NoError(t, err)
+		assert.True(t, deleted)
+		assert.Empty(t, server.Keys())
+
+		deleted, err = client.DelIfEqual(ctx, "game:g1:lock", "other")
+		assert.NoError(t, err)
+		assert.False(t, deleted)
+	})
+
+	t.Run("[UpstashClient: 型の合わないコマンドはErrUpstashCommandFailed]", func(t *testing.T) {
+		client, _ := newTestUpstashClient(t)
+		assert.NoError(t, client.Set(ctx, "game:g1:meta", "x"))
+		_, err := client.HGetAll(ctx, "game:g1:meta")
+		assert.ErrorIs(t, err, shared.ErrUpstashCommandFailed)
+		assert.ErrorContains(t, err, "WRONGTYPE")
+
+		_, err = client.Do(ctx, "FLUSHALL")
+		assert.ErrorIs(t, err, shared.ErrUpstashCommandFailed)
+	})
+
+	t.Run("[UpstashClient: トークンが違えばErrUpstashRequestFailed]", func(t *testing.T) {
+		server := upstashtest.NewServer(t)
+		client, err := NewUpstashClient(server.URL, "wrong", nil)
+		assert.NoError(t, err)
+		_, _, err = client.Get(ctx, "a")
+		assert.ErrorIs(t, err, shared.ErrUpstashRequestFailed)
+	})
+
+	t.Run("[UpstashClient: キャンセルされたcontext]", func(t *testing.T) {
+		client, _ := newTestUpstashClient(t)
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+		assert.ErrorIs(t, client.Set(canceled, "a", "1"), context.Canceled)
+		_, err := client.Pipeline().Set("a", "1").Exec(canceled)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestUpstashClientBatch(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("[UpstashBatch: パイプラインの結果は追加した順に返る]", func(t *testing.T) {
+		client, _ := newTestUpstashClient(t)
+		replies, err := client.Pipeline().
+			Set("game:g1:board", "{}").
+			HSet("game:g1:meta", map[string]string{"turn": "1"}).
+			RPush("game:g1:logs", "a", "b").
+			Get("game:g1:board").
+			Get("missing").
+			LRange("game:g1:logs", 0, -1).
+			Exec(ctx)
+		assert.NoError(t, err)
+		assert.Len(t, replies, 6)
+
+		added, err := replies[1].Int()
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), added)
+		board, found, err := replies[3].String()
+		assert.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, "{}", board)
+		assert.True(t, replies[4].IsNil())
+		logs, err := replies[5].Strings()
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"a", "b"}, logs)
+	})
+
+	t.Run("[UpstashBatch: トランザクション]", func(t *testing.T) {
+		client, _ := newTestUpstashClient(t)
+		transaction := client.Transaction().
+			HSet("game:g1:meta", map[string]string{"status": "inProgress"}).
+			SAdd("player:p1:games", "g1").
+			SMembers("player:p1:games").
+			HGetAll("game:g1:meta")
+		assert.Equal(t, 4, transaction.Len())
+		replies, err := transaction.Exec(ctx)
+		assert.NoError(t, err)
+		members, err := replies[2].Strings()
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"g1"}, members)
+		meta, err := replies[3].StringMap()
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"status": "inProgress"}, meta)
+	})
+
+	t.Run("[UpstashBatch: 失敗したコマンドがあっても残りは実行される]", func(t *testing.T) {
+		client, _ := newTestUpstashClient(t)
+		assert.NoError(t, client.Set(ctx, "game:g1:logs", "x"))
+		replies, err := client.Transaction().
+			RPush("game:g1:logs", "a").
+			Set("game:g1:board", "{}").
+			Exec(ctx)
+		assert.ErrorIs(t, err, shared.ErrUpstashCommandFailed)
+		assert.ErrorContains(t, err, "RPUSH")
+		assert.Len(t, replies, 2)
+
+		board, found, err := client.Get(ctx, "game:g1:board")
+		assert.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, "{}", board)
+	})
+
+	t.Run("[UpstashBatch: 空のバッチは送らない]", func(t *testing.T) {
+		client, _ := newTestUpstashClient(t)
+		replies, err := client.Pipeline().Exec(ctx)
+		assert.NoError(t, err)
+		assert.Empty(t, replies)
+	})
+}
@@ -0,0 +1,92 @@
+package infrastructure
+
+import (
+	"backend/domain"
+	"backend/domain/shared"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Upstash 上のキーは 02_Upstashデータ設計.mmd に従う.
+func upstashGameMetaKey(gameId shared.GameId) string {
+	return "game:" + gameId.String() + ":meta"
+}
+
+func upstashGameBoardKey(gameId shared.GameId) string {
+	return "game:" + gameId.String() + ":board"
+}
+
+// upstashGameLockKey は対戦を保存している間だけ存在するキー. 値は保存しているクライアントごとのトークン.
+func upstashGameLockKey(gameId shared.GameId) string {
+	return "game:" + gameId.String() + ":lock"
+}
+
+func upstashTurnLogsKey(gameId shared.GameId) string {
+	return "game:" + gameId.String() + ":logs"
+}
+
+func upstashPredictionKey(gameId shared.GameId, playerId shared.PlayerId) string {
+	return "game:" + gameId.String() + ":prediction:" + playerId.String()
+}
+
+func upstashPlayerGamesKey(playerId shared.PlayerId) string {
+	return "player:" + playerId.String() + ":games"
+}
+
+// upstashPosition は座標の JSON 表現.
+type upstashPosition struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+func encodeUpstashPosition(position *domain.Position) (*upstashPosition, error) {
+	if position == nil {
+		return nil, nil
+	}
+	x, y, err := position.GetPosition()
+	if err != nil {
+		return nil, err
+	}
+	return &upstashPosition{X: x, Y: y}, nil
+}
+
+func decodeUpstashPosition(position *upstashPosition) (*domain.Position, error) {
+	if position == nil {
+		return nil, nil
+	}
+	return domain.NewPosition(position.X, position.Y)
+}
+
+// 時刻はナノ秒まで保つため RFC3339Nano で保存する.
+func encodeUpstashTime(at time.Time) string {
+	return at.UTC().Format(time.RFC3339Nano)
+}
+
+func decodeUpstashTime(value string) (time.Time, error) {
+	at, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %v", shared.ErrUnexpectedUpstashReply, err)
+	}
+	return at, nil
+}
+
+func encodeUpstashFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// upstashEnum は String で保存する列挙型. 値は 0 から last までの連番とする.
+type upstashEnum interface {
+	~int
+	String() string
+}
+
+// decodeUpstashEnum は String の結果が value に一致する値を 0 から last までの中から探す.
+func decodeUpstashEnum[T upstashEnum](value string, last T) (T, error) {
+	for candidate := T(0); candidate <= last; candidate++ {
+		if candidate.String() == value {
+			return candidate, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: unknown value %q", shared.ErrUnexpectedUpstashReply, value)
+}
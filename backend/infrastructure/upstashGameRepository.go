@@ -0,0 +1,310 @@
+package infrastructure
+
+import (
+	"backend/domain"
+	"backend/domain/shared"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// upstashGameStatuses は meta の status に保存する値. API と同じ表記にする.
+var upstashGameStatuses = map[shared.GameStatus]string{
+	shared.Waiting:    "waiting",
+	shared.InProgress: "inProgress",
+	shared.Finished:   "finished",
+}
+
+// UpstashGameRepository は対戦を game:{gameId}:meta (Hash) と game:{gameId}:board (String JSON) に保存する.
+// 保存は対戦1件だけの UpstashUnitOfWork のコミットで行うため, 2つのキーの片方だけが更新された状態は読まれず,
+// version の確認も同じ方法で行う. 削除も同じロックを取得してから行う.
+type UpstashGameRepository struct {
+	client     *UpstashClient
+	unitOfWork *UpstashUnitOfWork
+}
+
+func NewUpstashGameRepository(client *UpstashClient) *UpstashGameRepository {
+	return &UpstashGameRepository{client: client, unitOfWork: NewUpstashUnitOfWork(client)}
+}
+
+// upstashBoard は game:{gameId}:board の JSON.
+// cells[y-1][x-1] はそのマスにいるプレイヤーA, プレイヤーBの潜水艦のid (いなければ空文字) で, 読む側の便宜のために書き出す.
+// 復元には submarines だけを使う.
+type upstashBoard struct {
+	Cells      [boardCells][boardCells][2]string `json:"cells"`
+	Submarines map[string]upstashSubmarine       `json:"submarines"`
+}
+
+const boardCells = shared.MaxPosition - shared.MinPosition + 1
+
+type upstashSubmarine struct {
+	OwnerId string `json:"ownerId"`
+	X       int    `json:"x"`
+	Y       int    `json:"y"`
+	Hp      int    `json:"hp"`
+	Sunk    bool   `json:"sunk"`
+}
+
+func (repository *UpstashGameRepository) Save(ctx context.Context, game *domain.Game) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	transaction := repository.unitOfWork.Begin()
+	if err := transaction.SaveGame(game); err != nil {
+		return err
+	}
+	return transaction.Commit(ctx)
+}
+
+func (repository *UpstashGameRepository) FindByID(ctx context.Context, gameId shared.GameId) (*domain.Game, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	replies, err := repository.client.Transaction().
+		HGetAll(upstashGameMetaKey(gameId)).
+		Get(upstashGameBoardKey(gameId)).
+		Exec(ctx)
+	if err != nil {
+		return nil, err
+	}
+	meta, err := replies[0].StringMap()
+	if err != nil {
+		return nil, err
+	}
+	if len(meta) == 0 {
+		return nil, shared.ErrGameNotFound
+	}
+	board, found, err := replies[1].String()
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("%w: %s is missing", shared.ErrUnexpectedUpstashReply, upstashGameBoardKey(gameId))
+	}
+	snapshot, err := decodeUpstashGameMeta(meta)
+	if err != nil {
+		return nil, err
+	}
+	if snapshot.Submarines, err = decodeUpstashBoard(board); err != nil {
+		return nil, err
+	}
+	return domain.RestoreGame(snapshot)
+}
+
+// Delete は meta, board, ターンログ, 両プレイヤーの存在確率マップを消し, 両プレイヤーの参加中の対戦から外すまでを1回の MULTI/EXEC で行う.
+// UpstashUnitOfWork と同じ game:{gameId}:lock を取得してから消すため, 保存中の対戦は消さずに ErrConcurrentModification を返す.
+// 消した後に読み込み済みの対戦を保存しようとしても, version の確認で ErrConcurrentModification になる.
+func (repository *UpstashGameRepository) Delete(ctx context.Context, gameId shared.GameId) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	client := repository.client
+	return withUpstashGameLocks(ctx, client, []shared.GameId{gameId}, repository.unitOfWork.lockTTL, func(ctx context.Context) error {
+		meta, err := client.HGetAll(ctx, upstashGameMetaKey(gameId))
+		if err != nil {
+			return err
+		}
+		if len(meta) == 0 {
+			return shared.ErrGameNotFound
+		}
+		_, err = client.Transaction().
+			Del(
+				upstashGameMetaKey(gameId),
+				upstashGameBoardKey(gameId),
+				upstashTurnLogsKey(gameId),
+				upstashPredictionKey(gameId, shared.PlayerId(meta["player_a_id"])),
+				upstashPredictionKey(gameId, shared.PlayerId(meta["player_b_id"])),
+			).
+			SRem(upstashPlayerGamesKey(shared.PlayerId(meta["player_a_id"])), gameId.String()).
+			SRem(upstashPlayerGamesKey(shared.PlayerId(meta["player_b_id"])), gameId.String()).
+			Exec(ctx)
+		return err
+	})
+}
+
+// encodeUpstashGameMeta は meta に書き込むフィールドを返す. 人間同士の対戦では cpu_ で始まるフィールドを空にする.
+func encodeUpstashGameMeta(snapshot *domain.GameSnapshot) map[string]string {
+	meta := map[string]string{
+		"game_id":             snapshot.Id.String(),
+		"status":              upstashGameStatuses[snapshot.Status],
+		"turn":                strconv.Itoa(snapshot.Turn),
+		"current_player_id":   snapshot.CurrentPlayerId.String(),
+		"player_a_id":         snapshot.PlayerAId.String(),
+		"player_b_id":         snapshot.PlayerBId.String(),
+		"winner_id":           snapshot.WinnerId.String(),
+		"finish_reason":       snapshot.FinishReason.String(),
+		"match_duration":      snapshot.MatchDuration.String(),
+		"turn_time_limit":     snapshot.TurnTimeLimit.String(),
+		"turn_timeout_policy": snapshot.TurnTimeoutPolicy.String(),
+		"cpu_name":            "",
+		"cpu_aggression":      "",
+		"cpu_move_threshold":  "",
+		"cpu_discount_rate":   "",
+		"cpu_iterations":      "",
+		"cpu_time_budget":     "",
+		"debug":               strconv.FormatBool(snapshot.Debug),
+		"started_at":          encodeUpstashTime(snapshot.StartedAt),
+		"turn_started_at":     encodeUpstashTime(snapshot.TurnStartedAt),
+		"created_at":          encodeUpstashTime(snapshot.CreatedAt),
+		"updated_at":          encodeUpstashTime(snapshot.UpdatedAt),
+		"version":             strconv.Itoa(snapshot.Version),
+	}
+	if profile := snapshot.CpuProfile; profile != nil {
+		meta["cpu_name"] = profile.GetName().String()
+		meta["cpu_aggression"] = encodeUpstashFloat(profile.GetAggression())
+		meta["cpu_move_threshold"] = encodeUpstashFloat(profile.GetMoveThreshold())
+		meta["cpu_discount_rate"] = encodeUpstashFloat(profile.GetDiscountRate())
+		meta["cpu_iterations"] = strconv.Itoa(profile.GetIterations())
+		meta["cpu_time_budget"] = profile.GetTimeBudget().String()
+	}
+	return meta
+}
+
+func decodeUpstashGameMeta(meta map[string]string) (*domain.GameSnapshot, error) {
+	decoder := upstashMetaDecoder{meta: meta}
+	snapshot := &domain.GameSnapshot{
+		Id:              shared.GameId(meta["game_id"]),
+		Turn:            decoder.int("turn"),
+		PlayerAId:       shared.PlayerId(meta["player_a_id"]),
+		PlayerBId:       shared.PlayerId(meta["player_b_id"]),
+		CurrentPlayerId: shared.PlayerId(meta["current_player_id"]),
+		WinnerId:        shared.PlayerId(meta["winner_id"]),
+		MatchDuration:   decoder.duration("match_duration"),
+		TurnTimeLimit:   decoder.duration("turn_time_limit"),
+		Debug:           decoder.bool("debug"),
+		StartedAt:       decoder.time("started_at"),
+		TurnStartedAt:   decoder.time("turn_started_at"),
+		CreatedAt:       decoder.time("created_at"),
+		UpdatedAt:       decoder.time("updated_at"),
+		Version:         decoder.int("version"),
+	}
+	snapshot.Status = shared.GameStatus(-1)
+	for status, value := range upstashGameStatuses {
+		if value == meta["status"] {
+			snapshot.Status = status
+		}
+	}
+	if decoder.err == nil {
+		snapshot.FinishReason, decoder.err = decodeUpstashEnum[shared.FinishReason](meta["finish_reason"], shared.FinishReasonUnknown)
+	}
+	if decoder.err == nil {
+		snapshot.TurnTimeoutPolicy, decoder.err = decodeUpstashEnum[shared.TurnTimeoutPolicy](meta["turn_timeout_policy"], shared.TurnTimeoutForfeit)
+	}
+	if decoder.err == nil && meta["cpu_name"] != "" {
+		iterations, timeBudget := decoder.int("cpu_iterations"), decoder.duration("cpu_time_budget")
+		snapshot.CpuProfile, decoder.err = domain.NewCpuProfileWithParams(
+			shared.CpuName(meta["cpu_name"]),
+			decoder.float("cpu_aggression"),
+			decoder.float("cpu_move_threshold"),
+			decoder.float("cpu_discount_rate"),
+		)
+		if decoder.err == nil {
+			snapshot.CpuProfile, decoder.err = snapshot.CpuProfile.WithSearchBudget(iterations, timeBudget)
+		}
+	}
+	if decoder.err != nil {
+		return nil, decoder.err
+	}
+	return snapshot, nil
+}
+
+// upstashMetaDecoder は meta のフィールドを順に読み, 最初に失敗したフィールドのエラーだけを err に残す.
+type upstashMetaDecoder struct {
+	meta map[string]string
+	err  error
+}
+
+func (decoder *upstashMetaDecoder) fail(field string, err error) {
+	if decoder.err == nil {
+		decoder.err = fmt.Errorf("%w: meta field %s: %v", shared.ErrUnexpectedUpstashReply, field, err)
+	}
+}
+
+func (decoder *upstashMetaDecoder) int(field string) int {
+	value, err := strconv.Atoi(decoder.meta[field])
+	if err != nil {
+		decoder.fail(field, err)
+	}
+	return value
+}
+
+func (decoder *upstashMetaDecoder) float(field string) float64 {
+	value, err := strconv.ParseFloat(decoder.meta[field], 64)
+	if err != nil {
+		decoder.fail(field, err)
+	}
+	return value
+}
+
+func (decoder *upstashMetaDecoder) bool(field string) bool {
+	value, err := strconv.ParseBool(decoder.meta[field])
+	if err != nil {
+		decoder.fail(field, err)
+	}
+	return value
+}
+
+func (decoder *upstashMetaDecoder) duration(field string) time.Duration {
+	value, err := time.ParseDuration(decoder.meta[field])
+	if err != nil {
+		decoder.fail(field, err)
+	}
+	return value
+}
+
+func (decoder *upstashMetaDecoder) time(field string) time.Time {
+	value, err := decodeUpstashTime(decoder.meta[field])
+	if err != nil {
+		decoder.fail(field, err)
+	}
+	return value
+}
+
+func encodeUpstashBoard(snapshot *domain.GameSnapshot) (string, error) {
+	board := upstashBoard{Submarines: make(map[string]upstashSubmarine, len(snapshot.Submarines))}
+	for _, submarine := range snapshot.Submarines {
+		x, y, err := submarine.GetPosition().GetPosition()
+		if err != nil {
+			return "", err
+		}
+		layer := 0
+		if submarine.GetOwnerId() == snapshot.PlayerBId {
+			layer = 1
+		}
+		board.Cells[y-shared.MinPosition][x-shared.MinPosition][layer] = submarine.GetId().String()
+		board.Submarines[submarine.GetId().String()] = upstashSubmarine{
+			OwnerId: submarine.GetOwnerId().String(),
+			X:       x,
+			Y:       y,
+			Hp:      submarine.GetHp(),
+			Sunk:    submarine.IsSunk(),
+		}
+	}
+	encoded, err := json.Marshal(board)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+func decodeUpstashBoard(value string) ([]*domain.Submarine, error) {
+	var board upstashBoard
+	if err := json.Unmarshal([]byte(value), &board); err != nil {
+		return nil, fmt.Errorf("%w: board: %v", shared.ErrUnexpectedUpstashReply, err)
+	}
+	submarines := make([]*domain.Submarine, 0, len(board.Submarines))
+	for id, encoded := range board.Submarines {
+		position, err := domain.NewPosition(encoded.X, encoded.Y)
+		if err != nil {
+			return nil, err
+		}
+		submarine, err := domain.NewSubmarine(shared.SubmarineId(id), shared.PlayerId(encoded.OwnerId), position, encoded.Hp)
+		if err != nil {
+			return nil, err
+		}
+		submarines = append(submarines, submarine)
+	}
+	return submarines, nil
+}
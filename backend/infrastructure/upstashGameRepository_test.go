@@ -0,0 +1,179 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"backend/domain"
+	"backend/domain/interfaces"
+	"backend/domain/shared"
+	"backend/infrastructure/repositorytest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpstashGameRepository(t *testing.T) {
+	repositorytest.RunGameRepositoryContract(t, func(t *testing.T) interfaces.GameRepository {
+		client, _ := newTestUpstashClient(t)
+		return NewUpstashGameRepository(client)
+	})
+}
+
+func TestUpstashGameRepositoryKeys(t *testing.T) {
+	ctx := context.Background()
+	client, server := newTestUpstashClient(t)
+	repository := NewUpstashGameRepository(client)
+	game := repositorytest.NewPlayedGame(t, "g1")
+	assert.NoError(t, repository.Save(ctx, game))
+
+	assert.Equal(t, []string{"game:g1:board", "game:g1:meta"}, server.Keys())
+	assert.Equal(t, "hash", server.Type("game:g1:meta"))
+	assert.Equal(t, "string", server.Type("game:g1:board"))
+
+	meta, err := client.HGetAll(ctx, "game:g1:meta")
+	assert.NoError(t, err)
+	assert.Equal(t, "g1", meta["game_id"])
+	assert.Equal(t, "inProgress", meta["status"])
+	assert.Equal(t, "3", meta["turn"])
+	assert.Equal(t, "p1", meta["current_player_id"])
+	assert.Equal(t, "p1", meta["player_a_id"])
+	assert.Equal(t, "p2", meta["player_b_id"])
+	assert.Equal(t, "", meta["winner_id"])
+	assert.Equal(t, "probabilistic", meta["cpu_name"])
+	assert.Equal(t, "0.25", meta["cpu_aggression"])
+	assert.Equal(t, "true", meta["debug"])
+	assert.Equal(t, "2026-04-01T10:00:00.123456789Z", meta["started_at"])
+	assert.Equal(t, "1", meta["version"])
+
+	value, _, err := client.Get(ctx, "game:g1:board")
+	assert.NoError(t, err)
+	var board upstashBoard
+	assert.NoError(t, json.Unmarshal([]byte(value), &board))
+	assert.Len(t, board.Submarines, 2*shared.SubmarineCount)
+	assert.Equal(t, upstashSubmarine{OwnerId: "p2", X: 3, Y: 3, Hp: shared.InitialHp - 1}, board.Submarines["p2-sub-1"])
+	assert.Equal(t, [2]string{"", "p2-sub-1"}, board.Cells[2][2])
+	assert.Equal(t, [2]string{"p1-sub-1", ""}, board.Cells[0][0])
+
+	assert.NoError(t, repository.Delete(ctx, "g1"))
+	assert.Empty(t, server.Keys())
+}
+
+func TestUpstashGameMetaCpuProfile(t *testing.T) {
+	profile, err := domain.NewCpuProfile(shared.CpuMcts)
+	assert.NoError(t, err)
+	profile, err = profile.WithSearchBudget(300, 2*time.Second)
+	assert.NoError(t, err)
+	snapshot := repositorytest.NewPlayedGame(t, "g1").Snapshot()
+	snapshot.CpuProfile = profile
+
+	meta := encodeUpstashGameMeta(snapshot)
+	assert.Equal(t, "300", meta["cpu_iterations"])
+	assert.Equal(t, "2s", meta["cpu_time_budget"])
+	decoded, err := decodeUpstashGameMeta(meta)
+	assert.NoError(t, err)
+	assert.Equal(t, profile, decoded.CpuProfile)
+}
+
+func TestUpstashGameRepositoryCorruptedData(t *testing.T) {
+	ctx := context.Background()
+	client, _ := newTestUpstashClient(t)
+	repository := NewUpstashGameRepository(client)
+	assert.NoError(t, repository.Save(ctx, repositorytest.NewPlayedGame(t, "g1")))
+
+	t.Run("[UpstashGameRepository: 盤面がなければErrUnexpectedUpstashReply]", func(t *testing.T) {
+		_, err := client.Del(ctx, "game:g1:board")
+		assert.NoError(t, err)
+		_, err = repository.FindByID(ctx, "g1")
+		assert.ErrorIs(t, err, shared.ErrUnexpectedUpstashReply)
+	})
+
+	t.Run("[UpstashGameRepository: 読めないフィールド]", func(t *testing.T) {
+		_, err := client.Del(ctx, "game:g1:meta")
+		assert.NoError(t, err)
+		assert.NoError(t, repository.Save(ctx, repositorytest.NewPlayedGame(t, "g1")))
+		_, err = client.HSet(ctx, "game:g1:meta", map[string]string{"turn": "three"})
+		assert.NoError(t, err)
+		_, err = repository.FindByID(ctx, "g1")
+		assert.ErrorIs(t, err, shared.ErrUnexpectedUpstashReply)
+		assert.ErrorContains(t, err, "turn")
+	})
+
+	t.Run("[UpstashGameRepository: 読めないバージョンには保存しない]", func(t *testing.T) {
+		_, err := client.HSet(ctx, "game:g1:meta", map[string]string{"version": "x"})
+		assert.NoError(t, err)
+		err = repository.Save(ctx, repositorytest.NewPlayedGame(t, "g1"))
+		assert.ErrorIs(t, err, shared.ErrUnexpectedUpstashReply)
+		assert.ErrorContains(t, err, "version")
+	})
+}
+
+func TestUpstashGameRepositoryLock(t *testing.T) {
+	ctx := context.Background()
+	client, server := newTestUpstashClient(t)
+	repository := NewUpstashGameRepository(client)
+	game := repositorytest.NewPlayedGame(t, "g1")
+
+	t.Run("[UpstashGameRepository: 他のクライアントが保存中ならErrConcurrentModification]", func(t *testing.T) {
+		locked, err := client.SetNX(ctx, "game:g1:lock", "other", time.Minute)
+		assert.NoError(t, err)
+		assert.True(t, locked)
+		assert.ErrorIs(t, repository.Save(ctx, game), shared.ErrConcurrentModification)
+		assert.Equal(t, 0, game.GetVersion())
+
+		value, _, err := client.Get(ctx, "game:g1:lock")
+		assert.NoError(t, err)
+		assert.Equal(t, "other", value)
+		_, err = client.Del(ctx, "game:g1:lock")
+		assert.NoError(t, err)
+	})
+
+	t.Run("[UpstashGameRepository: 保存が終わればロックを解放する]", func(t *testing.T) {
+		assert.NoError(t, repository.Save(ctx, game))
+		assert.ErrorIs(t, repository.Save(ctx, repositorytest.NewPlayedGame(t, "g1")), shared.ErrConcurrentModification)
+		assert.Equal(t, []string{"game:g1:board", "game:g1:meta"}, server.Keys())
+	})
+}
+
+func TestUpstashGameRepositoryDelete(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("[UpstashGameRepository: ターンログと存在確率マップも消す]", func(t *testing.T) {
+		store, _, server := newTestUpstashUnitOfWorkStore(t)
+		transaction := store.UnitOfWork.Begin()
+		assert.NoError(t, transaction.SaveGame(repositorytest.NewPlayedGame(t, "g1")))
+		assert.NoError(t, transaction.AppendTurnLog("g1", repositorytest.NewTurnLog(t, "g1", 1, "p1", shared.Attack)))
+		assert.NoError(t, transaction.SavePrediction("g1", "p1", domain.NewPredictionBoard()))
+		assert.NoError(t, transaction.SavePrediction("g1", "p2", domain.NewPredictionBoard()))
+		assert.NoError(t, transaction.Commit(ctx))
+		assert.NoError(t, store.Games.Save(ctx, repositorytest.NewPlayedGame(t, "g2")))
+
+		assert.NoError(t, store.Games.Delete(ctx, "g1"))
+		assert.Equal(t, []string{"game:g2:board", "game:g2:meta"}, server.Keys())
+	})
+
+	t.Run("[UpstashGameRepository: 他のクライアントが保存中なら消さない]", func(t *testing.T) {
+		client, server := newTestUpstashClient(t)
+		repository := NewUpstashGameRepository(client)
+		assert.NoError(t, repository.Save(ctx, repositorytest.NewPlayedGame(t, "g1")))
+		locked, err := client.SetNX(ctx, "game:g1:lock", "other", time.Minute)
+		assert.NoError(t, err)
+		assert.True(t, locked)
+
+		assert.ErrorIs(t, repository.Delete(ctx, "g1"), shared.ErrConcurrentModification)
+		assert.Equal(t, []string{"game:g1:board", "game:g1:lock", "game:g1:meta"}, server.Keys())
+	})
+
+	t.Run("[UpstashGameRepository: 削除前に読み込んだ対戦は保存できない]", func(t *testing.T) {
+		client, server := newTestUpstashClient(t)
+		repository := NewUpstashGameRepository(client)
+		assert.NoError(t, repository.Save(ctx, repositorytest.NewPlayedGame(t, "g1")))
+		loaded, err := repository.FindByID(ctx, "g1")
+		assert.NoError(t, err)
+
+		assert.NoError(t, repository.Delete(ctx, "g1"))
+		assert.ErrorIs(t, repository.Save(ctx, loaded), shared.ErrConcurrentModification)
+		assert.Empty(t, server.Keys())
+	})
+}
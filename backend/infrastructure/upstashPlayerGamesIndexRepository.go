@@ -0,0 +1,53 @@
+package infrastructure
+
+import (
+	"backend/domain/shared"
+	"context"
+)
+
+// UpstashPlayerGamesIndexRepository はプレイヤーごとの参加中の対戦を player:{playerId}:games (Set) に保存する.
+type UpstashPlayerGamesIndexRepository struct {
+	client *UpstashClient
+}
+
+func NewUpstashPlayerGamesIndexRepository(client *UpstashClient) *UpstashPlayerGamesIndexRepository {
+	return &UpstashPlayerGamesIndexRepository{client: client}
+}
+
+func (repository *UpstashPlayerGamesIndexRepository) AddGame(ctx context.Context, playerId shared.PlayerId, gameId shared.GameId) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := validatePlayerGame(playerId, gameId); err != nil {
+		return err
+	}
+	_, err := repository.client.SAdd(ctx, upstashPlayerGamesKey(playerId), gameId.String())
+	return err
+}
+
+func (repository *UpstashPlayerGamesIndexRepository) RemoveGame(ctx context.Context, playerId shared.PlayerId, gameId shared.GameId) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := validatePlayerGame(playerId, gameId); err != nil {
+		return err
+	}
+	_, err := repository.client.SRem(ctx, upstashPlayerGamesKey(playerId), gameId.String())
+	return err
+}
+
+func (repository *UpstashPlayerGamesIndexRepository) ListGames(ctx context.Context, playerId shared.PlayerId) ([]shared.GameId, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	members, err := repository.client.SMembers(ctx, upstashPlayerGamesKey(playerId))
+	if err != nil {
+		return nil, err
+	}
+	gameIds := make([]shared.GameId, 0, len(members))
+	for _, member := range members {
+		gameIds = append(gameIds, shared.GameId(member))
+	}
+	sortGameIds(gameIds)
+	return gameIds, nil
+}
@@ -0,0 +1,31 @@
+package infrastructure
+
+import (
+	"context"
+	"testing"
+
+	"backend/domain/interfaces"
+	"backend/infrastructure/repositorytest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpstashPlayerGamesIndexRepository(t *testing.T) {
+	repositorytest.RunPlayerGamesIndexRepositoryContract(t, func(t *testing.T) interfaces.PlayerGamesIndexRepository {
+		client, _ := newTestUpstashClient(t)
+		return NewUpstashPlayerGamesIndexRepository(client)
+	})
+}
+
+func TestUpstashPlayerGamesIndexRepositoryKeys(t *testing.T) {
+	ctx := context.Background()
+	client, server := newTestUpstashClient(t)
+	repository := NewUpstashPlayerGamesIndexRepository(client)
+	assert.NoError(t, repository.AddGame(ctx, "p1", "g1"))
+
+	assert.Equal(t, []string{"player:p1:games"}, server.Keys())
+	assert.Equal(t, "set", server.Type("player:p1:games"))
+	members, err := client.SMembers(ctx, "player:p1:games")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"g1"}, members)
+}
@@ -0,0 +1,142 @@
+package infrastructure
+
+import (
+	"backend/domain"
+	"backend/domain/shared"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+)
+
+// UpstashPredictionRepository は存在確率マップを game:{gameId}:prediction:{playerId} (String JSON) に保存する.
+type UpstashPredictionRepository struct {
+	client *UpstashClient
+}
+
+func NewUpstashPredictionRepository(client *UpstashClient) *UpstashPredictionRepository {
+	return &UpstashPredictionRepository{client: client}
+}
+
+// upstashPrediction は game:{gameId}:prediction:{playerId} の JSON.
+// score_grid (百分率の整数) と enemy_possibility は保存した時点の確率で, 読む側の便宜のために書き出す.
+// 割引はターンが進むたびに計算し直すため, 復元には加えた情報 evidences と current_turn, discount_rate を使う.
+type upstashPrediction struct {
+	PlayerId         string                      `json:"player_id"`
+	ScoreGrid        [][]int                     `json:"score_grid"`
+	EnemyPossibility [][]float32                 `json:"enemy_possibility"`
+	CurrentTurn      int                         `json:"current_turn"`
+	DiscountRate     float64                     `json:"discount_rate"`
+	Evidences        []upstashPredictionEvidence `json:"evidences"`
+	UpdatedAt        string                      `json:"updated_at"`
+}
+
+type upstashPredictionEvidence struct {
+	Kind      string           `json:"kind"`
+	Position  *upstashPosition `json:"position,omitempty"`
+	Direction string           `json:"direction,omitempty"`
+	Distance  int              `json:"distance,omitempty"`
+	Turn      int              `json:"turn"`
+}
+
+func (repository *UpstashPredictionRepository) Save(ctx context.Context, gameId shared.GameId, playerId shared.PlayerId, board *domain.PredictionBoard) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := validatePredictionKey(gameId, playerId); err != nil {
+		return err
+	}
+	if board == nil {
+		return shared.ErrPredictionBoardIsNil
+	}
+	encoded, err := encodeUpstashPrediction(playerId, board, time.Now())
+	if err != nil {
+		return err
+	}
+	return repository.client.Set(ctx, upstashPredictionKey(gameId, playerId), encoded)
+}
+
+func (repository *UpstashPredictionRepository) Find(ctx context.Context, gameId shared.GameId, playerId shared.PlayerId) (*domain.PredictionBoard, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	value, found, err := repository.client.Get(ctx, upstashPredictionKey(gameId, playerId))
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, shared.ErrPredictionBoardNotFound
+	}
+	return decodeUpstashPrediction(value)
+}
+
+func encodeUpstashPrediction(playerId shared.PlayerId, board *domain.PredictionBoard, updatedAt time.Time) (string, error) {
+	grid := board.Grid()
+	prediction := upstashPrediction{
+		PlayerId:         playerId.String(),
+		ScoreGrid:        make([][]int, len(grid)),
+		EnemyPossibility: make([][]float32, len(grid)),
+		CurrentTurn:      board.GetCurrentTurn(),
+		DiscountRate:     board.GetDiscountRate(),
+		Evidences:        make([]upstashPredictionEvidence, 0),
+		UpdatedAt:        encodeUpstashTime(updatedAt),
+	}
+	for y, row := range grid {
+		prediction.ScoreGrid[y] = make([]int, len(row))
+		prediction.EnemyPossibility[y] = make([]float32, len(row))
+		for x, possibility := range row {
+			prediction.ScoreGrid[y][x] = int(math.Round(possibility * 100))
+			prediction.EnemyPossibility[y][x] = float32(possibility)
+		}
+	}
+	for _, evidence := range board.Evidences() {
+		position, err := encodeUpstashPosition(evidence.Position)
+		if err != nil {
+			return "", err
+		}
+		encoded := upstashPredictionEvidence{
+			Kind:     evidence.Kind,
+			Position: position,
+			Distance: evidence.Distance,
+			Turn:     evidence.Turn,
+		}
+		if evidence.Direction != shared.DirectionUnknown {
+			encoded.Direction = evidence.Direction.String()
+		}
+		prediction.Evidences = append(prediction.Evidences, encoded)
+	}
+	encoded, err := json.Marshal(prediction)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+func decodeUpstashPrediction(value string) (*domain.PredictionBoard, error) {
+	var prediction upstashPrediction
+	if err := json.Unmarshal([]byte(value), &prediction); err != nil {
+		return nil, fmt.Errorf("%w: prediction: %v", shared.ErrUnexpectedUpstashReply, err)
+	}
+	evidences := make([]domain.PredictionEvidence, 0, len(prediction.Evidences))
+	for _, encoded := range prediction.Evidences {
+		position, err := decodeUpstashPosition(encoded.Position)
+		if err != nil {
+			return nil, err
+		}
+		direction := shared.Direction(shared.DirectionUnknown)
+		if encoded.Direction != "" {
+			if direction, err = decodeUpstashEnum[shared.Direction](encoded.Direction, shared.DirectionUnknown); err != nil {
+				return nil, err
+			}
+		}
+		evidences = append(evidences, domain.PredictionEvidence{
+			Kind:      encoded.Kind,
+			Position:  position,
+			Direction: direction,
+			Distance:  encoded.Distance,
+			Turn:      encoded.Turn,
+		})
+	}
+	return domain.RestorePredictionBoard(prediction.DiscountRate, prediction.CurrentTurn, evidences)
+}
@@ -0,0 +1,54 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"backend/domain"
+	"backend/domain/interfaces"
+	"backend/domain/shared"
+	"backend/infrastructure/repositorytest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpstashPredictionRepository(t *testing.T) {
+	repositorytest.RunPredictionRepositoryContract(t, func(t *testing.T) interfaces.PredictionRepository {
+		client, _ := newTestUpstashClient(t)
+		return NewUpstashPredictionRepository(client)
+	})
+}
+
+func TestUpstashPredictionRepositoryKeys(t *testing.T) {
+	ctx := context.Background()
+	client, server := newTestUpstashClient(t)
+	repository := NewUpstashPredictionRepository(client)
+	board := domain.NewPredictionBoard()
+	target, err := domain.NewPosition(2, 3)
+	assert.NoError(t, err)
+	assert.NoError(t, board.AdvanceTurn(1))
+	assert.NoError(t, board.MarkHit(target))
+	assert.NoError(t, board.AdvanceTurn(2))
+	assert.NoError(t, board.MarkEnemyMove(shared.North, 2))
+	assert.NoError(t, repository.Save(ctx, "g1", "p1", board))
+
+	assert.Equal(t, []string{"game:g1:prediction:p1"}, server.Keys())
+	assert.Equal(t, "string", server.Type("game:g1:prediction:p1"))
+	value, _, err := client.Get(ctx, "game:g1:prediction:p1")
+	assert.NoError(t, err)
+	var prediction upstashPrediction
+	assert.NoError(t, json.Unmarshal([]byte(value), &prediction))
+	assert.Equal(t, "p1", prediction.PlayerId)
+	assert.Equal(t, 2, prediction.CurrentTurn)
+	assert.Len(t, prediction.ScoreGrid, 5)
+	assert.Len(t, prediction.EnemyPossibility, 5)
+	possibility, err := board.Possibility(target)
+	assert.NoError(t, err)
+	assert.InDelta(t, possibility, float64(prediction.EnemyPossibility[2][1]), 1e-6)
+	assert.Equal(t, []upstashPredictionEvidence{
+		{Kind: "hit", Position: &upstashPosition{X: 2, Y: 3}, Turn: 1},
+		{Kind: "enemyMove", Direction: "north", Distance: 2, Turn: 2},
+	}, prediction.Evidences)
+	assert.NotEmpty(t, prediction.UpdatedAt)
+}
@@ -0,0 +1,158 @@
+package infrastructure
+
+import (
+	"backend/domain"
+	"backend/domain/shared"
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// UpstashTurnLogRepository は TurnLog を game:{gameId}:logs (List JSON) の末尾に追加していく.
+type UpstashTurnLogRepository struct {
+	client *UpstashClient
+}
+
+func NewUpstashTurnLogRepository(client *UpstashClient) *UpstashTurnLogRepository {
+	return &UpstashTurnLogRepository{client: client}
+}
+
+// upstashTurnLog は game:{gameId}:logs の各要素の JSON.
+type upstashTurnLog struct {
+	GameId       string           `json:"game_id"`
+	Turn         int              `json:"turn"`
+	PlayerId     string           `json:"player_id"`
+	SubmarineId  string           `json:"submarine_id,omitempty"`
+	ActionType   string           `json:"action_type"`
+	Target       *upstashPosition `json:"target,omitempty"`
+	Direction    string           `json:"direction"`
+	Distance     int              `json:"distance"`
+	AttackReport string           `json:"attack_report"`
+	MoveReport   string           `json:"move_report"`
+	ErrorCode    string           `json:"error_code"`
+	CreatedAt    string           `json:"created_at"`
+}
+
+func (repository *UpstashTurnLogRepository) Append(ctx context.Context, gameId shared.GameId, log *domain.TurnLog) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := validateTurnLog(gameId, log); err != nil {
+		return err
+	}
+	encoded, err := encodeUpstashTurnLog(log)
+	if err != nil {
+		return err
+	}
+	_, err = repository.client.RPush(ctx, upstashTurnLogsKey(gameId), encoded)
+	return err
+}
+
+func (repository *UpstashTurnLogRepository) FindByGameId(ctx context.Context, gameId shared.GameId) ([]*domain.TurnLog, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	values, err := repository.client.LRange(ctx, upstashTurnLogsKey(gameId), 0, -1)
+	if err != nil {
+		return nil, err
+	}
+	logs := make([]*domain.TurnLog, 0, len(values))
+	for _, value := range values {
+		log, err := decodeUpstashTurnLog(value)
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, log)
+	}
+	return logs, nil
+}
+
+// FindRange はリスト全体を読み, InMemoryTurnLogRepository と同じ規則で絞り込む.
+// 拒否された行動も同じターンで記録されるため, リストの添字からはターンの位置が分からない.
+func (repository *UpstashTurnLogRepository) FindRange(ctx context.Context, gameId shared.GameId, fromTurn int, limit int) ([]*domain.TurnLog, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if fromTurn < 0 || limit <= 0 {
+		return nil, shared.ErrInvalidPageRange
+	}
+	logs, err := repository.FindByGameId(ctx, gameId)
+	if err != nil {
+		return nil, err
+	}
+	return filterTurnLogRange(logs, fromTurn, limit), nil
+}
+
+func encodeUpstashTurnLog(log *domain.TurnLog) (string, error) {
+	target, err := encodeUpstashPosition(log.GetTarget())
+	if err != nil {
+		return "", err
+	}
+	encoded, err := json.Marshal(upstashTurnLog{
+		GameId:       log.GetGameId().String(),
+		Turn:         log.GetTurn(),
+		PlayerId:     log.GetPlayerId().String(),
+		SubmarineId:  log.GetSubmarineId().String(),
+		ActionType:   log.GetActionType().String(),
+		Target:       target,
+		Direction:    log.GetDirection().String(),
+		Distance:     log.GetDistance(),
+		AttackReport: log.GetAttackReport().String(),
+		MoveReport:   log.GetMoveReport().String(),
+		ErrorCode:    log.GetErrorCode().String(),
+		CreatedAt:    encodeUpstashTime(log.GetCreatedAt()),
+	})
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+func decodeUpstashTurnLog(value string) (*domain.TurnLog, error) {
+	var encoded upstashTurnLog
+	if err := json.Unmarshal([]byte(value), &encoded); err != nil {
+		return nil, fmt.Errorf("%w: turn log: %v", shared.ErrUnexpectedUpstashReply, err)
+	}
+	target, err := decodeUpstashPosition(encoded.Target)
+	if err != nil {
+		return nil, err
+	}
+	actionType, err := decodeUpstashEnum[shared.ActionType](encoded.ActionType, shared.ActionUnknown)
+	if err != nil {
+		return nil, err
+	}
+	direction, err := decodeUpstashEnum[shared.Direction](encoded.Direction, shared.DirectionUnknown)
+	if err != nil {
+		return nil, err
+	}
+	attackReport, err := decodeUpstashEnum[shared.AttackReportType](encoded.AttackReport, shared.AttackReportUnknown)
+	if err != nil {
+		return nil, err
+	}
+	moveReport, err := decodeUpstashEnum[shared.MoveReportType](encoded.MoveReport, shared.MoveReportUnknown)
+	if err != nil {
+		return nil, err
+	}
+	errorCode, err := decodeUpstashEnum[shared.ErrorCode](encoded.ErrorCode, shared.ErrorNone)
+	if err != nil {
+		return nil, err
+	}
+	createdAt, err := decodeUpstashTime(encoded.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewTurnLog(
+		shared.GameId(encoded.GameId),
+		encoded.Turn,
+		shared.PlayerId(encoded.PlayerId),
+		shared.SubmarineId(encoded.SubmarineId),
+		actionType,
+		target,
+		direction,
+		encoded.Distance,
+		attackReport,
+		moveReport,
+		errorCode,
+		createdAt,
+	)
+}
@@ -0,0 +1,46 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"backend/domain/interfaces"
+	"backend/domain/shared"
+	"backend/infrastructure/repositorytest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpstashTurnLogRepository(t *testing.T) {
+	repositorytest.RunTurnLogRepositoryContract(t, func(t *testing.T) interfaces.TurnLogRepository {
+		client, _ := newTestUpstashClient(t)
+		return NewUpstashTurnLogRepository(client)
+	})
+}
+
+func TestUpstashTurnLogRepositoryKeys(t *testing.T) {
+	ctx := context.Background()
+	client, server := newTestUpstashClient(t)
+	repository := NewUpstashTurnLogRepository(client)
+	assert.NoError(t, repository.Append(ctx, "g1", repositorytest.NewTurnLog(t, "g1", 1, "p1", shared.Attack)))
+	assert.NoError(t, repository.Append(ctx, "g1", repositorytest.NewTurnLog(t, "g1", 2, "p2", shared.Move)))
+
+	assert.Equal(t, []string{"game:g1:logs"}, server.Keys())
+	assert.Equal(t, "list", server.Type("game:g1:logs"))
+	values, err := client.LRange(ctx, "game:g1:logs", 0, -1)
+	assert.NoError(t, err)
+	assert.Len(t, values, 2)
+
+	var attack, move map[string]any
+	assert.NoError(t, json.Unmarshal([]byte(values[0]), &attack))
+	assert.NoError(t, json.Unmarshal([]byte(values[1]), &move))
+	assert.Equal(t, "attack", attack["action_type"])
+	assert.Equal(t, map[string]any{"x": 2.0, "y": 3.0}, attack["target"])
+	assert.Equal(t, "waveHigh", attack["attack_report"])
+	assert.Equal(t, "move", move["action_type"])
+	assert.Equal(t, "p2-sub-1", move["submarine_id"])
+	assert.Equal(t, "east", move["direction"])
+	assert.Equal(t, "invalidAction", move["error_code"])
+	assert.NotContains(t, move, "target")
+}
@@ -0,0 +1,165 @@
+package infrastructure
+
+import (
+	"backend/domain/interfaces"
+	"backend/domain/shared"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// upstashGameLockTTL は保存中のクライアントが止まった場合に game:{gameId}:lock が残る時間.
+// ロックを取得してからの書き込みはこの半分で打ち切るため, 既定のタイムアウトのリクエストが1回遅れても書き込み終えられる長さにする.
+const upstashGameLockTTL = 3 * defaultUpstashTimeout
+
+// UpstashUnitOfWork は1ターンの書き込みを1回の MULTI/EXEC にまとめてコミットする.
+// MULTI/EXEC は1回のリクエストで送るため, 送る前や送った後にクライアントが止まっても一部のキーだけが書き込まれることはない.
+//
+// REST API はリクエストごとに接続が分かれ WATCH を使えないため, 対戦を含むコミットでは game:{gameId}:lock を SET NX で取得してから
+// meta の version を確かめて書き込む. ロックを取得できない場合や version が進んでいる場合は ErrConcurrentModification を返す.
+// ロックの期限内に書き込み終えられなかった場合は context.DeadlineExceeded を返す.
+// Redis の EXEC は実行時のエラーで残りのコマンドを取り消さないため, 値は全て送る前に検証と変換を済ませておく.
+type UpstashUnitOfWork struct {
+	client  *UpstashClient
+	lockTTL time.Duration
+}
+
+func NewUpstashUnitOfWork(client *UpstashClient) *UpstashUnitOfWork {
+	return &UpstashUnitOfWork{client: client, lockTTL: upstashGameLockTTL}
+}
+
+func (unitOfWork *UpstashUnitOfWork) Begin() interfaces.TurnTransaction {
+	return &upstashTurnTransaction{unitOfWork: unitOfWork}
+}
+
+type upstashTurnTransaction struct {
+	turnChanges
+	unitOfWork *UpstashUnitOfWork
+}
+
+func (transaction *upstashTurnTransaction) Commit(ctx context.Context) error {
+	if err := transaction.close(); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	client := transaction.unitOfWork.client
+	batch := client.Transaction()
+	gameIds := make([]shared.GameId, 0, len(transaction.games))
+	for _, staged := range transaction.games {
+		snapshot := staged.saved.Snapshot()
+		snapshot.Version++
+		board, err := encodeUpstashBoard(snapshot)
+		if err != nil {
+			return err
+		}
+		batch.HSet(upstashGameMetaKey(snapshot.Id), encodeUpstashGameMeta(snapshot)).
+			Set(upstashGameBoardKey(snapshot.Id), board)
+		gameIds = append(gameIds, snapshot.Id)
+	}
+	for _, staged := range transaction.logs {
+		encoded, err := encodeUpstashTurnLog(staged.log)
+		if err != nil {
+			return err
+		}
+		batch.RPush(upstashTurnLogsKey(staged.gameId), encoded)
+	}
+	now := time.Now()
+	for _, staged := range transaction.predictions {
+		encoded, err := encodeUpstashPrediction(staged.playerId, staged.board, now)
+		if err != nil {
+			return err
+		}
+		batch.Set(upstashPredictionKey(staged.gameId, staged.playerId), encoded)
+	}
+	for _, staged := range transaction.playerGames {
+		batch.SAdd(upstashPlayerGamesKey(staged.playerId), staged.gameId.String())
+	}
+	if batch.Len() == 0 {
+		return nil
+	}
+
+	err := withUpstashGameLocks(ctx, client, gameIds, transaction.unitOfWork.lockTTL, func(ctx context.Context) error {
+		for _, staged := range transaction.games {
+			if err := checkUpstashGameVersion(ctx, client, staged.saved.GetId(), staged.saved.GetVersion()); err != nil {
+				return err
+			}
+		}
+		_, err := batch.Exec(ctx)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	for _, staged := range transaction.games {
+		if err := staged.saved.SetVersion(staged.saved.GetVersion() + 1); err != nil {
+			return err
+		}
+	}
+	return transaction.advanceVersions()
+}
+
+// withUpstashGameLocks は gameIds の対戦のロックを取得してから write を呼び, 終わったら解放する.
+// 複数の対戦を含む場合に互いに待たないよう, ロックは id の順に取得する.
+// ロックの期限が切れた後に書き込まないよう, ロックの取得から write の終わりまでを ttl の半分で打ち切る.
+// 残りの半分は, 打ち切る直前に送ったリクエストがサーバで実行されるまでの余裕とする.
+func withUpstashGameLocks(ctx context.Context, client *UpstashClient, gameIds []shared.GameId, ttl time.Duration, write func(ctx context.Context) error) error {
+	lockCtx, cancel := context.WithTimeout(ctx, ttl/2)
+	defer cancel()
+	sorted := append([]shared.GameId(nil), gameIds...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	for _, gameId := range sorted {
+		unlock, err := lockUpstashGame(lockCtx, client, gameId, ttl)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+	}
+	return write(lockCtx)
+}
+
+// lockUpstashGame は game:{gameId}:lock を取得し, 解放する関数を返す.
+// 他のクライアントが保存中であれば待たずに ErrConcurrentModification を返す.
+// 解放はトークンの比較と削除を1つのスクリプトで行い, 期限が切れた後に他のクライアントが取得したロックは消さない.
+// 解放に失敗しても期限が過ぎれば消えるため無視する.
+func lockUpstashGame(ctx context.Context, client *UpstashClient, gameId shared.GameId, ttl time.Duration) (func(), error) {
+	tokenBytes := make([]byte, 16)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return nil, err
+	}
+	token := hex.EncodeToString(tokenBytes)
+	key := upstashGameLockKey(gameId)
+	locked, err := client.SetNX(ctx, key, token, ttl)
+	if err != nil {
+		return nil, err
+	}
+	if !locked {
+		return nil, shared.ErrConcurrentModification
+	}
+	return func() {
+		_, _ = client.DelIfEqual(context.WithoutCancel(ctx), key, token)
+	}, nil
+}
+
+// checkUpstashGameVersion は meta の version が expected と一致するかを確かめる. 保存されていない対戦の version は0とする.
+func checkUpstashGameVersion(ctx context.Context, client *UpstashClient, gameId shared.GameId, expected int) error {
+	value, found, err := client.HGet(ctx, upstashGameMetaKey(gameId), "version")
+	if err != nil {
+		return err
+	}
+	stored := 0
+	if found {
+		if stored, err = strconv.Atoi(value); err != nil {
+			return fmt.Errorf("%w: meta field version: %v", shared.ErrUnexpectedUpstashReply, err)
+		}
+	}
+	if stored != expected {
+		return shared.ErrConcurrentModification
+	}
+	return nil
+}
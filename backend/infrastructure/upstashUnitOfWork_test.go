@@ -0,0 +1,243 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"backend/domain"
+	"backend/domain/shared"
+	"backend/infrastructure/repositorytest"
+	"backend/infrastructure/upstashtest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestUpstashUnitOfWorkStore(t *testing.T) (repositorytest.UnitOfWorkStore, *UpstashUnitOfWork, *upstashtest.Server) {
+	t.Helper()
+	client, server := newTestUpstashClient(t)
+	unitOfWork := NewUpstashUnitOfWork(client)
+	return repositorytest.UnitOfWorkStore{
+		UnitOfWork:  unitOfWork,
+		Games:       NewUpstashGameRepository(client),
+		TurnLogs:    NewUpstashTurnLogRepository(client),
+		Predictions: NewUpstashPredictionRepository(client),
+		PlayerGames: NewUpstashPlayerGamesIndexRepository(client),
+	}, unitOfWork, server
+}
+
+func TestUpstashUnitOfWork(t *testing.T) {
+	repositorytest.RunUnitOfWorkContract(t, func(t *testing.T) repositorytest.UnitOfWorkStore {
+		store, _, _ := newTestUpstashUnitOfWorkStore(t)
+		return store
+	})
+}
+
+func TestUpstashUnitOfWorkKeys(t *testing.T) {
+	ctx := context.Background()
+	store, _, server := newTestUpstashUnitOfWorkStore(t)
+	transaction := store.UnitOfWork.Begin()
+	assert.NoError(t, transaction.SaveGame(repositorytest.NewPlayedGame(t, "g1")))
+	assert.NoError(t, transaction.AppendTurnLog("g1", repositorytest.NewTurnLog(t, "g1", 1, "p1", shared.Attack)))
+	assert.NoError(t, transaction.SavePrediction("g1", "p1", domain.NewPredictionBoard()))
+	assert.NoError(t, transaction.Commit(ctx))
+
+	// ロックの取得, version の確認, MULTI/EXEC, ロックの解放の4回.
+	assert.Equal(t, 4, server.Requests())
+	assert.Equal(t, []string{"game:g1:board", "game:g1:logs", "game:g1:meta", "game:g1:prediction:p1"}, server.Keys())
+}
+
+// turnFixture は1ターン目までをコミットした保存先と, 2ターン目としてコミットする内容.
+type turnFixture struct {
+	previousGame       *domain.Game
+	previousLogs       []*domain.TurnLog
+	previousPrediction *domain.PredictionBoard
+	nextLog            *domain.TurnLog
+	nextPrediction     *domain.PredictionBoard
+}
+
+func newTurnFixture(t *testing.T, store repositorytest.UnitOfWorkStore) *turnFixture {
+	t.Helper()
+	ctx := context.Background()
+	fixture := &turnFixture{
+		previousGame:       repositorytest.NewPlayedGame(t, "g1"),
+		previousLogs:       []*domain.TurnLog{repositorytest.NewTurnLog(t, "g1", 1, "p1", shared.Attack)},
+		previousPrediction: domain.NewPredictionBoard(),
+		nextLog:            repositorytest.NewTurnLog(t, "g1", 2, "p2", shared.Attack),
+	}
+	assert.NoError(t, fixture.previousPrediction.AdvanceTurn(1))
+	fixture.nextPrediction = fixture.previousPrediction.Clone()
+	target, err := domain.NewPosition(4, 4)
+	assert.NoError(t, err)
+	assert.NoError(t, fixture.nextPrediction.AdvanceTurn(2))
+	assert.NoError(t, fixture.nextPrediction.MarkMiss(target))
+
+	transaction := store.UnitOfWork.Begin()
+	assert.NoError(t, transaction.SaveGame(fixture.previousGame))
+	assert.NoError(t, transaction.AppendTurnLog("g1", fixture.previousLogs[0]))
+	assert.NoError(t, transaction.SavePrediction("g1", "p1", fixture.previousPrediction))
+	assert.NoError(t, transaction.Commit(ctx))
+	return fixture
+}
+
+// commitNext は保存されている対戦を読み込み, 2ターン目の変更を加えてコミットする. 読み込みに失敗した場合は nil とエラーを返す.
+func (fixture *turnFixture) commitNext(t *testing.T, store repositorytest.UnitOfWorkStore) (*domain.Game, error) {
+	t.Helper()
+	ctx := context.Background()
+	game, err := store.Games.FindByID(ctx, "g1")
+	if err != nil {
+		return nil, err
+	}
+	_, err = game.GetBoard().MoveSubmarine("p1", "p1-sub-4", shared.East, 1)
+	assert.NoError(t, err)
+	transaction := store.UnitOfWork.Begin()
+	assert.NoError(t, transaction.SaveGame(game))
+	assert.NoError(t, transaction.AppendTurnLog("g1", fixture.nextLog))
+	assert.NoError(t, transaction.SavePrediction("g1", "p1", fixture.nextPrediction))
+	return game, transaction.Commit(ctx)
+}
+
+// nextGame は2ターン目をコミットした後に保存されているはずの対戦を返す.
+func (fixture *turnFixture) nextGame(t *testing.T) *domain.Game {
+	t.Helper()
+	game := fixture.previousGame.Clone()
+	_, err := game.GetBoard().MoveSubmarine("p1", "p1-sub-4", shared.East, 1)
+	assert.NoError(t, err)
+	assert.NoError(t, game.SetVersion(fixture.previousGame.GetVersion()+1))
+	return game
+}
+
+// TestUpstashUnitOfWorkRecovery はコミット中の各リクエストを失敗させ, 保存先が1ターン目と2ターン目のどちらかの状態に揃っていること,
+// 読み込み直してやり直せば2ターン目の状態になることを確かめる.
+func TestUpstashUnitOfWorkRecovery(t *testing.T) {
+	ctx := context.Background()
+	const requestsPerCommit = 4
+	for _, mode := range []struct {
+		name string
+		mode upstashtest.FailureMode
+	}{
+		{"実行前", upstashtest.FailBeforeExecute},
+		{"実行後", upstashtest.FailAfterExecute},
+	} {
+		for n := 1; n <= requestsPerCommit; n++ {
+			t.Run(fmt.Sprintf("[UpstashUnitOfWork: %d番目のリクエストが%sに失敗]", n, mode.name), func(t *testing.T) {
+				store, unitOfWork, server := newTestUpstashUnitOfWorkStore(t)
+				// 解放できなかったロックが早く消えるようにする.
+				unitOfWork.lockTTL = 100 * time.Millisecond
+				fixture := newTurnFixture(t, store)
+
+				server.FailRequest(n, mode.mode)
+				_, commitErr := fixture.commitNext(t, store)
+				assert.LessOrEqual(t, n, server.Requests())
+
+				found, err := store.Games.FindByID(ctx, "g1")
+				assert.NoError(t, err)
+				switch found.GetVersion() {
+				case fixture.previousGame.GetVersion():
+					assert.Error(t, commitErr)
+					repositorytest.AssertTurnStored(t, store, fixture.previousGame, fixture.previousLogs, fixture.previousPrediction)
+				case fixture.previousGame.GetVersion() + 1:
+					repositorytest.AssertTurnStored(t, store, fixture.nextGame(t), append(fixture.previousLogs, fixture.nextLog), fixture.nextPrediction)
+					return
+				default:
+					t.Fatalf("unexpected version %d", found.GetVersion())
+				}
+
+				// 2ターン目が書き込まれていなければ, 残ったロックが消えるまで読み込み直してやり直す.
+				var retryErr error
+				assert.Eventually(t, func() bool {
+					_, retryErr = fixture.commitNext(t, store)
+					return !errors.Is(retryErr, shared.ErrConcurrentModification)
+				}, 2*time.Second, 20*time.Millisecond)
+				assert.NoError(t, retryErr)
+				repositorytest.AssertTurnStored(t, store, fixture.nextGame(t), append(fixture.previousLogs, fixture.nextLog), fixture.nextPrediction)
+			})
+		}
+	}
+
+	t.Run("[UpstashUnitOfWork: 応答が失われたコミットは二重に書き込まれない]", func(t *testing.T) {
+		store, _, server := newTestUpstashUnitOfWorkStore(t)
+		fixture := newTurnFixture(t, store)
+		game, err := store.Games.FindByID(ctx, "g1")
+		assert.NoError(t, err)
+
+		// 3番目のリクエストは MULTI/EXEC. 書き込みは済んだが, クライアントは失敗として受け取る.
+		server.FailRequest(3, upstashtest.FailAfterExecute)
+		transaction := store.UnitOfWork.Begin()
+		assert.NoError(t, transaction.SaveGame(game))
+		assert.NoError(t, transaction.AppendTurnLog("g1", fixture.nextLog))
+		assert.ErrorIs(t, transaction.Commit(ctx), shared.ErrUpstashRequestFailed)
+		assert.Equal(t, fixture.previousGame.GetVersion(), game.GetVersion())
+
+		// 読み込み直さずに同じ内容を送り直しても, バージョンが進んでいるため書き込まれない.
+		transaction = store.UnitOfWork.Begin()
+		assert.NoError(t, transaction.SaveGame(game))
+		assert.NoError(t, transaction.AppendTurnLog("g1", fixture.nextLog))
+		assert.ErrorIs(t, transaction.Commit(ctx), shared.ErrConcurrentModification)
+		logs, err := store.TurnLogs.FindByGameId(ctx, "g1")
+		assert.NoError(t, err)
+		assert.Len(t, logs, 2)
+	})
+}
+
+func TestUpstashGameLock(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("[lockUpstashGame: 期限が切れた後に他のクライアントが取得したロックは解放しない]", func(t *testing.T) {
+		client, _ := newTestUpstashClient(t)
+		unlock, err := lockUpstashGame(ctx, client, "g1", 50*time.Millisecond)
+		assert.NoError(t, err)
+		assert.Eventually(t, func() bool {
+			locked, err := client.SetNX(ctx, "game:g1:lock", "other", time.Minute)
+			return err == nil && locked
+		}, time.Second, 10*time.Millisecond)
+
+		unlock()
+		value, found, err := client.Get(ctx, "game:g1:lock")
+		assert.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, "other", value)
+	})
+}
+
+// stallingTransport は path へのリクエストを context が終わるまで返さない. タイムアウトのないクライアントで応答が返らない場合にあたる.
+type stallingTransport struct {
+	path string
+}
+
+func (transport stallingTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	if request.URL.Path == transport.path {
+		<-request.Context().Done()
+		return nil, request.Context().Err()
+	}
+	return http.DefaultTransport.RoundTrip(request)
+}
+
+func TestUpstashUnitOfWorkLockDeadline(t *testing.T) {
+	ctx := context.Background()
+	server := upstashtest.NewServer(t)
+	client, err := NewUpstashClient(server.URL, server.Token, &http.Client{Transport: stallingTransport{path: "/multi-exec"}})
+	assert.NoError(t, err)
+	unitOfWork := NewUpstashUnitOfWork(client)
+	unitOfWork.lockTTL = 200 * time.Millisecond
+
+	t.Run("[UpstashUnitOfWork: ロックの期限の半分で書き込みを打ち切る]", func(t *testing.T) {
+		transaction := unitOfWork.Begin()
+		assert.NoError(t, transaction.SaveGame(repositorytest.NewPlayedGame(t, "g1")))
+		started := time.Now()
+		assert.ErrorIs(t, transaction.Commit(ctx), context.DeadlineExceeded)
+		assert.Less(t, time.Since(started), unitOfWork.lockTTL)
+		assert.Empty(t, server.Keys())
+	})
+
+	t.Run("[UpstashGameRepository: 削除も同じ期限で打ち切る]", func(t *testing.T) {
+		_, err := client.HSet(ctx, "game:g1:meta", map[string]string{"player_a_id": "p1", "player_b_id": "p2"})
+		assert.NoError(t, err)
+		repository := &UpstashGameRepository{client: client, unitOfWork: unitOfWork}
+		assert.ErrorIs(t, repository.Delete(ctx, "g1"), context.DeadlineExceeded)
+		assert.Equal(t, []string{"game:g1:meta"}, server.Keys())
+	})
+}
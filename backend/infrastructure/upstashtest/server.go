@@ -0,0 +1,536 @@
+// Package upstashtest は Upstash の REST API を真似たプロセス内のサーバを提供する.
+// データはメモリに保持し, UpstashClient が使うコマンドだけを実装する.
+package upstashtest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const defaultToken = "upstashtest-token"
+
+var (
+	errWrongType = errors.New("WRONGTYPE Operation against a key holding the wrong kind of value")
+	errNotInt    = errors.New("ERR value is not an integer or out of range")
+	errSyntax    = errors.New("ERR syntax error")
+)
+
+// delIfEqualScript は UpstashClient.DelIfEqual が EVAL で送る Lua スクリプト. EVAL はこのスクリプトだけを実装する.
+const delIfEqualScript = `if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("DEL", KEYS[1]) else return 0 end`
+
+// FailureMode は FailRequest で失敗させるリクエストの扱い.
+type FailureMode int
+
+const (
+	// FailBeforeExecute はコマンドを実行せずに 503 を返す. リクエストが届く前に接続が切れた場合にあたる.
+	FailBeforeExecute FailureMode = iota + 1
+	// FailAfterExecute はコマンドを実行した後に 503 を返す. 書き込みは済んだが応答が失われた場合にあたる.
+	FailAfterExecute
+)
+
+// Server は Upstash の REST API を真似た httptest サーバ.
+// 単一のコマンドは "/", パイプラインは "/pipeline", トランザクションは "/multi-exec" で受け付ける.
+// 値は文字列, ハッシュ, リスト, 集合のいずれかで, 型の合わないコマンドには WRONGTYPE を返す.
+// EVAL は UpstashClient.DelIfEqual が送るスクリプトだけを受け付ける.
+// SET の EX, PX で有効期限を付けたキーは, 期限を過ぎた後に最初に受け付けたコマンドの前に削除する.
+type Server struct {
+	URL   string
+	Token string
+
+	server      *httptest.Server
+	mu          sync.Mutex
+	data        map[string]any
+	expires     map[string]time.Time
+	requests    int
+	failAt      int
+	failureMode FailureMode
+}
+
+// NewServer はサーバを起動し, テストの終了時に停止するよう登録する.
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+	server := &Server{
+		Token:   defaultToken,
+		data:    make(map[string]any),
+		expires: make(map[string]time.Time),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /{$}", server.handleCommand)
+	mux.HandleFunc("POST /pipeline", server.handleBatch)
+	mux.HandleFunc("POST /multi-exec", server.handleBatch)
+	server.server = httptest.NewServer(mux)
+	server.URL = server.server.URL
+	t.Cleanup(server.server.Close)
+	return server
+}
+
+// Keys は保存されているキーを昇順で返す.
+func (server *Server) Keys() []string {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	server.evictExpired()
+	keys := make([]string, 0, len(server.data))
+	for key := range server.data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// FailRequest は, この後に受け付ける n 番目 (1から数える) のリクエストを mode に従って失敗させる.
+// 認証に失敗したリクエストは数えない. n が0以下なら失敗させる予定を取り消す.
+func (server *Server) FailRequest(n int, mode FailureMode) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	server.failAt = 0
+	if n > 0 {
+		server.failAt = server.requests + n
+		server.failureMode = mode
+	}
+}
+
+// Requests はこれまでに受け付けたリクエストの数を返す.
+func (server *Server) Requests() int {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	return server.requests
+}
+
+// Type は key に保存されている値の Redis 上の型を返す. 保存されていなければ "none" を返す.
+func (server *Server) Type(key string) string {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	server.evictExpired()
+	switch server.data[key].(type) {
+	case string:
+		return "string"
+	case map[string]string:
+		return "hash"
+	case []string:
+		return "list"
+	case map[string]struct{}:
+		return "set"
+	default:
+		return "none"
+	}
+}
+
+type reply struct {
+	Result any    `json:"result"`
+	Error  string `json:"error,omitempty"`
+}
+
+func (server *Server) handleCommand(w http.ResponseWriter, r *http.Request) {
+	if !server.authorize(w, r) {
+		return
+	}
+	var command []string
+	if err := json.NewDecoder(r.Body).Decode(&command); err != nil || len(command) == 0 {
+		writeJSON(w, http.StatusBadRequest, reply{Error: "ERR failed to parse command"})
+		return
+	}
+	server.mu.Lock()
+	failure := server.countRequest()
+	if failure == FailBeforeExecute {
+		server.mu.Unlock()
+		writeJSON(w, http.StatusServiceUnavailable, reply{Error: "injected failure"})
+		return
+	}
+	result, err := server.execute(command)
+	server.mu.Unlock()
+	if failure == FailAfterExecute {
+		writeJSON(w, http.StatusServiceUnavailable, reply{Error: "injected failure"})
+		return
+	}
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, reply{Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, reply{Result: result})
+}
+
+// handleBatch はパイプラインとトランザクションを受け付ける.
+// どちらもロックを取ったまま全コマンドを実行するため, 途中で他のリクエストが割り込むことはない.
+// Redis と同様に, 途中のコマンドが失敗しても残りのコマンドは実行する.
+func (server *Server) handleBatch(w http.ResponseWriter, r *http.Request) {
+	if !server.authorize(w, r) {
+		return
+	}
+	var commands [][]string
+	if err := json.NewDecoder(r.Body).Decode(&commands); err != nil || len(commands) == 0 {
+		writeJSON(w, http.StatusBadRequest, reply{Error: "ERR failed to parse commands"})
+		return
+	}
+	for _, command := range commands {
+		if len(command) == 0 {
+			writeJSON(w, http.StatusBadRequest, reply{Error: "ERR empty command"})
+			return
+		}
+	}
+	server.mu.Lock()
+	failure := server.countRequest()
+	if failure == FailBeforeExecute {
+		server.mu.Unlock()
+		writeJSON(w, http.StatusServiceUnavailable, reply{Error: "injected failure"})
+		return
+	}
+	replies := make([]reply, 0, len(commands))
+	for _, command := range commands {
+		result, err := server.execute(command)
+		if err != nil {
+			replies = append(replies, reply{Error: err.Error()})
+			continue
+		}
+		replies = append(replies, reply{Result: result})
+	}
+	server.mu.Unlock()
+	if failure == FailAfterExecute {
+		writeJSON(w, http.StatusServiceUnavailable, reply{Error: "injected failure"})
+		return
+	}
+	writeJSON(w, http.StatusOK, replies)
+}
+
+// countRequest は受け付けたリクエストを数え, FailRequest で失敗させる予定のリクエストであれば失敗のさせ方を返す.
+// 呼び出し側で mu を取得しておくこと.
+func (server *Server) countRequest() FailureMode {
+	server.requests++
+	if server.requests != server.failAt {
+		return 0
+	}
+	server.failAt = 0
+	return server.failureMode
+}
+
+func (server *Server) authorize(w http.ResponseWriter, r *http.Request) bool {
+	if r.Header.Get("Authorization") != "Bearer "+server.Token {
+		writeJSON(w, http.StatusUnauthorized, reply{Error: "Unauthorized"})
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// execute は1つのコマンドを実行する. 呼び出し側で mu を取得しておくこと.
+func (server *Server) execute(command []string) (any, error) {
+	server.evictExpired()
+	name := strings.ToUpper(command[0])
+	args := command[1:]
+	switch name {
+	case "GET":
+		if len(args) != 1 {
+			return nil, wrongArgs(name)
+		}
+		switch value := server.data[args[0]].(type) {
+		case nil:
+			return nil, nil
+		case string:
+			return value, nil
+		default:
+			return nil, errWrongType
+		}
+	case "SET":
+		if len(args) < 2 {
+			return nil, wrongArgs(name)
+		}
+		options, err := parseSetOptions(args[2:])
+		if err != nil {
+			return nil, err
+		}
+		_, exists := server.data[args[0]]
+		if (options.nx && exists) || (options.xx && !exists) {
+			return nil, nil
+		}
+		server.data[args[0]] = args[1]
+		delete(server.expires, args[0])
+		if options.ttl > 0 {
+			server.expires[args[0]] = time.Now().Add(options.ttl)
+		}
+		return "OK", nil
+	case "DEL":
+		if len(args) == 0 {
+			return nil, wrongArgs(name)
+		}
+		deleted := 0
+		for _, key := range args {
+			if _, ok := server.data[key]; ok {
+				delete(server.data, key)
+				delete(server.expires, key)
+				deleted++
+			}
+		}
+		return deleted, nil
+	case "HSET":
+		if len(args) < 3 || len(args)%2 == 0 {
+			return nil, wrongArgs(name)
+		}
+		hash, err := server.hash(args[0], true)
+		if err != nil {
+			return nil, err
+		}
+		added := 0
+		for i := 1; i < len(args); i += 2 {
+			if _, ok := hash[args[i]]; !ok {
+				added++
+			}
+			hash[args[i]] = args[i+1]
+		}
+		return added, nil
+	case "HGET":
+		if len(args) != 2 {
+			return nil, wrongArgs(name)
+		}
+		hash, err := server.hash(args[0], false)
+		if err != nil {
+			return nil, err
+		}
+		value, ok := hash[args[1]]
+		if !ok {
+			return nil, nil
+		}
+		return value, nil
+	case "HGETALL":
+		if len(args) != 1 {
+			return nil, wrongArgs(name)
+		}
+		hash, err := server.hash(args[0], false)
+		if err != nil {
+			return nil, err
+		}
+		fields := make([]string, 0, len(hash))
+		for field := range hash {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		result := make([]string, 0, 2*len(fields))
+		for _, field := range fields {
+			result = append(result, field, hash[field])
+		}
+		return result, nil
+	case "RPUSH":
+		if len(args) < 2 {
+			return nil, wrongArgs(name)
+		}
+		list, err := server.list(args[0])
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, args[1:]...)
+		server.data[args[0]] = list
+		return len(list), nil
+	case "LRANGE":
+		if len(args) != 3 {
+			return nil, wrongArgs(name)
+		}
+		list, err := server.list(args[0])
+		if err != nil {
+			return nil, err
+		}
+		start, err := strconv.Atoi(args[1])
+		if err != nil {
+			return nil, errNotInt
+		}
+		stop, err := strconv.Atoi(args[2])
+		if err != nil {
+			return nil, errNotInt
+		}
+		start, stop = normalizeRange(start, stop, len(list))
+		if start > stop {
+			return []string{}, nil
+		}
+		return append([]string{}, list[start:stop+1]...), nil
+	case "SADD", "SREM":
+		if len(args) < 2 {
+			return nil, wrongArgs(name)
+		}
+		set, err := server.set(args[0], name == "SADD")
+		if err != nil {
+			return nil, err
+		}
+		changed := 0
+		for _, member := range args[1:] {
+			_, ok := set[member]
+			switch {
+			case name == "SADD" && !ok:
+				set[member] = struct{}{}
+				changed++
+			case name == "SREM" && ok:
+				delete(set, member)
+				changed++
+			}
+		}
+		if len(set) == 0 {
+			delete(server.data, args[0])
+		}
+		return changed, nil
+	case "SMEMBERS":
+		if len(args) != 1 {
+			return nil, wrongArgs(name)
+		}
+		set, err := server.set(args[0], false)
+		if err != nil {
+			return nil, err
+		}
+		members := make([]string, 0, len(set))
+		for member := range set {
+			members = append(members, member)
+		}
+		sort.Strings(members)
+		return members, nil
+	case "EVAL":
+		if len(args) < 2 {
+			return nil, wrongArgs(name)
+		}
+		if args[0] != delIfEqualScript {
+			return nil, errors.New("ERR unsupported script")
+		}
+		numKeys, err := strconv.Atoi(args[1])
+		if err != nil {
+			return nil, errNotInt
+		}
+		if numKeys != 1 || len(args) != 4 {
+			return nil, wrongArgs(name)
+		}
+		value, err := server.execute([]string{"GET", args[2]})
+		if err != nil {
+			return nil, err
+		}
+		if value != any(args[3]) {
+			return 0, nil
+		}
+		return server.execute([]string{"DEL", args[2]})
+	default:
+		return nil, fmt.Errorf("ERR unknown command '%s'", command[0])
+	}
+}
+
+// evictExpired は有効期限を過ぎたキーを削除する. 呼び出し側で mu を取得しておくこと.
+func (server *Server) evictExpired() {
+	now := time.Now()
+	for key, expiresAt := range server.expires {
+		if !now.Before(expiresAt) {
+			delete(server.data, key)
+			delete(server.expires, key)
+		}
+	}
+}
+
+type setOptions struct {
+	nx  bool
+	xx  bool
+	ttl time.Duration
+}
+
+// parseSetOptions は SET の NX, XX, EX, PX を解釈する.
+func parseSetOptions(args []string) (setOptions, error) {
+	var options setOptions
+	for i := 0; i < len(args); i++ {
+		switch strings.ToUpper(args[i]) {
+		case "NX":
+			options.nx = true
+		case "XX":
+			options.xx = true
+		case "EX", "PX":
+			if options.ttl != 0 || i+1 >= len(args) {
+				return setOptions{}, errSyntax
+			}
+			value, err := strconv.Atoi(args[i+1])
+			if err != nil {
+				return setOptions{}, errNotInt
+			}
+			if value <= 0 {
+				return setOptions{}, errors.New("ERR invalid expire time in 'set' command")
+			}
+			unit := time.Second
+			if strings.ToUpper(args[i]) == "PX" {
+				unit = time.Millisecond
+			}
+			options.ttl = time.Duration(value) * unit
+			i++
+		default:
+			return setOptions{}, errSyntax
+		}
+	}
+	if options.nx && options.xx {
+		return setOptions{}, errSyntax
+	}
+	return options, nil
+}
+
+// hash は key のハッシュを返す. create が true なら, 存在しない場合に作成して保存する.
+func (server *Server) hash(key string, create bool) (map[string]string, error) {
+	switch value := server.data[key].(type) {
+	case nil:
+		hash := make(map[string]string)
+		if create {
+			server.data[key] = hash
+		}
+		return hash, nil
+	case map[string]string:
+		return value, nil
+	default:
+		return nil, errWrongType
+	}
+}
+
+func (server *Server) list(key string) ([]string, error) {
+	switch value := server.data[key].(type) {
+	case nil:
+		return nil, nil
+	case []string:
+		return value, nil
+	default:
+		return nil, errWrongType
+	}
+}
+
+// set は key の集合を返す. create が true なら, 存在しない場合に作成して保存する.
+func (server *Server) set(key string, create bool) (map[string]struct{}, error) {
+	switch value := server.data[key].(type) {
+	case nil:
+		set := make(map[string]struct{})
+		if create {
+			server.data[key] = set
+		}
+		return set, nil
+	case map[string]struct{}:
+		return value, nil
+	default:
+		return nil, errWrongType
+	}
+}
+
+// normalizeRange は LRANGE の負の添字を解決し, リストの範囲に収めた閉区間を返す.
+func normalizeRange(start int, stop int, length int) (int, int) {
+	if start < 0 {
+		start += length
+	}
+	if stop < 0 {
+		stop += length
+	}
+	if start < 0 {
+		start = 0
+	}
+	if stop >= length {
+		stop = length - 1
+	}
+	return start, stop
+}
+
+func wrongArgs(name string) error {
+	return fmt.Errorf("ERR wrong number of arguments for '%s' command", strings.ToLower(name))
+}